@@ -0,0 +1,210 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/qonico/cosmos-iot/x/datanode"
+	"github.com/qonico/cosmos-iot/x/datanode/ante"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+func TestDelegatedDeductFeeRules(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr01"))
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	payer := sdk.AccAddress([]byte("test-fee-payer-addr01"))
+	newOwner := sdk.AccAddress([]byte("test-owner-address02"))
+
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 10))
+	fund := func(addr sdk.AccAddress, amount int64) {
+		acc := app.accountKeeper.NewAccountWithAddress(ctx, addr)
+		require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", amount))))
+		app.accountKeeper.SetAccount(ctx, acc)
+	}
+	balance := func(addr sdk.AccAddress) int64 {
+		return app.accountKeeper.GetAccount(ctx, addr).GetCoins().AmountOf("stake").Int64()
+	}
+	decorator := ante.NewDelegatedDeductFeeDecorator(app.accountKeeper, app.supplyKeeper, app.dataNodeKeeper)
+	deduct := func(msgs ...sdk.Msg) error {
+		tx := auth.NewStdTx(msgs, auth.NewStdFee(200000, fee), nil, "")
+		_, err := decorator.AnteHandle(ctx, tx, false, func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			return ctx, nil
+		})
+		return err
+	}
+
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, dataNode, owner)
+	require.NoError(t, app.dataNodeKeeper.SetDataNodeFeePayer(ctx, dataNode, payer))
+	app.dataNodeKeeper.SetFeeAllowance(ctx, types.NewFeeAllowance(dataNode, sdk.NewCoins(sdk.NewInt64Coin("stake", 20)), nil, 0, nil))
+	for _, addr := range []sdk.AccAddress{dataNode, owner, payer, newOwner} {
+		fund(addr, 15)
+	}
+	addRecords := types.NewMsgAddRecords(dataNode, nil, false)
+
+	// the records written by the device are paid by the fee payer, then the owner, then the device
+	require.NoError(t, deduct(addRecords))
+	require.Equal(t, int64(5), balance(payer))
+	require.NoError(t, deduct(addRecords))
+	require.Equal(t, int64(5), balance(owner))
+	// the device paying for itself is not capped by the fee allowance, already fully spent
+	require.NoError(t, deduct(addRecords))
+	require.Equal(t, int64(5), balance(dataNode))
+	require.True(t, sdkerrors.ErrInsufficientFunds.Is(deduct(addRecords)))
+
+	// the owner msgs are paid by their first signer, the datanode co-signing doesn't charge its owner
+	fund(owner, 15)
+	require.NoError(t, deduct(types.NewMsgSetOwner(dataNode, dataNode, newOwner, "", "")))
+	require.Equal(t, int64(5), balance(newOwner))
+	require.Equal(t, int64(15), balance(owner))
+	require.NoError(t, deduct(types.NewMsgSetOwner(dataNode, owner, newOwner, "", "")))
+	require.Equal(t, int64(5), balance(owner))
+
+	// a tx mixing records and other msgs is not a device tx
+	fund(dataNode, 15)
+	require.NoError(t, deduct(addRecords, types.NewMsgSetBackfill(owner, dataNode, true)))
+	require.Equal(t, int64(5), balance(dataNode))
+	require.Equal(t, int64(5), balance(owner))
+
+	// the gateway fees are split by the records of the channels the gateway can write
+	gateway := sdk.AccAddress([]byte("test-gateway-addr001"))
+	writable := sdk.AccAddress([]byte("test-datanode-addr02"))
+	other := sdk.AccAddress([]byte("test-datanode-addr03"))
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, writable, payer)
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, other, newOwner)
+	app.dataNodeKeeper.SetRoleGrant(ctx, types.RoleGrant{DataNode: writable, Address: gateway, Role: types.RoleWriter, Channels: []string{"1"}})
+	app.dataNodeKeeper.SetRoleGrant(ctx, types.RoleGrant{DataNode: other, Address: gateway, Role: types.RoleWriter, Channels: []string{"1"}})
+	fund(payer, 15)
+	fund(newOwner, 15)
+	require.NoError(t, deduct(types.NewMsgGatewayAddRecords(gateway, []types.DataNodeRecords{
+		{DataNode: writable, Records: []types.NewRecord{{NodeChannelID: "1"}, {NodeChannelID: "2"}}},
+		{DataNode: other, Records: []types.NewRecord{{NodeChannelID: "2"}, {NodeChannelID: "2"}}},
+	}, false)))
+	require.Equal(t, int64(5), balance(payer))
+	require.Equal(t, int64(15), balance(newOwner))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(deduct(types.NewMsgGatewayAddRecords(gateway, []types.DataNodeRecords{
+		{DataNode: other, Records: []types.NewRecord{{NodeChannelID: "2"}}},
+	}, false))))
+}
+
+func TestBandwidthQuota(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr01"))
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	acc := app.accountKeeper.NewAccountWithAddress(ctx, owner)
+	require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 5000))))
+	app.accountKeeper.SetAccount(ctx, acc)
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, dataNode, owner)
+
+	// every 1000stake bonded grant a record on each period
+	handler := datanode.NewHandler(app.dataNodeKeeper)
+	_, err = handler(ctx, types.NewMsgBondBandwidth(owner, sdk.NewCoins(sdk.NewInt64Coin("stake", 3000))))
+	require.NoError(t, err)
+	require.Equal(t, int64(2000), app.accountKeeper.GetAccount(ctx, owner).GetCoins().AmountOf("stake").Int64())
+	bond, quota := app.dataNodeKeeper.BandwidthQuota(ctx, owner)
+	require.Equal(t, int64(3), quota)
+	require.Equal(t, int64(0), bond.Used)
+
+	// device txs without fees skip the minimum gas prices within the quota
+	checkCtx := ctx.WithIsCheckTx(true).WithMinGasPrices(sdk.NewDecCoins(sdk.NewInt64DecCoin("stake", 1)))
+	decorator := ante.NewBandwidthDecorator(app.dataNodeKeeper)
+	gasPerRecord := app.dataNodeKeeper.BandwidthGasPerRecord(ctx)
+	checkGas := func(records int, gas uint64) error {
+		msg := types.NewMsgAddRecords(dataNode, make([]types.NewRecord, records), false)
+		tx := auth.NewStdTx([]sdk.Msg{msg}, auth.NewStdFee(gas, nil), nil, "")
+		_, err := decorator.AnteHandle(checkCtx, tx, false, func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			return ctx, nil
+		})
+		return err
+	}
+	check := func(records int) error {
+		return checkGas(records, uint64(records)*gasPerRecord)
+	}
+	// the unsigned txs are rejected before using the quota
+	anteHandler := ante.NewDelegatedDeductFeeAnteHandler(app.accountKeeper, app.supplyKeeper, app.dataNodeKeeper, auth.DefaultSigVerificationGasConsumer)
+	unsigned := auth.NewStdTx([]sdk.Msg{types.NewMsgAddRecords(dataNode, make([]types.NewRecord, 1), false)}, auth.NewStdFee(gasPerRecord, nil), nil, "")
+	_, err = anteHandler(checkCtx, unsigned, false)
+	require.True(t, sdkerrors.ErrNoSignatures.Is(err))
+	bond, _ = app.dataNodeKeeper.BandwidthQuota(ctx, owner)
+	require.Equal(t, int64(0), bond.Used)
+
+	// the txs without fees that can't use the quota fail the minimum gas prices before the signatures
+	overGas := auth.NewStdTx([]sdk.Msg{types.NewMsgAddRecords(dataNode, make([]types.NewRecord, 1), false)}, auth.NewStdFee(gasPerRecord+1, nil), nil, "")
+	_, err = anteHandler(checkCtx, overGas, false)
+	require.True(t, sdkerrors.ErrInsufficientFee.Is(err))
+	ownerTx := auth.NewStdTx([]sdk.Msg{types.NewMsgSetBackfill(owner, dataNode, true)}, auth.NewStdFee(gasPerRecord, nil), nil, "")
+	_, err = anteHandler(checkCtx, ownerTx, false)
+	require.True(t, sdkerrors.ErrInsufficientFee.Is(err))
+
+	// the txs over the gas of their records pay fees, without using the quota
+	require.True(t, sdkerrors.ErrInsufficientFee.Is(checkGas(2, 2*gasPerRecord+1)))
+	require.NoError(t, check(2))
+	require.True(t, sdkerrors.ErrInsufficientFee.Is(check(2)))
+	require.NoError(t, check(1))
+	bond, _ = app.dataNodeKeeper.BandwidthQuota(ctx, owner)
+	require.Equal(t, int64(3), bond.Used)
+
+	// the quota is renewed on the next period
+	nextPeriod := blockTime.Add(24 * time.Hour)
+	checkCtx = checkCtx.WithBlockTime(nextPeriod)
+	require.NoError(t, check(3))
+
+	// unbonded coins stop granting quota right away and are returned after the unbonding time
+	_, err = handler(ctx, types.NewMsgUnbondBandwidth(owner, sdk.NewCoins(sdk.NewInt64Coin("stake", 2000))))
+	require.NoError(t, err)
+	_, quota = app.dataNodeKeeper.BandwidthQuota(ctx, owner)
+	require.Equal(t, int64(1), quota)
+	_, err = handler(ctx, types.NewMsgUnbondBandwidth(owner, sdk.NewCoins(sdk.NewInt64Coin("stake", 2000))))
+	require.True(t, sdkerrors.ErrInsufficientFunds.Is(err))
+
+	// using the quota doesn't touch the unbonding queue
+	queued := func() int {
+		iterator := sdk.KVStorePrefixIterator(ctx.KVStore(app.keys[datanode.StoreKey]), types.BandwidthUnbondingPrefix)
+		defer iterator.Close()
+		count := 0
+		for ; iterator.Valid(); iterator.Next() {
+			count++
+		}
+		return count
+	}
+	require.Equal(t, 1, queued())
+	require.NoError(t, app.dataNodeKeeper.ConsumeBandwidth(ctx.WithBlockTime(nextPeriod.Add(24*time.Hour)), owner, 1))
+	require.Equal(t, 1, queued())
+
+	datanode.EndBlocker(ctx.WithBlockTime(blockTime.Add(20*24*time.Hour)), app.dataNodeKeeper)
+	require.Equal(t, int64(2000), app.accountKeeper.GetAccount(ctx, owner).GetCoins().AmountOf("stake").Int64())
+	datanode.EndBlocker(ctx.WithBlockTime(blockTime.Add(21*24*time.Hour)), app.dataNodeKeeper)
+	require.Equal(t, int64(4000), app.accountKeeper.GetAccount(ctx, owner).GetCoins().AmountOf("stake").Int64())
+	bond, quota = app.dataNodeKeeper.BandwidthQuota(ctx, owner)
+	require.Empty(t, bond.Unbonding)
+	require.Equal(t, int64(1), quota)
+	require.Equal(t, 0, queued())
+}
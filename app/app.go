@@ -67,12 +67,14 @@ var (
 
 	// module account permissions
 	maccPerms = map[string][]string{
-		auth.FeeCollectorName:     nil,
-		distr.ModuleName:          nil,
-		mint.ModuleName:           {supply.Minter},
-		staking.BondedPoolName:    {supply.Burner, supply.Staking},
-		staking.NotBondedPoolName: {supply.Burner, supply.Staking},
-		gov.ModuleName:            {supply.Burner},
+		auth.FeeCollectorName:           nil,
+		distr.ModuleName:                nil,
+		mint.ModuleName:                 {supply.Minter},
+		staking.BondedPoolName:          {supply.Burner, supply.Staking},
+		staking.NotBondedPoolName:       {supply.Burner, supply.Staking},
+		gov.ModuleName:                  {supply.Burner},
+		datanode.ModuleName:             nil,
+		datanode.StorageDepositPoolName: nil,
 	}
 )
 
@@ -172,6 +174,7 @@ func NewQonicoIoTApp(
 	app.subspaces[gov.ModuleName] = app.paramsKeeper.Subspace(gov.DefaultParamspace).WithKeyTable(gov.ParamKeyTable())
 	app.subspaces[crisis.ModuleName] = app.paramsKeeper.Subspace(crisis.DefaultParamspace)
 	app.subspaces[evidence.ModuleName] = app.paramsKeeper.Subspace(evidence.DefaultParamspace)
+	app.subspaces[datanode.ModuleName] = app.paramsKeeper.Subspace(datanode.DefaultParamspace)
 
 	// The AccountKeeper handles address -> account lookups
 	app.accountKeeper = auth.NewAccountKeeper(
@@ -271,8 +274,56 @@ func NewQonicoIoTApp(
 	app.dataNodeKeeper = datanode.NewKeeper(
 		app.cdc,
 		keys[datanode.StoreKey],
+		app.subspaces[datanode.ModuleName],
+		app.supplyKeeper,
+		app.bankKeeper,
 	)
 
+	// register the datanode store migrations, they run once when the upgrade plan is reached
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeKeyPrefixes, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateKeyPrefixes(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeRecordsLayout, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateRecordsLayout(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeTimeFrameIndex, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateTimeFrameIndex(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeOwnerIndex, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateOwnerIndex(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeOwnershipOffers, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeRecordsV2, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateRecordsV2(ctx)
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeAcceptanceWindow, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeBandwidthQuota, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeStorageDeposits, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateStorageBytes(ctx)
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+	// the max retention is read to queue the time frames, set it first
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeRetention, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateParams(ctx)
+		app.dataNodeKeeper.MigrateRetentionQueue(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeFleetKeys, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateFleetKeys(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeFrameSize, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateFrameSize(ctx)
+	})
+	app.upgradeKeeper.SetUpgradeHandler(datanode.UpgradeBandwidthGas, func(ctx sdk.Context, plan upgrade.Plan) {
+		app.dataNodeKeeper.MigrateParams(ctx)
+	})
+
 	// NOTE: Any module instantiated in the module manager that is later modified
 	// must be passed by reference here.
 	app.mm = module.NewManager(
@@ -295,7 +346,7 @@ func NewQonicoIoTApp(
 	// CanWithdrawInvariant invariant.
 
 	app.mm.SetOrderBeginBlockers(upgrade.ModuleName, mint.ModuleName, distr.ModuleName, slashing.ModuleName)
-	app.mm.SetOrderEndBlockers(crisis.ModuleName, gov.ModuleName, staking.ModuleName)
+	app.mm.SetOrderEndBlockers(crisis.ModuleName, gov.ModuleName, staking.ModuleName, datanode.ModuleName)
 
 	// Sets the order of Genesis - Order matters, genutil is to always come last
 	// NOTE: The genutils module must occur after staking so that pools are
@@ -315,6 +366,9 @@ func NewQonicoIoTApp(
 		evidence.ModuleName,
 	)
 
+	// register the datanode invariants, the crisis module checks them
+	datanode.RegisterInvariants(&app.crisisKeeper, app.dataNodeKeeper)
+
 	// register all module routes and module queriers
 	app.mm.RegisterRoutes(app.Router(), app.QueryRouter())
 
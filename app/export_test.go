@@ -0,0 +1,129 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/qonico/cosmos-iot/x/datanode"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+func initChain(t *testing.T, app *QonicoIoTApp, appState []byte) {
+	app.InitChain(abci.RequestInitChain{
+		Validators:    []abci.ValidatorUpdate{},
+		AppStateBytes: appState,
+	})
+	app.Commit()
+}
+
+func TestDataNodeExportImportRoundTrip(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 5, 20, 23, 59, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNodes := []sdk.AccAddress{
+		sdk.AccAddress([]byte("test-datanode-addr01")),
+		sdk.AccAddress([]byte("test-datanode-addr02")),
+	}
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+
+	for _, dn := range dataNodes {
+		app.dataNodeKeeper.SetDataNodeOwner(ctx, dn, owner)
+		require.NoError(t, app.dataNodeKeeper.ChangeChannel(ctx, dn, datanode.NodeChannel{ID: "1", Variable: "temperature"}))
+		require.NoError(t, app.dataNodeKeeper.ChangeChannel(ctx, dn, datanode.NodeChannel{ID: "2", Variable: "humidity"}))
+
+		// records spanning two time frames, added out of order
+		for _, offset := range []int64{120, 0, 60, -60} {
+			for _, ch := range []string{"1", "2"} {
+				record := datanode.Record{TimeStamp: (blockTime.Unix() + offset) * 1000, Value: offset + 100, Misc: ch}
+				require.NoError(t, app.dataNodeKeeper.AddRecord(ctx, dn, ch, record))
+			}
+		}
+	}
+
+	// the rest of the datanode state, set through the handler as the txs do
+	acc := app.accountKeeper.NewAccountWithAddress(ctx, owner)
+	require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 100000))))
+	app.accountKeeper.SetAccount(ctx, acc)
+	handler := datanode.NewHandler(app.dataNodeKeeper)
+	newOwner := sdk.AccAddress([]byte("test-owner-address02"))
+	writer := sdk.AccAddress([]byte("test-gateway-addr001"))
+	nowMs := blockTime.Unix() * types.MillisPerSecond
+	for _, msg := range []sdk.Msg{
+		types.NewMsgCreateFleet(owner, "fleet-1"),
+		types.NewMsgAddFleetMember(owner, "fleet-1", dataNodes[0], owner),
+		types.NewMsgGrantRole(owner, dataNodes[0], writer, types.RoleWriter, []string{"1"}, time.Time{}),
+		types.NewMsgOfferOwnership(owner, dataNodes[1], newOwner),
+		types.NewMsgSetFeeAllowance(owner, dataNodes[0], sdk.NewCoins(sdk.NewInt64Coin("stake", 50000)), nil, 0, nil),
+		types.NewMsgBondBandwidth(owner, sdk.NewCoins(sdk.NewInt64Coin("stake", 3000))),
+		// a deposit paid for the records added
+		types.NewMsgAddRecords(dataNodes[0], []types.NewRecord{{NodeChannelID: "1", Time: nowMs - 1, IntValue: 1}}, true),
+		// the records pruned are summarized on the rollups of the channel
+		types.NewMsgUpdateChannels(owner, dataNodes[1], []types.ChannelUpdate{{Action: "set", ID: "1", Variable: "temperature", Rollup: true}}),
+		types.NewMsgPruneRecords(owner, dataNodes[1], "1", (blockTime.Unix()+60)*1000),
+		// the records of a deleted channel are still stored
+		types.NewMsgUpdateChannels(owner, dataNodes[1], []types.ChannelUpdate{{Action: "delete", ID: "2"}}),
+	} {
+		_, err := handler(ctx, msg)
+		require.NoError(t, err, msg.Type())
+	}
+
+	app.EndBlock(abci.RequestEndBlock{Height: ctx.BlockHeight()})
+	app.Commit()
+
+	exported, _, err := app.ExportAppStateAndValidators(false, []string{})
+	require.NoError(t, err)
+
+	var exportedGenesis GenesisState
+	require.NoError(t, app.Codec().UnmarshalJSON(exported, &exportedGenesis))
+	var dataNodeGenesis datanode.GenesisState
+	require.NoError(t, datanode.ModuleCdc.UnmarshalJSON(exportedGenesis[datanode.ModuleName], &dataNodeGenesis))
+	require.NoError(t, datanode.ValidateGenesis(dataNodeGenesis))
+	require.Len(t, dataNodeGenesis.DataNodes, 2)
+	require.Len(t, dataNodeGenesis.DataRecords, 7)
+	require.Len(t, dataNodeGenesis.Fleets, 1)
+	require.Len(t, dataNodeGenesis.RoleGrants, 1)
+	require.Len(t, dataNodeGenesis.OwnershipOffers, 1)
+	require.Len(t, dataNodeGenesis.FeeAllowances, 1)
+	require.Len(t, dataNodeGenesis.BandwidthBonds, 1)
+	require.Len(t, dataNodeGenesis.StorageDeposits, 2)
+	require.Len(t, dataNodeGenesis.Rollups, 1)
+	var deletedChannel int
+	for _, dataRecord := range dataNodeGenesis.DataRecords {
+		if dataRecord.DataNode.Equals(dataNodes[1]) && dataRecord.NodeChannel.ID == "2" {
+			deletedChannel += len(dataRecord.Records)
+		}
+	}
+	require.Equal(t, 4, deletedChannel)
+
+	// the deposits must belong to the datanodes exported
+	invalid := dataNodeGenesis
+	invalid.StorageDeposits = append([]types.StorageDeposit{}, dataNodeGenesis.StorageDeposits...)
+	invalid.StorageDeposits[0].DataNode = newOwner
+	require.Error(t, datanode.ValidateGenesis(invalid))
+
+	newApp := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+	initChain(t, newApp, exported)
+
+	reexported, _, err := newApp.ExportAppStateAndValidators(false, []string{})
+	require.NoError(t, err)
+
+	// other modules renumber accounts on import, the datanode state must be kept byte-for-byte
+	var reexportedGenesis GenesisState
+	require.NoError(t, newApp.Codec().UnmarshalJSON(reexported, &reexportedGenesis))
+	require.Equal(t, string(exportedGenesis[datanode.ModuleName]), string(reexportedGenesis[datanode.ModuleName]))
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/qonico/cosmos-iot/x/datanode"
+	"github.com/qonico/cosmos-iot/x/datanode/keeper"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+func TestStorageDeposits(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr01"))
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	balance := func(addr sdk.AccAddress) int64 {
+		acc := app.accountKeeper.GetAccount(ctx, addr)
+		if acc == nil {
+			return 0
+		}
+		return acc.GetCoins().AmountOf("stake").Int64()
+	}
+	pool := app.supplyKeeper.GetModuleAddress(types.StorageDepositPoolName)
+	checkInvariants := func() {
+		for _, invariant := range []sdk.Invariant{keeper.StorageDepositsPoolInvariant(app.dataNodeKeeper), keeper.StorageDepositsBytesInvariant(app.dataNodeKeeper), keeper.StorageDepositsDepositorsInvariant(app.dataNodeKeeper)} {
+			msg, broken := invariant(ctx)
+			require.False(t, broken, msg)
+		}
+	}
+
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, dataNode, owner)
+	require.NoError(t, app.dataNodeKeeper.ChangeChannel(ctx, dataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+
+	handler := datanode.NewHandler(app.dataNodeKeeper)
+	nowMs := blockTime.Unix() * types.MillisPerSecond
+	addRecords := func(ctx sdk.Context, times ...int64) error {
+		var records []types.NewRecord
+		for _, ts := range times {
+			records = append(records, types.NewRecord{NodeChannelID: "1", Time: ts, IntValue: 1})
+		}
+		_, err := handler(ctx, types.NewMsgAddRecords(dataNode, records, false))
+		return err
+	}
+
+	// nobody can pay the deposit of the records, the failed msg is discarded by the app
+	cacheCtx, _ := ctx.CacheContext()
+	require.True(t, types.ErrStorageDepositUnpaid.Is(addRecords(cacheCtx, nowMs)))
+
+	// the fee payer of the datanode pays a coin for each byte stored, duplicates are not charged
+	acc := app.accountKeeper.NewAccountWithAddress(ctx, owner)
+	require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 10000))))
+	app.accountKeeper.SetAccount(ctx, acc)
+	require.NoError(t, addRecords(ctx, nowMs-2000, nowMs-1000, nowMs, nowMs))
+	deposit := app.dataNodeKeeper.GetStorageDeposit(ctx, dataNode)
+	require.True(t, deposit.Bytes > 0)
+	require.Equal(t, deposit.Bytes, deposit.Amount.AmountOf("stake").Int64())
+	require.Equal(t, 10000-deposit.Bytes, balance(owner))
+	require.Equal(t, deposit.Bytes, balance(pool))
+	checkInvariants()
+
+	// pruning refunds the deposit of the records deleted
+	_, err = handler(ctx, types.NewMsgPruneRecords(owner, dataNode, "1", nowMs-1000))
+	require.NoError(t, err)
+	left := app.dataNodeKeeper.GetStorageDeposit(ctx, dataNode)
+	require.Equal(t, deposit.Bytes/3*2, left.Bytes)
+	require.Equal(t, 10000-left.Bytes, balance(owner))
+	records, _, err := app.dataNodeKeeper.GetRecordsRange(ctx, dataNode, "1", 0, nowMs, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	checkInvariants()
+
+	// deleting the datanode refunds the rest
+	_, err = handler(ctx, types.NewMsgDeleteDataNode(owner, dataNode, false))
+	require.NoError(t, err)
+	require.True(t, app.dataNodeKeeper.GetStorageDeposit(ctx, dataNode).IsEmpty())
+	require.Equal(t, int64(10000), balance(owner))
+	require.Equal(t, int64(0), balance(pool))
+	checkInvariants()
+}
+
+func TestStorageDepositsRefundDepositors(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr01"))
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	payer := sdk.AccAddress([]byte("test-fee-payer-addr01"))
+	newPayer := sdk.AccAddress([]byte("test-fee-payer-addr02"))
+	balance := func(addr sdk.AccAddress) int64 {
+		acc := app.accountKeeper.GetAccount(ctx, addr)
+		if acc == nil {
+			return 0
+		}
+		return acc.GetCoins().AmountOf("stake").Int64()
+	}
+	for _, addr := range []sdk.AccAddress{owner, payer} {
+		acc := app.accountKeeper.NewAccountWithAddress(ctx, addr)
+		require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", 10000))))
+		app.accountKeeper.SetAccount(ctx, acc)
+	}
+
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, dataNode, owner)
+	require.NoError(t, app.dataNodeKeeper.ChangeChannel(ctx, dataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+	handler := datanode.NewHandler(app.dataNodeKeeper)
+	nowMs := blockTime.Unix() * types.MillisPerSecond
+	addRecords := func(times ...int64) {
+		var records []types.NewRecord
+		for _, ts := range times {
+			records = append(records, types.NewRecord{NodeChannelID: "1", Time: ts, IntValue: 1})
+		}
+		_, err := handler(ctx, types.NewMsgAddRecords(dataNode, records, false))
+		require.NoError(t, err)
+	}
+
+	// the owner pays the first records and the fee payer set later the next ones
+	addRecords(nowMs-6000, nowMs-5000)
+	require.NoError(t, app.dataNodeKeeper.SetDataNodeFeePayer(ctx, dataNode, payer))
+	addRecords(nowMs-4000, nowMs-3000)
+	deposit := app.dataNodeKeeper.GetStorageDeposit(ctx, dataNode)
+	paid := deposit.Amount.AmountOf("stake").Int64() / 2
+	require.Equal(t, []types.StorageDepositor{
+		{Address: owner, Amount: sdk.NewCoins(sdk.NewInt64Coin("stake", paid))},
+		{Address: payer, Amount: sdk.NewCoins(sdk.NewInt64Coin("stake", paid))},
+	}, deposit.Depositors)
+
+	// the fee payer changes before the records are pruned, the depositors are refunded in proportion
+	require.NoError(t, app.dataNodeKeeper.SetDataNodeFeePayer(ctx, dataNode, newPayer))
+	res, err := handler(ctx, types.NewMsgPruneRecords(owner, dataNode, "1", nowMs-4000))
+	require.NoError(t, err)
+	requireEventCount(t, res.Events, types.EventTypeStorageRefunded, 2)
+	require.Equal(t, 10000-paid/2, balance(owner))
+	require.Equal(t, 10000-paid/2, balance(payer))
+	require.Equal(t, int64(0), balance(newPayer))
+
+	_, err = handler(ctx, types.NewMsgDeleteDataNode(owner, dataNode, false))
+	require.NoError(t, err)
+	require.Equal(t, int64(10000), balance(owner))
+	require.Equal(t, int64(10000), balance(payer))
+	require.Equal(t, int64(0), balance(newPayer))
+	msg, broken := keeper.StorageDepositsDepositorsInvariant(app.dataNodeKeeper)(ctx)
+	require.False(t, broken, msg)
+}
+
+func TestStorageDepositsAllowance(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr01"))
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	fund := func(addr sdk.AccAddress, amount int64) {
+		acc := app.accountKeeper.NewAccountWithAddress(ctx, addr)
+		require.NoError(t, acc.SetCoins(sdk.NewCoins(sdk.NewInt64Coin("stake", amount))))
+		app.accountKeeper.SetAccount(ctx, acc)
+	}
+	balance := func(addr sdk.AccAddress) int64 {
+		return app.accountKeeper.GetAccount(ctx, addr).GetCoins().AmountOf("stake").Int64()
+	}
+
+	app.dataNodeKeeper.SetDataNodeOwner(ctx, dataNode, owner)
+	require.NoError(t, app.dataNodeKeeper.ChangeChannel(ctx, dataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+	fund(owner, 10000)
+
+	// the owner caps what the device spends on its behalf
+	handler := datanode.NewHandler(app.dataNodeKeeper)
+	limit := sdk.NewCoins(sdk.NewInt64Coin("stake", 1))
+	_, err = handler(ctx, types.NewMsgSetFeeAllowance(owner, dataNode, nil, nil, 0, limit))
+	require.NoError(t, err)
+
+	nowMs := blockTime.Unix() * types.MillisPerSecond
+	msg := types.NewMsgAddRecords(dataNode, []types.NewRecord{{NodeChannelID: "1", Time: nowMs, IntValue: 1}}, false)
+
+	// over the cap the owner doesn't pay, and the device has no coins
+	cacheCtx, _ := ctx.CacheContext()
+	_, err = handler(cacheCtx, msg)
+	require.True(t, types.ErrStorageDepositUnpaid.Is(err))
+
+	// the device signing the records pays for itself, without cap
+	fund(dataNode, 10000)
+	_, err = handler(ctx, msg)
+	require.NoError(t, err)
+	deposit := app.dataNodeKeeper.GetStorageDeposit(ctx, dataNode)
+	require.Equal(t, 10000-deposit.Bytes, balance(dataNode))
+	require.Equal(t, int64(10000), balance(owner))
+
+	// within the cap the owner pays, spending the allowance
+	_, err = handler(ctx, types.NewMsgSetFeeAllowance(owner, dataNode, nil, nil, 0, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000))))
+	require.NoError(t, err)
+	msg.Records[0].Time = nowMs - 1000
+	_, err = handler(ctx, msg)
+	require.NoError(t, err)
+	require.Equal(t, 10000-deposit.Bytes, balance(dataNode))
+	require.Equal(t, 10000-deposit.Bytes, balance(owner))
+	allowance, err := app.dataNodeKeeper.GetFeeAllowance(ctx, dataNode)
+	require.NoError(t, err)
+	require.Equal(t, deposit.Bytes, allowance.TotalSpent.AmountOf("stake").Int64())
+}
+
+// requireEventCount - requires the events to hold count events of the type
+func requireEventCount(t *testing.T, events sdk.Events, eventType string, count int) {
+	var found int
+	for _, event := range events {
+		if event.Type == eventType {
+			found++
+		}
+	}
+	require.Equal(t, count, found, eventType)
+}
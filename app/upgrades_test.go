@@ -0,0 +1,147 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/cosmos-sdk/x/upgrade"
+
+	"github.com/qonico/cosmos-iot/x/datanode"
+	"github.com/qonico/cosmos-iot/x/datanode/keeper"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// legacy layouts of the datanode store before the key prefixes upgrade
+
+type legacyRecord struct {
+	TimeStamp uint32 `json:"t"`
+	Value     uint32 `json:"v"`
+	Misc      string `json:"m"`
+}
+
+type legacyDataRecord struct {
+	DataNode    sdk.AccAddress    `json:"datanode"`
+	NodeChannel types.NodeChannel `json:"channel"`
+	TimeFrame   int64             `json:"timeframe"`
+	Records     []legacyRecord    `json:"records"`
+}
+
+type legacyDataNode struct {
+	ID       sdk.AccAddress         `json:"id,omitempty"`
+	Owner    sdk.AccAddress         `json:"owner"`
+	Name     string                 `json:"name"`
+	Channels []types.NodeChannel    `json:"channels"`
+	Records  []types.DataRecordHash `json:"records"`
+}
+
+func TestUpgradeLegacyStore(t *testing.T) {
+	app := NewQonicoIoTApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, 0, map[int64]bool{})
+
+	appState, err := json.Marshal(NewDefaultGenesisState())
+	require.NoError(t, err)
+	initChain(t, app, appState)
+
+	blockTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime}})
+	ctx := app.NewContext(false, abci.Header{Height: app.LastBlockHeight() + 1, Time: blockTime})
+
+	// the legacy chain had no datanode parameters nor prefixed keys
+	clearStore := func(store sdk.KVStore) {
+		iterator := store.Iterator(nil, nil)
+		var keys [][]byte
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, append([]byte{}, iterator.Key()...))
+		}
+		iterator.Close()
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	}
+	paramStore := ctx.KVStore(app.keys[params.StoreKey])
+	clearStore(prefix.NewStore(paramStore, []byte(datanode.DefaultParamspace+"/")))
+	store := ctx.KVStore(app.keys[datanode.StoreKey])
+	clearStore(store)
+
+	owner := sdk.AccAddress([]byte("test-owner-address01"))
+	channel := types.NodeChannel{ID: "1", Variable: "temperature"}
+	frame := types.GetTimeFrame(blockTime.Unix(), types.DefaultFrameSize)
+	dataNodes := []sdk.AccAddress{sdk.AccAddress([]byte("test-datanode-addr01")), sdk.AccAddress([]byte("test-datanode-addr02"))}
+	var timeStamps []int64
+	for _, address := range dataNodes {
+		legacyNode := legacyDataNode{ID: address, Owner: owner, Name: address.String(), Channels: []types.NodeChannel{channel}}
+		// three days of records, three records a day
+		for timeFrame := frame - 2; timeFrame <= frame; timeFrame++ {
+			dataRecord := legacyDataRecord{DataNode: address, NodeChannel: channel, TimeFrame: timeFrame}
+			for i := int64(0); i < 3; i++ {
+				ts := timeFrame*types.DefaultFrameSize + i*3600
+				dataRecord.Records = append(dataRecord.Records, legacyRecord{TimeStamp: uint32(ts), Value: uint32(i)})
+				timeStamps = append(timeStamps, ts*types.MillisPerSecond)
+			}
+			hash := types.GetDataRecordHash(address, &channel, timeFrame)
+			store.Set(hash[:], app.cdc.MustMarshalBinaryBare(dataRecord))
+			legacyNode.Records = append(legacyNode.Records, hash)
+		}
+		store.Set(address, app.cdc.MustMarshalBinaryBare(legacyNode))
+	}
+
+	// the upgrade handlers of the app, in the order they were planned
+	for _, name := range []string{
+		datanode.UpgradeKeyPrefixes,
+		datanode.UpgradeRecordsLayout,
+		datanode.UpgradeTimeFrameIndex,
+		datanode.UpgradeOwnerIndex,
+		datanode.UpgradeOwnershipOffers,
+		datanode.UpgradeRecordsV2,
+		datanode.UpgradeAcceptanceWindow,
+		datanode.UpgradeBandwidthQuota,
+		datanode.UpgradeStorageDeposits,
+		datanode.UpgradeRetention,
+		datanode.UpgradeFleetKeys,
+		datanode.UpgradeFrameSize,
+		datanode.UpgradeBandwidthGas,
+	} {
+		require.True(t, app.upgradeKeeper.HasHandler(name), name)
+		app.upgradeKeeper.ApplyUpgrade(ctx, upgrade.Plan{Name: name, Height: ctx.BlockHeight()})
+	}
+
+	k := app.dataNodeKeeper
+	require.Equal(t, types.DefaultParams(), k.GetParams(ctx))
+
+	owned, _ := k.GetDataNodesByOwner(ctx, owner, nil, 10)
+	require.Len(t, owned, 2)
+	for i, address := range dataNodes {
+		require.Equal(t, address, owned[i].ID)
+		require.Equal(t, address.String(), owned[i].Name)
+		require.Equal(t, []types.NodeChannel{channel}, owned[i].Channels)
+
+		records, next, err := k.GetRecordsRange(ctx, address, "1", 0, blockTime.Unix()*types.MillisPerSecond, 100)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), next)
+		require.Len(t, records, 9)
+		for j, record := range records {
+			require.Equal(t, types.Record{TimeStamp: timeStamps[i*9+j], Value: int64(j % 3)}, record)
+		}
+
+		stats, err := k.GetDataNodeStats(ctx, address)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), stats.TimeFrames)
+		require.True(t, k.GetStorageDeposit(ctx, address).Bytes > 0)
+	}
+
+	for _, invariant := range []sdk.Invariant{keeper.StorageDepositsPoolInvariant(k), keeper.StorageDepositsBytesInvariant(k)} {
+		msg, broken := invariant(ctx)
+		require.False(t, broken, msg)
+	}
+
+	// no channel nor the module set a retention, nothing is pruned
+	require.Empty(t, k.PruneExpiredRecords(ctx, blockTime.Add(10*365*24*time.Hour)))
+}
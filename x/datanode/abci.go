@@ -1,6 +1,10 @@
 package datanode
 
 import (
+	"strconv"
+
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	abci "github.com/tendermint/tendermint/abci/types"
 )
@@ -11,7 +15,47 @@ func BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock, k DataNodeKeeper)
 	// 	TODO: fill out if your application requires beginblock, if not you can delete this function
 }
 
-// EndBlocker called every block, process inflation, update validator set.
+// EndBlocker called every block, drops the ownership offers that expired, returns the coins of the
+// completed bandwidth unbondings, rebuilds the time frame index when the frame size changed and prunes
+// the records past their channel retention
 func EndBlocker(ctx sdk.Context, k DataNodeKeeper) {
-	// 	TODO: fill out if your application requires endblock, if not you can delete this function
+	for _, offer := range k.GetExpiredOwnershipOffers(ctx, ctx.BlockTime()) {
+		k.DeleteOwnershipOffer(ctx, offer.DataNode)
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeOwnershipOfferExpired,
+				sdk.NewAttribute(types.AttributeKeyDataNode, offer.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyOwner, offer.Owner.String()),
+				sdk.NewAttribute(types.AttributeKeyNewOwner, offer.NewOwner.String()),
+			),
+		)
+	}
+
+	for _, paid := range k.CompleteBandwidthUnbondings(ctx, ctx.BlockTime()) {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeBandwidthUnbonded,
+				sdk.NewAttribute(types.AttributeKeyOwner, paid.Owner.String()),
+				sdk.NewAttribute(types.AttributeKeyAmount, paid.Amount.String()),
+			),
+		)
+	}
+
+	k.ReindexTimeFrames(ctx)
+
+	frameSize := k.FrameSize(ctx) * types.MillisPerSecond
+	for _, pruned := range k.PruneExpiredRecords(ctx, ctx.BlockTime()) {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeRecordsPruned,
+				sdk.NewAttribute(types.AttributeKeyDataNode, pruned.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyChannel, pruned.Channel),
+				sdk.NewAttribute(types.AttributeKeyCount, strconv.Itoa(pruned.Count)),
+				sdk.NewAttribute(types.AttributeKeyFrom, strconv.FormatInt(pruned.TimeFrame*frameSize, 10)),
+				sdk.NewAttribute(types.AttributeKeyTo, strconv.FormatInt((pruned.TimeFrame+1)*frameSize, 10)),
+				sdk.NewAttribute(types.AttributeKeyReason, types.AttributeValueRetention),
+			),
+		)
+		emitStorageRefunded(ctx, pruned.Refund)
+	}
 }
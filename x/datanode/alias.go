@@ -14,16 +14,37 @@ const (
 	StoreKey          = types.StoreKey
 	DefaultParamspace = types.DefaultParamspace
 	QuerierRoute      = types.QuerierRoute
+
+	UpgradeKeyPrefixes    = types.UpgradeKeyPrefixes
+	UpgradeRecordsLayout  = types.UpgradeRecordsLayout
+	UpgradeTimeFrameIndex = types.UpgradeTimeFrameIndex
+	UpgradeOwnerIndex     = types.UpgradeOwnerIndex
+
+	UpgradeOwnershipOffers  = types.UpgradeOwnershipOffers
+	UpgradeRecordsV2        = types.UpgradeRecordsV2
+	UpgradeAcceptanceWindow = types.UpgradeAcceptanceWindow
+	UpgradeBandwidthQuota   = types.UpgradeBandwidthQuota
+	UpgradeStorageDeposits  = types.UpgradeStorageDeposits
+	UpgradeRetention        = types.UpgradeRetention
+	UpgradeFleetKeys        = types.UpgradeFleetKeys
+	UpgradeFrameSize        = types.UpgradeFrameSize
+	UpgradeBandwidthGas     = types.UpgradeBandwidthGas
+
+	StorageDepositPoolName = types.StorageDepositPoolName
 )
 
 var (
 	// functions aliases
 	NewKeeper                        = keeper.NewKeeper
 	NewQuerier                       = keeper.NewQuerier
+	RegisterInvariants               = keeper.RegisterInvariants
 	RegisterCodec                    = types.RegisterCodec
 	NewGenesisState                  = types.NewGenesisState
 	DefaultGenesisState              = types.DefaultGenesisState
 	ValidateGenesis                  = types.ValidateGenesis
+	NewParams                        = types.NewParams
+	DefaultParams                    = types.DefaultParams
+	ParamKeyTable                    = types.ParamKeyTable
 	NewDelegatedDeductFeeAnteHandler = ante.NewDelegatedDeductFeeAnteHandler
 
 	// variable aliases
@@ -37,4 +58,15 @@ type (
 	Params         = types.Params
 	DataNode       = types.DataNode
 	DataRecord     = types.DataRecord
+	NodeChannel    = types.NodeChannel
+	Record         = types.Record
+	OwnershipOffer = types.OwnershipOffer
+	RoleGrant      = types.RoleGrant
+	Role           = types.Role
+	Fleet          = types.Fleet
+	DeviceType     = types.DeviceType
+	FeeAllowance   = types.FeeAllowance
+	BandwidthBond  = types.BandwidthBond
+	StorageDeposit = types.StorageDeposit
+	Rollup         = types.Rollup
 )
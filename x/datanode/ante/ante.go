@@ -11,11 +11,12 @@ import (
 
 // NewDelegatedDeductFeeAnteHandler returns an AnteHandler that checks and increments sequence
 // numbers, checks signatures & account numbers, and deducts fees from deducted wallet or the first
-// signer.
+// signer. Device txs without fees use the bandwidth quota of the datanode owner when it's enough, once
+// their signatures are verified, the other txs are checked against the minimum gas prices first.
 func NewDelegatedDeductFeeAnteHandler(ak authKeeper.AccountKeeper, supplyKeeper authTypes.SupplyKeeper, dk keeper.DataNodeKeeper, sigGasConsumer authAnte.SignatureVerificationGasConsumer) sdk.AnteHandler {
 	return sdk.ChainAnteDecorators(
 		authAnte.NewSetUpContextDecorator(), // outermost AnteDecorator. SetUpContext must be called first
-		authAnte.NewMempoolFeeDecorator(),
+		NewBandwidthMempoolFeeDecorator(dk), // skips the txs that could use the bandwidth quota, BandwidthDecorator checks them
 		authAnte.NewValidateBasicDecorator(),
 		authAnte.NewValidateMemoDecorator(ak),
 		authAnte.NewConsumeGasForTxSizeDecorator(ak),
@@ -24,6 +25,7 @@ func NewDelegatedDeductFeeAnteHandler(ak authKeeper.AccountKeeper, supplyKeeper
 		NewDelegatedDeductFeeDecorator(ak, supplyKeeper, dk),
 		authAnte.NewSigGasConsumeDecorator(ak, sigGasConsumer),
 		authAnte.NewSigVerificationDecorator(ak),
+		NewBandwidthDecorator(dk),                  // falls back to MempoolFeeDecorator when the bandwidth quota doesn't cover the tx
 		authAnte.NewIncrementSequenceDecorator(ak), // innermost AnteDecorator
 	)
 }
@@ -0,0 +1,96 @@
+package ante
+
+import (
+	"github.com/qonico/cosmos-iot/x/datanode/keeper"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authAnte "github.com/cosmos/cosmos-sdk/x/auth/ante"
+)
+
+// BandwidthDecorator lets the device txs without fees write their records on the bandwidth quota granted
+// by the coins bonded by the owner of the datanode, skipping the minimum gas prices of the validators.
+// The gas of the txs written on the quota is capped by the bandwidth gas per record, so they can't take
+// more block gas than their records need. Txs with fees, other txs, txs over the gas cap or records over
+// the quota left fall back to the MempoolFeeDecorator and pay their fees as usual
+// CONTRACT: Tx must implement FeeTx interface to use BandwidthDecorator
+// CONTRACT: the signatures must be verified before, the quota is only used by the datanode owners
+type BandwidthDecorator struct {
+	dataNodeKeeper keeper.DataNodeKeeper
+}
+
+func NewBandwidthDecorator(dk keeper.DataNodeKeeper) BandwidthDecorator {
+	return BandwidthDecorator{
+		dataNodeKeeper: dk,
+	}
+}
+
+func (bd BandwidthDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(DelegatedFeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a DelegatedFeeTx")
+	}
+
+	// the quota is used on check tx too, the mempool doesn't take more records than the quota left
+	if dataNode := bandwidthDataNode(ctx, bd.dataNodeKeeper, feeTx); dataNode != nil &&
+		bd.dataNodeKeeper.ConsumeBandwidth(ctx, dataNode.Owner, countRecords(feeTx.GetMsgs())) == nil {
+		return next(ctx, tx, simulate)
+	}
+
+	return authAnte.NewMempoolFeeDecorator().AnteHandle(ctx, tx, simulate, next)
+}
+
+// BandwidthMempoolFeeDecorator checks the minimum gas prices of the validators as the MempoolFeeDecorator
+// before the signatures are verified, so the txs without fees are rejected before taking their cost. Only
+// the device txs that could be written on the bandwidth quota skip it, the BandwidthDecorator checks them
+// once their signatures are verified
+// CONTRACT: Tx must implement FeeTx interface to use BandwidthMempoolFeeDecorator
+type BandwidthMempoolFeeDecorator struct {
+	dataNodeKeeper keeper.DataNodeKeeper
+}
+
+func NewBandwidthMempoolFeeDecorator(dk keeper.DataNodeKeeper) BandwidthMempoolFeeDecorator {
+	return BandwidthMempoolFeeDecorator{
+		dataNodeKeeper: dk,
+	}
+}
+
+func (bmd BandwidthMempoolFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(DelegatedFeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a DelegatedFeeTx")
+	}
+
+	if bandwidthDataNode(ctx, bmd.dataNodeKeeper, feeTx) != nil {
+		return next(ctx, tx, simulate)
+	}
+	return authAnte.NewMempoolFeeDecorator().AnteHandle(ctx, tx, simulate, next)
+}
+
+// bandwidthDataNode - returns the datanode of the device tx without fees that can be written on the
+// bandwidth quota of its owner, its gas within the gas of its records, otherwise nil
+func bandwidthDataNode(ctx sdk.Context, dk keeper.DataNodeKeeper, tx DelegatedFeeTx) *types.DataNode {
+	if !tx.GetFee().IsZero() {
+		return nil
+	}
+	dataNode := deviceDataNode(ctx, dk, tx)
+	if dataNode == nil || tx.GetGas() > uint64(countRecords(tx.GetMsgs()))*dk.BandwidthGasPerRecord(ctx) {
+		return nil
+	}
+	return dataNode
+}
+
+// countRecords - returns the number of records added by the msgs
+func countRecords(msgs []sdk.Msg) int64 {
+	var records int64
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case types.MsgAddRecords:
+			records += int64(len(msg.Records))
+		case types.MsgGatewayAddRecords:
+			records += int64(msg.RecordsCount())
+		}
+	}
+	return records
+}
@@ -0,0 +1,26 @@
+package ante
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+func TestCountRecords(t *testing.T) {
+	first := sdk.AccAddress([]byte("test-datanode-addr01"))
+	second := sdk.AccAddress([]byte("test-datanode-addr02"))
+	gateway := sdk.AccAddress([]byte("test-gateway-addr001"))
+
+	require.Equal(t, int64(0), countRecords(nil))
+	require.Equal(t, int64(6), countRecords([]sdk.Msg{
+		types.NewMsgAddRecords(first, make([]types.NewRecord, 2), false),
+		types.NewMsgGatewayAddRecords(gateway, []types.DataNodeRecords{
+			{DataNode: first, Records: make([]types.NewRecord, 1)},
+			{DataNode: second, Records: make([]types.NewRecord, 3)},
+		}, false),
+		types.NewMsgSetBackfill(first, first, true),
+	}))
+}
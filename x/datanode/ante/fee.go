@@ -8,6 +8,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	authAnte "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authexported "github.com/cosmos/cosmos-sdk/x/auth/exported"
 	authKeeper "github.com/cosmos/cosmos-sdk/x/auth/keeper"
 	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 
@@ -26,8 +27,18 @@ type DelegatedFeeTx interface {
 	GetSigners() []sdk.AccAddress
 }
 
-// DelegatedDeductFeeDecorator deducts fees from the delegated account or the first signer of the tx
-// If the fee payer does not have the funds to pay for the fees, return with InsufficientFunds error
+// DelegatedDeductFeeDecorator deducts the fees of the txs writing records from the accounts paying for
+// the datanodes, within their fee allowances:
+//   - gateway txs, only MsgGatewayAddRecords, split the fees among the datanodes written, each share is
+//     paid by the fee payer of the datanode or else its owner
+//   - device txs, only MsgAddRecords signed by an active datanode, are paid by the fee payer of the
+//     first datanode signing, or else its owner, or else the datanode itself. Without fees they can
+//     use the bandwidth quota of the owner instead, see BandwidthDecorator
+//   - any other tx, like the owner msgs as MsgSetOwner, is paid by its first signer as usual, a datanode
+//     signing it doesn't move the fees to its owner
+//
+// The fee payer of a datanode is the one set on it, or the one set on its fleet, or none. The first
+// account with the funds pays, if none has them return with InsufficientFunds error
 // Call next AnteHandler if fees successfully deducted
 // CONTRACT: Tx must implement FeeTx interface to use DelegatedDeductFeeDecorator
 type DelegatedDeductFeeDecorator struct {
@@ -54,32 +65,47 @@ func (dfd DelegatedDeductFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, si
 		panic(fmt.Sprintf("%s module account has not been set", authTypes.FeeCollectorName))
 	}
 
-	signerAddrs := feeTx.GetSigners()
-	var dataNode (*types.DataNode)
-
-	for _, sa := range signerAddrs {
-		dn, err := dfd.dataNodeKeeper.GetDataNode(ctx, sa)
-		if err == nil {
-			dataNode = dn
-			break
+	// gateway txs are paid by the fee payers or the owners of the datanodes written
+	shares, err := dfd.gatewayFeeShares(ctx, feeTx.GetMsgs())
+	if err != nil {
+		return ctx, err
+	}
+	if shares != nil {
+		if !feeTx.GetFee().IsZero() {
+			for _, share := range splitFee(feeTx.GetFee(), shares) {
+				if share.fee.IsZero() {
+					continue
+				}
+				if err := dfd.spendFeeAllowance(ctx, share.dataNode, share.fee); err != nil {
+					return ctx, err
+				}
+				if err := dfd.deductFees(ctx, share.payers, share.fee); err != nil {
+					return ctx, err
+				}
+			}
 		}
+		return next(ctx, tx, simulate)
 	}
 
-	// Check if some DataNode signed the Transaction, if not use default DeductFeeDecorator
+	// Check if some active DataNode signed a device Transaction, if not use default DeductFeeDecorator
+	dataNode := deviceDataNode(ctx, dfd.dataNodeKeeper, feeTx)
 	if dataNode == nil {
 		return authAnte.NewDeductFeeDecorator(dfd.ak, dfd.supplyKeeper).AnteHandle(ctx, tx, simulate, next)
 	}
 
-	feePayer := dataNode.Owner
-	feePayerAcc := dfd.ak.GetAccount(ctx, feePayer)
-
-	if feePayerAcc == nil {
-		return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "fee payer address: %s does not exist", feePayer)
-	}
-
-	// deduct the fees
+	// deduct the fees, the device paying for itself is not capped by the allowance of the owner
 	if !feeTx.GetFee().IsZero() {
-		err = authAnte.DeductFees(dfd.supplyKeeper, ctx, feePayerAcc, feeTx.GetFee())
+		payers := append(dfd.dataNodeKeeper.GetFeePayers(ctx, *dataNode), dataNode.ID)
+		payer, err := dfd.selectFeePayer(ctx, payers, feeTx.GetFee())
+		if err != nil {
+			return ctx, err
+		}
+		if !payer.GetAddress().Equals(dataNode.ID) {
+			if err := dfd.spendFeeAllowance(ctx, dataNode.ID, feeTx.GetFee()); err != nil {
+				return ctx, err
+			}
+		}
+		err = authAnte.DeductFees(dfd.supplyKeeper, ctx, payer, feeTx.GetFee())
 		if err != nil {
 			return ctx, err
 		}
@@ -87,3 +113,140 @@ func (dfd DelegatedDeductFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, si
 
 	return next(ctx, tx, simulate)
 }
+
+// deviceDataNode - returns the first active datanode signing the tx when all its msgs are MsgAddRecords,
+// otherwise nil. Archived datanodes are retired devices, their owners don't pay for them anymore
+func deviceDataNode(ctx sdk.Context, dk keeper.DataNodeKeeper, tx DelegatedFeeTx) *types.DataNode {
+	if !isDeviceTx(tx.GetMsgs()) {
+		return nil
+	}
+	for _, sa := range tx.GetSigners() {
+		dataNode, err := dk.GetDataNode(ctx, sa)
+		if err == nil && !dataNode.Archived {
+			return dataNode
+		}
+	}
+	return nil
+}
+
+// isDeviceTx - returns true if all the msgs add records signed by their datanodes
+func isDeviceTx(msgs []sdk.Msg) bool {
+	for _, msg := range msgs {
+		if _, ok := msg.(types.MsgAddRecords); !ok {
+			return false
+		}
+	}
+	return len(msgs) > 0
+}
+
+// selectFeePayer - returns the account of the first payer with the funds to pay the fee
+func (dfd DelegatedDeductFeeDecorator) selectFeePayer(ctx sdk.Context, payers []sdk.AccAddress, fee sdk.Coins) (authexported.Account, error) {
+	for _, payer := range payers {
+		acc := dfd.ak.GetAccount(ctx, payer)
+		if acc != nil && acc.SpendableCoins(ctx.BlockHeader().Time).IsAllGTE(fee) {
+			return acc, nil
+		}
+	}
+	return nil, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "no fee payer can pay %s, tried %s", fee, payers)
+}
+
+// deductFees - deducts the fee from the first payer with the funds to pay it
+func (dfd DelegatedDeductFeeDecorator) deductFees(ctx sdk.Context, payers []sdk.AccAddress, fee sdk.Coins) error {
+	acc, err := dfd.selectFeePayer(ctx, payers, fee)
+	if err != nil {
+		return err
+	}
+	return authAnte.DeductFees(dfd.supplyKeeper, ctx, acc, fee)
+}
+
+// spendFeeAllowance - charges the fee to the fee allowance of the datanode and emits an event by cap
+// fully spent, it fails when the fee goes over a cap
+func (dfd DelegatedDeductFeeDecorator) spendFeeAllowance(ctx sdk.Context, dataNode sdk.AccAddress, fee sdk.Coins) error {
+	caps, err := dfd.dataNodeKeeper.SpendFeeAllowance(ctx, dataNode, fee)
+	if err != nil {
+		return err
+	}
+	for _, feeCap := range caps {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeFeeCapReached,
+				sdk.NewAttribute(types.AttributeKeyDataNode, dataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyCap, feeCap),
+			),
+		)
+	}
+	return nil
+}
+
+// feeShare - part of the tx fee paid for the records of a datanode, by the first of its payers with the funds
+type feeShare struct {
+	dataNode sdk.AccAddress
+	payers   []sdk.AccAddress
+	records  int64
+	fee      sdk.Coins
+}
+
+// gatewayFeeShares - returns the datanodes written by the tx with their fee payers and number of records,
+// in order of appearance, when all the msgs of the tx are MsgGatewayAddRecords. Only the records of the
+// channels the gateway can write are counted, as the handler rejects the others, otherwise it could spend
+// the fees of any owner. The datanodes without such records pay nothing, the tx fails when none has them
+func (dfd DelegatedDeductFeeDecorator) gatewayFeeShares(ctx sdk.Context, msgs []sdk.Msg) ([]feeShare, error) {
+	var shares []feeShare
+	index := make(map[string]int)
+	for _, msg := range msgs {
+		gatewayMsg, ok := msg.(types.MsgGatewayAddRecords)
+		if !ok {
+			return nil, nil
+		}
+		for _, batch := range gatewayMsg.DataNodes {
+			dataNode, err := dfd.dataNodeKeeper.GetDataNode(ctx, batch.DataNode)
+			if err != nil || dataNode.Archived {
+				return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "datanode %s not defined or archived", batch.DataNode)
+			}
+			canWrite := dfd.dataNodeKeeper.ChannelWriter(ctx, *dataNode, gatewayMsg.Gateway)
+			var records int64
+			for _, re := range batch.Records {
+				if canWrite(re.NodeChannelID) {
+					records++
+				}
+			}
+			if records == 0 {
+				continue
+			}
+
+			// the same datanode can be written by several msgs of the tx
+			i, ok := index[batch.DataNode.String()]
+			if !ok {
+				i = len(shares)
+				index[batch.DataNode.String()] = i
+				shares = append(shares, feeShare{dataNode: batch.DataNode, payers: dfd.dataNodeKeeper.GetFeePayers(ctx, *dataNode)})
+			}
+			shares[i].records += records
+		}
+	}
+	if len(shares) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "the gateway can't write any record of the tx")
+	}
+	return shares, nil
+}
+
+// splitFee - splits the fee among the payers proportionally to their records, the first payer
+// takes the rounding remainder
+func splitFee(fee sdk.Coins, shares []feeShare) []feeShare {
+	var total int64
+	for _, share := range shares {
+		total += share.records
+	}
+
+	remainder := fee
+	for i := 1; i < len(shares); i++ {
+		var coins []sdk.Coin
+		for _, coin := range fee {
+			coins = append(coins, sdk.NewCoin(coin.Denom, coin.Amount.MulRaw(shares[i].records).QuoRaw(total)))
+		}
+		shares[i].fee = sdk.NewCoins(coins...)
+		remainder = remainder.Sub(shares[i].fee)
+	}
+	shares[0].fee = remainder
+	return shares
+}
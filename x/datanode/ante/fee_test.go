@@ -0,0 +1,33 @@
+package ante
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestSplitFee(t *testing.T) {
+	first := sdk.AccAddress([]byte("test-owner-address01"))
+	second := sdk.AccAddress([]byte("test-owner-address02"))
+	third := sdk.AccAddress([]byte("test-owner-address03"))
+
+	shares := splitFee(sdk.NewCoins(sdk.NewInt64Coin("stake", 100)), []feeShare{
+		{dataNode: first, records: 1},
+		{dataNode: second, records: 1},
+		{dataNode: third, records: 1},
+	})
+
+	// the first payer takes the rounding remainder
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 34)), shares[0].fee)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 33)), shares[1].fee)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 33)), shares[2].fee)
+
+	shares = splitFee(sdk.NewCoins(sdk.NewInt64Coin("stake", 1)), []feeShare{
+		{dataNode: first, records: 1},
+		{dataNode: second, records: 9},
+	})
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)), shares[0].fee)
+	require.True(t, shares[1].fee.IsZero())
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
@@ -13,6 +15,12 @@ import (
 	"github.com/qonico/cosmos-iot/x/datanode/types"
 )
 
+const (
+	flagLimit = "limit"
+	flagStart = "start"
+	flagUnit  = "unit"
+)
+
 // GetQueryCmd returns the cli query commands for this module
 func GetQueryCmd(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	// Group datanode queries under a subcommand
@@ -26,14 +34,51 @@ func GetQueryCmd(queryRoute string, cdc *codec.Codec) *cobra.Command {
 
 	datanodeQueryCmd.AddCommand(
 		flags.GetCommands(
+			GetCmdParams(types.StoreKey, cdc),
 			GetCmdDataNode(types.StoreKey, cdc),
+			GetCmdDataNodes(types.StoreKey, cdc),
+			GetCmdDataNodesByOwner(types.StoreKey, cdc),
+			GetCmdOwnershipOffer(types.StoreKey, cdc),
+			GetCmdFeeAllowance(types.StoreKey, cdc),
+			GetCmdBandwidthQuota(types.StoreKey, cdc),
+			GetCmdBandwidthUnbonding(types.StoreKey, cdc),
+			GetCmdStorageDeposit(types.StoreKey, cdc),
+			GetCmdRollups(types.StoreKey, cdc),
+			GetCmdRoles(types.StoreKey, cdc),
+			GetCmdFleet(types.StoreKey, cdc),
+			GetCmdFleetMembers(types.StoreKey, cdc),
+			GetCmdDeviceType(types.StoreKey, cdc),
+			GetCmdDeviceTypes(types.StoreKey, cdc),
+			GetCmdDeviceTypeNodes(types.StoreKey, cdc),
 			GetCmdRecords(types.StoreKey, cdc),
+			GetCmdRecordsRange(types.StoreKey, cdc),
 		)...,
 	)
 
 	return datanodeQueryCmd
 }
 
+// GetCmdParams queries the datanode module parameters
+func GetCmdParams(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params",
+		Short: "datanode module parameters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryParams), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.Params
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
 // GetCmdDataNode queries information about a datanode
 func GetCmdDataNode(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
@@ -50,26 +95,398 @@ func GetCmdDataNode(queryRoute string, cdc *codec.Codec) *cobra.Command {
 				return nil
 			}
 
-			var out types.DataNode
+			var out types.QueryResDataNode
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdDataNodes lists the datanodes ordered by address
+func GetCmdDataNodes(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datanodes",
+		Short: "list datanodes",
+		Long: strings.TrimSpace(`
+List the datanodes ordered by address. Results are paginated, when the response has a
+next address use it as --start to get the following page.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryDataNodes, pagePath()), nil)
+			if err != nil {
+				fmt.Printf("could not get datanodes - %s \n", err)
+				return nil
+			}
+
+			var out types.QueryResDataNodes
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Int(flagLimit, types.DefaultDataNodesLimit, "maximum number of datanodes to return")
+	cmd.Flags().String(flagStart, "", "address of the first datanode to return")
+	return cmd
+}
+
+// GetCmdDataNodesByOwner lists the datanodes of an owner ordered by address
+func GetCmdDataNodesByOwner(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datanodes-by-owner [owner]",
+		Short: "list datanodes of owner",
+		Long: strings.TrimSpace(`
+List the datanodes of an owner ordered by address. Results are paginated, when the response
+has a next address use it as --start to get the following page.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			owner := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s/%s", queryRoute, types.QueryOwnerNodes, owner, pagePath()), nil)
+			if err != nil {
+				fmt.Printf("could not get datanodes of - %s \n", owner)
+				return nil
+			}
+
+			var out types.QueryResDataNodes
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Int(flagLimit, types.DefaultDataNodesLimit, "maximum number of datanodes to return")
+	cmd.Flags().String(flagStart, "", "address of the first datanode to return")
+	return cmd
+}
+
+// GetCmdOwnershipOffer queries the pending ownership offer of a datanode
+func GetCmdOwnershipOffer(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ownership-offer [address]",
+		Short: "pending ownership offer of datanode address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryOwnershipOffer, address), nil)
+			if err != nil {
+				fmt.Printf("could not get ownership offer of - %s \n", address)
+				return nil
+			}
+
+			var out types.OwnershipOffer
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdFeeAllowance queries the fee allowance of a datanode
+func GetCmdFeeAllowance(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "fee-allowance [address]",
+		Short: "fee allowance of datanode address with the fees that can still be charged",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryFeeAllowance, address), nil)
+			if err != nil {
+				fmt.Printf("could not get fee allowance of - %s \n", address)
+				return nil
+			}
+
+			var out types.QueryResFeeAllowance
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdBandwidthQuota queries the bandwidth quota of an owner
+func GetCmdBandwidthQuota(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "bandwidth-quota [owner]",
+		Short: "records the datanodes of owner can write without fees on the current period",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			owner := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryBandwidthQuota, owner), nil)
+			if err != nil {
+				fmt.Printf("could not get bandwidth quota of - %s \n", owner)
+				return nil
+			}
+
+			var out types.QueryResBandwidthQuota
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdBandwidthUnbonding queries the coins unbonding from the bandwidth bond of an owner
+func GetCmdBandwidthUnbonding(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "bandwidth-unbonding [owner]",
+		Short: "coins unbonded by owner with the time they are returned",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			owner := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryBandwidthUnbonding, owner), nil)
+			if err != nil {
+				fmt.Printf("could not get bandwidth unbonding of - %s \n", owner)
+				return nil
+			}
+
+			var out []types.BandwidthUnbonding
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdStorageDeposit queries the bytes stored by a datanode and the coins deposited for them
+func GetCmdStorageDeposit(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "storage-deposit [address]",
+		Short: "bytes stored by datanode address and coins deposited for them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryStorageDeposit, address), nil)
+			if err != nil {
+				fmt.Printf("could not get storage deposit of - %s \n", address)
+				return nil
+			}
+
+			var out types.StorageDeposit
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRollups queries the summaries of the records pruned from a datanode channel
+func GetCmdRollups(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollups [address] [channel]",
+		Short: "summaries of the records pruned from datanode address channel by time frame",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+			channel := args[1]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s/%s", queryRoute, types.QueryRollups, address, channel), nil)
+			if err != nil {
+				fmt.Printf("could not get rollups of - %s %s \n", address, channel)
+				return nil
+			}
+
+			var out []types.Rollup
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRoles queries the role grants of a datanode
+func GetCmdRoles(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "roles [address]",
+		Short: "roles granted on datanode address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryRoles, address), nil)
+			if err != nil {
+				fmt.Printf("could not get roles of - %s \n", address)
+				return nil
+			}
+
+			var out []types.RoleGrant
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdFleet queries a fleet
+func GetCmdFleet(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "fleet [fleet]",
+		Short: "fleet admins, channel template and fee payer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			fleet := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryFleet, fleet), nil)
+			if err != nil {
+				fmt.Printf("could not get fleet - %s \n", fleet)
+				return nil
+			}
+
+			var out types.Fleet
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdFleetMembers lists the member datanodes of a fleet ordered by address
+func GetCmdFleetMembers(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet-members [fleet]",
+		Short: "list datanodes of fleet",
+		Long: strings.TrimSpace(`
+List the member datanodes of a fleet ordered by address. Results are paginated, when the response
+has a next address use it as --start to get the following page.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			fleet := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s/%s", queryRoute, types.QueryFleetMembers, fleet, pagePath()), nil)
+			if err != nil {
+				fmt.Printf("could not get datanodes of fleet - %s \n", fleet)
+				return nil
+			}
+
+			var out types.QueryResDataNodes
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Int(flagLimit, types.DefaultDataNodesLimit, "maximum number of datanodes to return")
+	cmd.Flags().String(flagStart, "", "address of the first datanode to return")
+	return cmd
+}
+
+// GetCmdDeviceType queries a version of a device type
+func GetCmdDeviceType(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "device-type [device-type]",
+		Short: "device type channels, defaults to the latest version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			deviceType := args[0]
+
+			path := fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryDeviceType, deviceType)
+			if version := viper.GetUint(flagVersion); version > 0 {
+				path += fmt.Sprintf("/%d", version)
+			}
+
+			res, _, err := cliCtx.QueryWithData(path, nil)
+			if err != nil {
+				fmt.Printf("could not get device type - %s \n", deviceType)
+				return nil
+			}
+
+			var out types.DeviceType
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Uint32(flagVersion, 0, "version of the device type, 0 for the latest")
+	return cmd
+}
+
+// GetCmdDeviceTypes lists the latest version of the device types
+func GetCmdDeviceTypes(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "device-types",
+		Short: "list latest version of device types",
+		Long: strings.TrimSpace(`
+List the latest version of the device types. Results are paginated, when the response has a next
+device type use it as --start to get the following page.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryDeviceTypes, pagePath()), nil)
+			if err != nil {
+				fmt.Printf("could not get device types\n")
+				return nil
+			}
+
+			var out types.QueryResDeviceTypes
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Int(flagLimit, types.DefaultDataNodesLimit, "maximum number of device types to return")
+	cmd.Flags().String(flagStart, "", "id of the first device type to return")
+	return cmd
+}
+
+// GetCmdDeviceTypeNodes lists the datanodes linked to a device type ordered by address
+func GetCmdDeviceTypeNodes(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "device-type-nodes [device-type]",
+		Short: "list datanodes linked to device type",
+		Long: strings.TrimSpace(`
+List the datanodes linked to any version of a device type ordered by address. Results are paginated,
+when the response has a next address use it as --start to get the following page.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			deviceType := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s/%s", queryRoute, types.QueryDeviceTypeNodes, deviceType, pagePath()), nil)
+			if err != nil {
+				fmt.Printf("could not get datanodes of device type - %s \n", deviceType)
+				return nil
+			}
+
+			var out types.QueryResDataNodes
 			cdc.MustUnmarshalJSON(res, &out)
 			return cliCtx.PrintOutput(out)
 		},
 	}
+	cmd.Flags().Int(flagLimit, types.DefaultDataNodesLimit, "maximum number of datanodes to return")
+	cmd.Flags().String(flagStart, "", "address of the first datanode to return")
+	return cmd
+}
+
+// pagePath returns the <limit>[/<start>] query path from the pagination flags
+func pagePath() string {
+	path := fmt.Sprintf("%d", viper.GetInt(flagLimit))
+	if start := viper.GetString(flagStart); start != "" {
+		path += "/" + start
+	}
+	return path
 }
 
 // GetCmdRecords queries information about records on a time frame
 func GetCmdRecords(queryRoute string, cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "records [address] [channelID] [date]",
 		Short: "records address channelID date",
-		Args:  cobra.ExactArgs(3),
+		Long: strings.TrimSpace(`
+Query the records of a datanode channel on the time frame of date. The date is read with the
+unit flag: a time frame index (frame), seconds (s) or milliseconds (ms) since epoch.`),
+		Args: cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
 			address := args[0]
 			channelID := args[1]
 			date := args[2]
 
-			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/records/%s/%s/%s", queryRoute, address, channelID, date), nil)
+			unit := viper.GetString(flagUnit)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/records/%s/%s/%s/%s", queryRoute, address, channelID, date, unit), nil)
 			if err != nil {
 				fmt.Printf("could not get records on - %s %s %s \n", address, channelID, date)
 				return nil
@@ -80,4 +497,42 @@ func GetCmdRecords(queryRoute string, cdc *codec.Codec) *cobra.Command {
 			return cliCtx.PrintOutput(out)
 		},
 	}
+	cmd.Flags().String(flagUnit, string(types.TimeUnitSecond), "unit of the date: frame, s or ms")
+	return cmd
+}
+
+// GetCmdRecordsRange queries records between two timestamps across time frames
+func GetCmdRecordsRange(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "records-range [address] [channelID] [from] [to]",
+		Short: "records address channelID between from and to timestamps",
+		Long: strings.TrimSpace(`
+Query the records of a datanode channel between from and to timestamps (both inclusive),
+read with the unit flag: seconds (s) or milliseconds (ms) since epoch. Results are paginated,
+when the response has a non zero next value use it as from, with the ms unit, to get the
+following page.`),
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+			channelID := args[1]
+			from := args[2]
+			to := args[3]
+			limit := viper.GetInt(flagLimit)
+			unit := viper.GetString(flagUnit)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/records-range/%s/%s/%s/%s/%d/%s", queryRoute, address, channelID, from, to, limit, unit), nil)
+			if err != nil {
+				fmt.Printf("could not get records on - %s %s %s %s \n", address, channelID, from, to)
+				return nil
+			}
+
+			var out types.QueryResRecordsRange
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+	cmd.Flags().Int(flagLimit, types.DefaultRecordsRangeLimit, "maximum number of records to return")
+	cmd.Flags().String(flagUnit, string(types.TimeUnitSecond), "unit of from and to: s or ms")
+	return cmd
 }
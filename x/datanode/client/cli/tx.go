@@ -3,8 +3,12 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
@@ -17,6 +21,24 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+const (
+	flagName            = "name"
+	flagDescription     = "description"
+	flagTags            = "tags"
+	flagLocation        = "location"
+	flagFirmwareVersion = "firmware-version"
+	flagArchive         = "archive"
+	flagChannels        = "channels"
+	flagExpiry          = "expiry"
+	flagDeviceType      = "device-type"
+	flagVersion         = "version"
+	flagStrict          = "strict"
+	flagTotalLimit      = "total-limit"
+	flagPeriodLimit     = "period-limit"
+	flagPeriod          = "period"
+	flagTxLimit         = "tx-limit"
+)
+
 // GetTxCmd returns the transaction commands for this module
 func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 	datanodeTxCmd := &cobra.Command{
@@ -29,8 +51,33 @@ func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 
 	datanodeTxCmd.AddCommand(flags.PostCommands(
 		GetCmdSetOwner(cdc),
+		GetCmdOfferOwnership(cdc),
+		GetCmdAcceptOwnership(cdc),
+		GetCmdCancelOwnershipOffer(cdc),
+		GetCmdUpdateDataNode(cdc),
+		GetCmdDeleteDataNode(cdc),
+		GetCmdSetBackfill(cdc),
+		GetCmdSetFeeAllowance(cdc),
+		GetCmdSetFeePayer(cdc),
+		GetCmdBondBandwidth(cdc),
+		GetCmdUnbondBandwidth(cdc),
 		GetCmdUpdateChannels(cdc),
 		GetCmdAddRecords(cdc),
+		GetCmdPruneRecords(cdc),
+		GetCmdGrantRole(cdc),
+		GetCmdRevokeRole(cdc),
+		GetCmdGatewayAddRecords(cdc),
+		GetCmdCreateFleet(cdc),
+		GetCmdSetFleetAdmins(cdc),
+		GetCmdSetFleetFeePayer(cdc),
+		GetCmdAddFleetMember(cdc),
+		GetCmdRemoveFleetMember(cdc),
+		GetCmdSetFleetTemplate(cdc),
+		GetCmdPublishDeviceType(cdc),
+		GetCmdTransferDeviceType(cdc),
+		GetCmdLinkDeviceType(cdc),
+		GetCmdUnlinkDeviceType(cdc),
+		GetCmdUpgradeDataNodes(cdc),
 	)...)
 
 	return datanodeTxCmd
@@ -38,10 +85,14 @@ func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 
 // GetCmdSetOwner is the CLI command for sending a BuyName transaction
 func GetCmdSetOwner(cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "set-owner [datanode] [owner] [newowner] [name]",
 		Short: "set owner of datanode or register a new one",
-		Args:  cobra.RangeArgs(3, 4),
+		Long: strings.TrimSpace(`
+Set the owner of a datanode or register a new one. Transfers to another account must be signed by
+both the owner and the new owner, use offer-ownership and accept-ownership otherwise. New datanodes
+can be linked to the latest version of a device type with --device-type.`),
+		Args: cobra.RangeArgs(3, 4),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inBuf := bufio.NewReader(cmd.InOrStdin())
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
@@ -68,7 +119,7 @@ func GetCmdSetOwner(cdc *codec.Codec) *cobra.Command {
 				name = args[3]
 			}
 
-			msg := types.NewMsgSetOwner(datanode, owner, newOwner, name)
+			msg := types.NewMsgSetOwner(datanode, owner, newOwner, name, viper.GetString(flagDeviceType))
 			err = msg.ValidateBasic()
 			if err != nil {
 				return err
@@ -77,13 +128,15 @@ func GetCmdSetOwner(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().String(flagDeviceType, "", "device type linked to the new datanode")
+	return cmd
 }
 
-// GetCmdUpdateChannels is the CLI command for sending a BuyName transaction
-func GetCmdUpdateChannels(cdc *codec.Codec) *cobra.Command {
+// GetCmdOfferOwnership is the CLI command for sending a MsgOfferOwnership transaction
+func GetCmdOfferOwnership(cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
-		Use:   "update-channels [owner] [datanode] [channels]",
-		Short: "update channels of datanode",
+		Use:   "offer-ownership [owner] [datanode] [newowner]",
+		Short: "offer the ownership of datanode, newowner must accept it before it expires",
 		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inBuf := bufio.NewReader(cmd.InOrStdin())
@@ -101,10 +154,12 @@ func GetCmdUpdateChannels(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			var channels ([]types.ChannelUpdate)
-			cdc.MustUnmarshalJSON([]byte(args[2]), &channels)
+			newOwner, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
 
-			msg := types.NewMsgUpdateChannels(owner, datanode, channels)
+			msg := types.NewMsgOfferOwnership(owner, datanode, newOwner)
 			err = msg.ValidateBasic()
 			if err != nil {
 				return err
@@ -115,11 +170,11 @@ func GetCmdUpdateChannels(cdc *codec.Codec) *cobra.Command {
 	}
 }
 
-// GetCmdAddRecords is the CLI command for sending a BuyName transaction
-func GetCmdAddRecords(cdc *codec.Codec) *cobra.Command {
+// GetCmdAcceptOwnership is the CLI command for sending a MsgAcceptOwnership transaction
+func GetCmdAcceptOwnership(cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
-		Use:   "add-records [datanode] [records]",
-		Short: "add records to data record time frame",
+		Use:   "accept-ownership [newowner] [datanode]",
+		Short: "accept the pending ownership offer of datanode",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inBuf := bufio.NewReader(cmd.InOrStdin())
@@ -127,15 +182,977 @@ func GetCmdAddRecords(cdc *codec.Codec) *cobra.Command {
 
 			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
 
-			datanode, err := sdk.AccAddressFromBech32(args[0])
+			newOwner, err := sdk.AccAddressFromBech32(args[0])
 			if err != nil {
 				return err
 			}
 
-			var records ([]types.NewRecord)
-			cdc.MustUnmarshalJSON([]byte(args[1]), &records)
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgAcceptOwnership(newOwner, datanode)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdCancelOwnershipOffer is the CLI command for sending a MsgCancelOwnershipOffer transaction
+func GetCmdCancelOwnershipOffer(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel-ownership-offer [owner] [datanode]",
+		Short: "cancel the pending ownership offer of datanode",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgCancelOwnershipOffer(owner, datanode)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdUpdateDataNode is the CLI command for sending a MsgUpdateDataNode transaction
+func GetCmdUpdateDataNode(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-datanode [owner] [datanode]",
+		Short: "update metadata of datanode, all the metadata is replaced by the flag values",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgUpdateDataNode(owner, datanode,
+				viper.GetString(flagName),
+				viper.GetString(flagDescription),
+				viper.GetStringSlice(flagTags),
+				viper.GetString(flagLocation),
+				viper.GetString(flagFirmwareVersion),
+			)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagName, "", "name of the datanode, defaults to its address")
+	cmd.Flags().String(flagDescription, "", "description of the datanode")
+	cmd.Flags().StringSlice(flagTags, nil, "comma separated tags of the datanode")
+	cmd.Flags().String(flagLocation, "", "location of the datanode")
+	cmd.Flags().String(flagFirmwareVersion, "", "firmware version of the datanode")
+	return cmd
+}
+
+// GetCmdDeleteDataNode is the CLI command for sending a MsgDeleteDataNode transaction
+func GetCmdDeleteDataNode(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete-datanode [owner] [datanode]",
+		Short: "delete datanode with all its records, or archive it keeping the records",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgDeleteDataNode(owner, datanode, viper.GetBool(flagArchive))
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Bool(flagArchive, false, "keep the datanode records queryable and only reject new ones")
+	return cmd
+}
+
+// GetCmdSetBackfill is the CLI command for sending a MsgSetBackfill transaction
+func GetCmdSetBackfill(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-backfill [owner] [datanode] [enabled]",
+		Short: "enable or disable the backfill mode of the datanode, to add records older than the max backfill age",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			enabled, err := strconv.ParseBool(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSetBackfill(owner, datanode, enabled)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdSetFeeAllowance is the CLI command for sending a MsgSetFeeAllowance transaction
+func GetCmdSetFeeAllowance(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-fee-allowance [owner] [datanode]",
+		Short: "cap the fees charged for the txs signed by datanode",
+		Long: strings.TrimSpace(`
+Cap the fees charged to the fee payer for the txs signed by the datanode: overall, on a period of
+the given seconds and by tx. Txs with a fee over any cap are rejected. The new limits replace the
+previous ones keeping the fees already charged, without limits the allowance is removed.`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			totalLimit, err := sdk.ParseCoins(viper.GetString(flagTotalLimit))
+			if err != nil {
+				return err
+			}
+			periodLimit, err := sdk.ParseCoins(viper.GetString(flagPeriodLimit))
+			if err != nil {
+				return err
+			}
+			txLimit, err := sdk.ParseCoins(viper.GetString(flagTxLimit))
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSetFeeAllowance(owner, datanode, totalLimit, periodLimit, viper.GetInt64(flagPeriod), txLimit)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagTotalLimit, "", "maximum fees charged overall")
+	cmd.Flags().String(flagPeriodLimit, "", "maximum fees charged on a period")
+	cmd.Flags().Int64(flagPeriod, 0, "seconds of the period")
+	cmd.Flags().String(flagTxLimit, "", "maximum fee of a single tx")
+	return cmd
+}
+
+// GetCmdSetFeePayer is the CLI command for sending a MsgSetFeePayer transaction
+func GetCmdSetFeePayer(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-fee-payer [owner] [datanode] [fee-payer]",
+		Short: "set the account paying the fees of the datanode, removed when fee-payer is omitted",
+		Long: strings.TrimSpace(`
+Set the account paying the fees of the datanode instead of the fee payer of its fleet or its owner,
+like a company treasury. The tx must be signed by both the owner and the fee payer. When the fee payer
+lacks the funds for the records written by the device, its owner pays and then the device itself.
+Omit the fee payer to remove it.`),
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			var feePayer sdk.AccAddress
+			if len(args) > 2 {
+				feePayer, err = sdk.AccAddressFromBech32(args[2])
+				if err != nil {
+					return err
+				}
+			}
+
+			msg := types.NewMsgSetFeePayer(owner, datanode, feePayer)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdBondBandwidth is the CLI command for sending a MsgBondBandwidth transaction
+func GetCmdBondBandwidth(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "bond-bandwidth [owner] [amount]",
+		Short: "bond coins granting the datanodes of owner a quota of records written without fees",
+		Long: strings.TrimSpace(`
+Bond coins granting the datanodes of the owner a quota of records written without fees on every
+bandwidth period, a record for each bandwidth bond per record of the module parameters. The txs of
+the datanodes adding records with no fees use the quota while it lasts.`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgBondBandwidth(owner, amount)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdUnbondBandwidth is the CLI command for sending a MsgUnbondBandwidth transaction
+func GetCmdUnbondBandwidth(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unbond-bandwidth [owner] [amount]",
+		Short: "unbond coins bonded for bandwidth, returned to owner after the unbonding time",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgUnbondBandwidth(owner, amount)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdUpdateChannels is the CLI command for sending a BuyName transaction
+func GetCmdUpdateChannels(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-channels [owner] [datanode] [channels]",
+		Short: "update channels of datanode",
+		Long: strings.TrimSpace(`
+Update the channels of a datanode, channels is a json list of {action, id, variable} where action is
+set or delete. Set channels can declare the UCUM unit of the values and the encoding of the record
+values: uint32, int32, int64 (sent as decimal text on misc), decimal with scale digits, float32, bool,
+enum with its labels, or misc. Numeric channels can bound their values with min and max, records out
+of the schema are rejected. The records are pruned retention seconds after their time frame ends, and
+rollup keeps a summary of each time frame pruned.
+
+Example: [{"action":"set","id":"1","variable":"temperature","unit":"Cel","encoding":"decimal","scale":1,"min":"-40","max":"85"}]`),
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			var channels ([]types.ChannelUpdate)
+			cdc.MustUnmarshalJSON([]byte(args[2]), &channels)
+
+			msg := types.NewMsgUpdateChannels(owner, datanode, channels)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdAddRecords is the CLI command for sending a BuyName transaction
+func GetCmdAddRecords(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-records [datanode] [records]",
+		Short: "add records to data record time frame",
+		Long: strings.TrimSpace(`
+Add records to the channels of a datanode, records is a json list of records v2 with a timestamp
+in milliseconds since epoch and a 64-bit value read with the channel encoding:
+
+[{"channel":"temp","time":1600000000123,"int_value":-1250,"misc":""}]
+
+Legacy records with a timestamp in seconds and a 32-bit value are accepted while the
+legacy_records param is enabled, both kinds can't be mixed on the same message:
+
+[{"channel":"temp","timestamp":1600000000,"value":25,"misc":""}]
+
+Invalid records are rejected one by one and listed with the reason on the data of the tx
+result, along with the accepted and the duplicated ones. With the strict flag any invalid
+record fails the whole tx.`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			datanode, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var records ([]types.NewRecord)
+			cdc.MustUnmarshalJSON([]byte(args[1]), &records)
+
+			msg := types.NewMsgAddRecords(datanode, records, viper.GetBool(flagStrict))
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Bool(flagStrict, false, "fail the tx if any record is invalid")
+	return cmd
+}
+
+// GetCmdPruneRecords is the CLI command for sending a MsgPruneRecords transaction
+func GetCmdPruneRecords(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune-records [owner] [datanode] [channel] [before]",
+		Short: "delete the records of the datanode channel before the timestamp in milliseconds, refunding their storage deposit",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			before, err := strconv.ParseInt(args[3], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgPruneRecords(owner, datanode, args[2], before)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdGrantRole is the CLI command for sending a MsgGrantRole transaction
+func GetCmdGrantRole(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant-role [granter] [datanode] [address] [role]",
+		Short: "grant a role (admin, operator, writer, viewer) on datanode to address",
+		Long: strings.TrimSpace(`
+Grant a role on the datanode to an account, replacing its previous role. Admins can transfer and
+delete the datanode, operators edit its channels and metadata and writers add records. The owner
+pays the fees of the records added by writers. Only the owner can grant the admin role.`),
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			granter, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			address, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			var expiry time.Time
+			if e := viper.GetString(flagExpiry); e != "" {
+				expiry, err = time.Parse(time.RFC3339, e)
+				if err != nil {
+					return err
+				}
+			}
+
+			msg := types.NewMsgGrantRole(granter, datanode, address, types.Role(args[3]), viper.GetStringSlice(flagChannels), expiry)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringSlice(flagChannels, nil, "comma separated channels a writer can write, all of them when empty")
+	cmd.Flags().String(flagExpiry, "", "RFC3339 time the role expires at, it doesn't expire when empty")
+	return cmd
+}
+
+// GetCmdRevokeRole is the CLI command for sending a MsgRevokeRole transaction
+func GetCmdRevokeRole(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke-role [granter] [datanode] [address]",
+		Short: "revoke the role of address on datanode",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			granter, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			address, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgRevokeRole(granter, datanode, address)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdGatewayAddRecords is the CLI command for sending a MsgGatewayAddRecords transaction
+func GetCmdGatewayAddRecords(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gateway-add-records [gateway] [datanodes]",
+		Short: "add records of several datanodes, datanodes is a json list of {datanode, records}",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			gateway, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var dataNodes ([]types.DataNodeRecords)
+			cdc.MustUnmarshalJSON([]byte(args[1]), &dataNodes)
+
+			msg := types.NewMsgGatewayAddRecords(gateway, dataNodes, viper.GetBool(flagStrict))
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Bool(flagStrict, false, "fail the tx if any record is invalid")
+	return cmd
+}
+
+// GetCmdCreateFleet is the CLI command for sending a MsgCreateFleet transaction
+func GetCmdCreateFleet(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create-fleet [admin] [fleet]",
+		Short: "create a new fleet with admin as its admin",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			admin, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgCreateFleet(admin, args[1])
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdSetFleetAdmins is the CLI command for sending a MsgSetFleetAdmins transaction
+func GetCmdSetFleetAdmins(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-fleet-admins [admin] [fleet] [admins]",
+		Short: "replace the admins of fleet, admins is a comma separated list of addresses",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			admin, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var admins []sdk.AccAddress
+			for _, a := range strings.Split(args[2], ",") {
+				address, err := sdk.AccAddressFromBech32(strings.TrimSpace(a))
+				if err != nil {
+					return err
+				}
+				admins = append(admins, address)
+			}
+
+			msg := types.NewMsgSetFleetAdmins(admin, args[1], admins)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdSetFleetFeePayer is the CLI command for sending a MsgSetFleetFeePayer transaction
+func GetCmdSetFleetFeePayer(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-fleet-fee-payer [admin] [fleet] [fee-payer]",
+		Short: "set the account paying the fees of the fleet members, the owners pay when fee-payer is omitted",
+		Long: strings.TrimSpace(`
+Set the account paying the fees of the datanodes of the fleet instead of their owners. The tx must
+be signed by both the fleet admin and the fee payer. Omit the fee payer to let the owners pay again.`),
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			admin, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var feePayer sdk.AccAddress
+			if len(args) > 2 {
+				feePayer, err = sdk.AccAddressFromBech32(args[2])
+				if err != nil {
+					return err
+				}
+			}
+
+			msg := types.NewMsgSetFleetFeePayer(admin, args[1], feePayer)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdAddFleetMember is the CLI command for sending a MsgAddFleetMember transaction
+func GetCmdAddFleetMember(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-fleet-member [admin] [fleet] [datanode] [owner]",
+		Short: "add datanode to fleet",
+		Long: strings.TrimSpace(`
+Add a datanode to a fleet and set the fleet channel template on it. The tx must be signed by both
+the fleet admin and the datanode owner.`),
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			admin, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			owner, err := sdk.AccAddressFromBech32(args[3])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgAddFleetMember(admin, args[1], datanode, owner)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdRemoveFleetMember is the CLI command for sending a MsgRemoveFleetMember transaction
+func GetCmdRemoveFleetMember(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-fleet-member [sender] [fleet] [datanode]",
+		Short: "remove datanode from fleet, sender is a fleet admin or the datanode owner",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			sender, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgRemoveFleetMember(sender, args[1], datanode)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdSetFleetTemplate is the CLI command for sending a MsgSetFleetTemplate transaction
+func GetCmdSetFleetTemplate(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-fleet-template [admin] [fleet] [channels]",
+		Short: "set the channel template of fleet on all its members, channels is a json list of {id, variable}",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			admin, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var channels ([]types.NodeChannel)
+			cdc.MustUnmarshalJSON([]byte(args[2]), &channels)
+
+			msg := types.NewMsgSetFleetTemplate(admin, args[1], channels)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdPublishDeviceType is the CLI command for sending a MsgPublishDeviceType transaction
+func GetCmdPublishDeviceType(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish-device-type [owner] [device-type] [channels]",
+		Short: "publish a new version of device type, channels is a json list of {id, variable}",
+		Long: strings.TrimSpace(`
+Publish a new version of a device type. The first publisher of a device type owns it, later versions
+can only be published by the owner, who can transfer it. Linked datanodes keep their version until
+they are upgraded.`),
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var channels ([]types.NodeChannel)
+			cdc.MustUnmarshalJSON([]byte(args[2]), &channels)
+
+			msg := types.NewMsgPublishDeviceType(owner, args[1], viper.GetString(flagDescription), channels)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagDescription, "", "description of the device type")
+	return cmd
+}
+
+// GetCmdTransferDeviceType is the CLI command for sending a MsgTransferDeviceType transaction
+func GetCmdTransferDeviceType(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "transfer-device-type [owner] [device-type] [new-owner]",
+		Short: "transfer device type to a new owner, who publishes its next versions",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			newOwner, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgTransferDeviceType(owner, args[1], newOwner)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdLinkDeviceType is the CLI command for sending a MsgLinkDeviceType transaction
+func GetCmdLinkDeviceType(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link-device-type [owner] [datanode] [device-type]",
+		Short: "link datanode to a version of device type, defaults to the latest one",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgLinkDeviceType(owner, datanode, args[2], uint32(viper.GetUint(flagVersion)))
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Uint32(flagVersion, 0, "version of the device type, 0 for the latest")
+	return cmd
+}
+
+// GetCmdUnlinkDeviceType is the CLI command for sending a MsgLinkDeviceType transaction without device type
+func GetCmdUnlinkDeviceType(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unlink-device-type [owner] [datanode]",
+		Short: "unlink datanode from its device type, the channels are kept",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			datanode, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgLinkDeviceType(owner, datanode, "", 0)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdUpgradeDataNodes is the CLI command for sending a MsgUpgradeDataNodes transaction
+func GetCmdUpgradeDataNodes(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade-datanodes [owner] [device-type] [datanodes]",
+		Short: "upgrade comma separated datanodes to a newer version of their device type, defaults to the latest one",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			owner, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var datanodes []sdk.AccAddress
+			for _, a := range strings.Split(args[2], ",") {
+				datanode, err := sdk.AccAddressFromBech32(strings.TrimSpace(a))
+				if err != nil {
+					return err
+				}
+				datanodes = append(datanodes, datanode)
+			}
 
-			msg := types.NewMsgAddRecords(datanode, records)
+			msg := types.NewMsgUpgradeDataNodes(owner, args[1], uint32(viper.GetUint(flagVersion)), datanodes)
 			err = msg.ValidateBasic()
 			if err != nil {
 				return err
@@ -144,4 +1161,6 @@ func GetCmdAddRecords(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().Uint32(flagVersion, 0, "version of the device type, 0 for the latest")
+	return cmd
 }
@@ -3,18 +3,49 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/types/rest"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
 )
 
 func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router) {
+	r.HandleFunc("/datanode/params", queryParamsHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/fleets/{fleet}/members", queryFleetMembersHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/fleets/{fleet}", queryFleetHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/device-types", queryDeviceTypesHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/device-types/{devicetype}/nodes", queryDeviceTypeNodesHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/device-types/{devicetype}", queryDeviceTypeHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/records/{channelid}/{from}/{to}", queryRecordsRangeHandler(cliCtx)).Methods("GET")
 	r.HandleFunc("/datanode/{address}/records/{channelid}/{date}", queryRecordsHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/roles", queryRolesHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/ownership-offer", queryOwnershipOfferHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/fee-allowance", queryFeeAllowanceHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/storage-deposit", queryStorageDepositHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/{address}/rollups/{channel}", queryRollupsHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/bandwidth/{owner}/quota", queryBandwidthQuotaHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/bandwidth/{owner}/unbonding", queryBandwidthUnbondingHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/datanodes", queryDataNodesHandler(cliCtx)).Methods("GET")
+	r.HandleFunc("/datanode/owner/{owner}", queryDataNodesByOwnerHandler(cliCtx)).Methods("GET")
 	r.HandleFunc("/datanode/{address}", queryDataNodeHandler(cliCtx)).Methods("GET")
 }
 
+func queryParamsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s", types.QueryParams), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
 func queryDataNodeHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -30,14 +61,312 @@ func queryDataNodeHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	}
 }
 
+func queryOwnershipOfferHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryOwnershipOffer, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryFeeAllowanceHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryFeeAllowance, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryBandwidthQuotaHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		owner := vars["owner"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryBandwidthQuota, owner), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryBandwidthUnbondingHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		owner := vars["owner"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryBandwidthUnbonding, owner), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryStorageDepositHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryStorageDeposit, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryRollupsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+		channel := vars["channel"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s/%s", types.QueryRollups, address, channel), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryRolesHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryRoles, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryDataNodesHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := pagePath(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryDataNodes, page), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryDataNodesByOwnerHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		owner := vars["owner"]
+
+		page, err := pagePath(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s/%s", types.QueryOwnerNodes, owner, page), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryFleetHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fleet := vars["fleet"]
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryFleet, fleet), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryFleetMembersHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fleet := vars["fleet"]
+
+		page, err := pagePath(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s/%s", types.QueryFleetMembers, fleet, page), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryDeviceTypeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		path := fmt.Sprintf("custom/datanode/%s/%s", types.QueryDeviceType, vars["devicetype"])
+		if version := r.URL.Query().Get("version"); version != "" {
+			path += "/" + version
+		}
+
+		res, _, err := cliCtx.QueryWithData(path, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryDeviceTypesHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := pagePath(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s", types.QueryDeviceTypes, page), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryDeviceTypeNodesHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		deviceType := vars["devicetype"]
+
+		page, err := pagePath(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/%s/%s/%s", types.QueryDeviceTypeNodes, deviceType, page), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+// pagePath returns the <limit>[/<start>] query path from the limit and start url parameters
+func pagePath(r *http.Request) (string, error) {
+	limit := types.DefaultDataNodesLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path := strconv.Itoa(limit)
+	if start := r.URL.Query().Get("start"); start != "" {
+		path += "/" + start
+	}
+	return path, nil
+}
+
 func queryRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		address := vars["address"]
 		channelID := vars["channelid"]
 		date := vars["date"]
+		unit := r.URL.Query().Get("unit")
+		if unit == "" {
+			unit = string(types.TimeUnitSecond)
+		}
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/records/%s/%s/%s/%s", address, channelID, date, unit), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryRecordsRangeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars["address"]
+		channelID := vars["channelid"]
+		from := vars["from"]
+		to := vars["to"]
+
+		limit := types.DefaultRecordsRangeLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		unit := r.URL.Query().Get("unit")
+		if unit == "" {
+			unit = string(types.TimeUnitSecond)
+		}
 
-		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/records/%s/%s/%s", address, channelID, date), nil)
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/datanode/records-range/%s/%s/%s/%s/%d/%s", address, channelID, from, to, limit, unit), nil)
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -14,17 +15,42 @@ import (
 )
 
 func registerTxRoutes(cliCtx context.CLIContext, r *mux.Router) {
+	r.HandleFunc("/datanode/ownership/offer", offerOwnershipHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/ownership/accept", acceptOwnershipHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/ownership/cancel", cancelOwnershipOfferHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/metadata", updateDataNodeHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/delete", deleteDataNodeHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/backfill", setBackfillHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fee-allowance", setFeeAllowanceHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fee-payer", setFeePayerHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/bandwidth/bond", bondBandwidthHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/bandwidth/unbond", unbondBandwidthHandler(cliCtx)).Methods("POST")
 	r.HandleFunc("/datanode/channels", updateChannelsHandler(cliCtx)).Methods("POST")
 	r.HandleFunc("/datanode/records", addRecordsHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/records/prune", pruneRecordsHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/roles/grant", grantRoleHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/roles/revoke", revokeRoleHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/gateway/records", gatewayAddRecordsHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets", createFleetHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets/admins", setFleetAdminsHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets/fee-payer", setFleetFeePayerHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets/members/add", addFleetMemberHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets/members/remove", removeFleetMemberHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/fleets/template", setFleetTemplateHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/device-types", publishDeviceTypeHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/device-types/transfer", transferDeviceTypeHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/device-types/link", linkDeviceTypeHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/datanode/device-types/upgrade", upgradeDataNodesHandler(cliCtx)).Methods("POST")
 	r.HandleFunc("/datanode", setOwnerHandler(cliCtx)).Methods("POST")
 }
 
 type setOwnerReq struct {
-	BaseReq  rest.BaseReq `json:"base_req"`
-	DataNode string       `json:"datanode"`
-	Owner    string       `json:"owner"`
-	NewOwner string       `json:"newowner"`
-	Name     string       `json:"name"`
+	BaseReq    rest.BaseReq `json:"base_req"`
+	DataNode   string       `json:"datanode"`
+	Owner      string       `json:"owner"`
+	NewOwner   string       `json:"newowner"`
+	Name       string       `json:"name"`
+	DeviceType string       `json:"device_type"`
 }
 
 func setOwnerHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -59,7 +85,7 @@ func setOwnerHandler(cliCtx context.CLIContext) http.HandlerFunc {
 		}
 
 		// create the message
-		msg := types.NewMsgSetOwner(dataNode, owner, newOwner, req.Name)
+		msg := types.NewMsgSetOwner(dataNode, owner, newOwner, req.Name, req.DeviceType)
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -70,16 +96,16 @@ func setOwnerHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	}
 }
 
-type updateChannelsReq struct {
-	BaseReq  rest.BaseReq          `json:"base_req"`
-	Owner    string                `json:"owner"`
-	DataNode string                `json:"datanode"`
-	Updates  []types.ChannelUpdate `json:"channels"`
+type offerOwnershipReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+	NewOwner string       `json:"newowner"`
 }
 
-func updateChannelsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+func offerOwnershipHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req updateChannelsReq
+		var req offerOwnershipReq
 		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
 			return
@@ -102,8 +128,14 @@ func updateChannelsHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		newOwner, err := sdk.AccAddressFromBech32(req.NewOwner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// create the message
-		msg := types.NewMsgUpdateChannels(owner, dataNode, req.Updates)
+		msg := types.NewMsgOfferOwnership(owner, dataNode, newOwner)
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -114,15 +146,241 @@ func updateChannelsHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	}
 }
 
-type addRecordsReq struct {
-	BaseReq  rest.BaseReq      `json:"base_req"`
-	DataNode string            `json:"datanode"`
-	Records  []types.NewRecord `json:"records"`
+type acceptOwnershipReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	NewOwner string       `json:"newowner"`
+	DataNode string       `json:"datanode"`
 }
 
-func addRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+func acceptOwnershipHandler(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req addRecordsReq
+		var req acceptOwnershipReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		newOwner, err := sdk.AccAddressFromBech32(req.NewOwner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgAcceptOwnership(newOwner, dataNode)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type cancelOwnershipOfferReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+}
+
+func cancelOwnershipOfferHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req cancelOwnershipOfferReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgCancelOwnershipOffer(owner, dataNode)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type updateDataNodeReq struct {
+	BaseReq         rest.BaseReq `json:"base_req"`
+	Owner           string       `json:"owner"`
+	DataNode        string       `json:"datanode"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Tags            []string     `json:"tags"`
+	Location        string       `json:"location"`
+	FirmwareVersion string       `json:"firmware_version"`
+}
+
+func updateDataNodeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req updateDataNodeReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgUpdateDataNode(owner, dataNode, req.Name, req.Description, req.Tags, req.Location, req.FirmwareVersion)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type deleteDataNodeReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+	Archive  bool         `json:"archive"`
+}
+
+func deleteDataNodeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req deleteDataNodeReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgDeleteDataNode(owner, dataNode, req.Archive)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setBackfillReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+	Enabled  bool         `json:"enabled"`
+}
+
+func setBackfillHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setBackfillReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgSetBackfill(owner, dataNode, req.Enabled)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setFeeAllowanceReq struct {
+	BaseReq     rest.BaseReq `json:"base_req"`
+	Owner       string       `json:"owner"`
+	DataNode    string       `json:"datanode"`
+	TotalLimit  sdk.Coins    `json:"total_limit"`
+	PeriodLimit sdk.Coins    `json:"period_limit"`
+	Period      int64        `json:"period"`
+	TxLimit     sdk.Coins    `json:"tx_limit"`
+}
+
+func setFeeAllowanceHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setFeeAllowanceReq
 		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
 			return
@@ -133,6 +391,12 @@ func addRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -140,7 +404,839 @@ func addRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
 		}
 
 		// create the message
-		msg := types.NewMsgAddRecords(dataNode, req.Records)
+		msg := types.NewMsgSetFeeAllowance(owner, dataNode, req.TotalLimit, req.PeriodLimit, req.Period, req.TxLimit)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setFeePayerReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+	FeePayer string       `json:"fee_payer"`
+}
+
+func setFeePayerHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setFeePayerReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var feePayer sdk.AccAddress
+		if req.FeePayer != "" {
+			feePayer, err = sdk.AccAddressFromBech32(req.FeePayer)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// create the message
+		msg := types.NewMsgSetFeePayer(owner, dataNode, feePayer)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type bandwidthBondReq struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Owner   string       `json:"owner"`
+	Amount  sdk.Coins    `json:"amount"`
+}
+
+func bondBandwidthHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bandwidthBondReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgBondBandwidth(owner, req.Amount)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+func unbondBandwidthHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bandwidthBondReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgUnbondBandwidth(owner, req.Amount)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type updateChannelsReq struct {
+	BaseReq  rest.BaseReq          `json:"base_req"`
+	Owner    string                `json:"owner"`
+	DataNode string                `json:"datanode"`
+	Updates  []types.ChannelUpdate `json:"channels"`
+}
+
+func updateChannelsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req updateChannelsReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgUpdateChannels(owner, dataNode, req.Updates)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type addRecordsReq struct {
+	BaseReq  rest.BaseReq      `json:"base_req"`
+	DataNode string            `json:"datanode"`
+	Records  []types.NewRecord `json:"records"`
+	Strict   bool              `json:"strict"`
+}
+
+func addRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req addRecordsReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgAddRecords(dataNode, req.Records, req.Strict)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type pruneRecordsReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Owner    string       `json:"owner"`
+	DataNode string       `json:"datanode"`
+	Channel  string       `json:"channel"`
+	Before   int64        `json:"before"`
+}
+
+func pruneRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req pruneRecordsReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgPruneRecords(owner, dataNode, req.Channel, req.Before)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type grantRoleReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Granter  string       `json:"granter"`
+	DataNode string       `json:"datanode"`
+	Address  string       `json:"address"`
+	Role     types.Role   `json:"role"`
+	Channels []string     `json:"channels"`
+	Expiry   time.Time    `json:"expiry"`
+}
+
+func grantRoleHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req grantRoleReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		granter, err := sdk.AccAddressFromBech32(req.Granter)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		address, err := sdk.AccAddressFromBech32(req.Address)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgGrantRole(granter, dataNode, address, req.Role, req.Channels, req.Expiry)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type revokeRoleReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Granter  string       `json:"granter"`
+	DataNode string       `json:"datanode"`
+	Address  string       `json:"address"`
+}
+
+func revokeRoleHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req revokeRoleReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		granter, err := sdk.AccAddressFromBech32(req.Granter)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		address, err := sdk.AccAddressFromBech32(req.Address)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgRevokeRole(granter, dataNode, address)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type gatewayAddRecordsReq struct {
+	BaseReq   rest.BaseReq            `json:"base_req"`
+	Gateway   string                  `json:"gateway"`
+	DataNodes []types.DataNodeRecords `json:"datanodes"`
+	Strict    bool                    `json:"strict"`
+}
+
+func gatewayAddRecordsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req gatewayAddRecordsReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		gateway, err := sdk.AccAddressFromBech32(req.Gateway)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgGatewayAddRecords(gateway, req.DataNodes, req.Strict)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type createFleetReq struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Admin   string       `json:"admin"`
+	Fleet   string       `json:"fleet"`
+}
+
+func createFleetHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req createFleetReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		admin, err := sdk.AccAddressFromBech32(req.Admin)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgCreateFleet(admin, req.Fleet)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setFleetAdminsReq struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Admin   string       `json:"admin"`
+	Fleet   string       `json:"fleet"`
+	Admins  []string     `json:"admins"`
+}
+
+func setFleetAdminsHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setFleetAdminsReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		admin, err := sdk.AccAddressFromBech32(req.Admin)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var admins []sdk.AccAddress
+		for _, a := range req.Admins {
+			address, err := sdk.AccAddressFromBech32(a)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			admins = append(admins, address)
+		}
+
+		// create the message
+		msg := types.NewMsgSetFleetAdmins(admin, req.Fleet, admins)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setFleetFeePayerReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Admin    string       `json:"admin"`
+	Fleet    string       `json:"fleet"`
+	FeePayer string       `json:"fee_payer"`
+}
+
+func setFleetFeePayerHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setFleetFeePayerReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		admin, err := sdk.AccAddressFromBech32(req.Admin)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// an empty fee payer lets the owners pay
+		var feePayer sdk.AccAddress
+		if req.FeePayer != "" {
+			feePayer, err = sdk.AccAddressFromBech32(req.FeePayer)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// create the message
+		msg := types.NewMsgSetFleetFeePayer(admin, req.Fleet, feePayer)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type addFleetMemberReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Admin    string       `json:"admin"`
+	Fleet    string       `json:"fleet"`
+	DataNode string       `json:"datanode"`
+	Owner    string       `json:"owner"`
+}
+
+func addFleetMemberHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req addFleetMemberReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		admin, err := sdk.AccAddressFromBech32(req.Admin)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgAddFleetMember(admin, req.Fleet, dataNode, owner)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type removeFleetMemberReq struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Sender   string       `json:"sender"`
+	Fleet    string       `json:"fleet"`
+	DataNode string       `json:"datanode"`
+}
+
+func removeFleetMemberHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req removeFleetMemberReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		sender, err := sdk.AccAddressFromBech32(req.Sender)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgRemoveFleetMember(sender, req.Fleet, dataNode)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type setFleetTemplateReq struct {
+	BaseReq  rest.BaseReq        `json:"base_req"`
+	Admin    string              `json:"admin"`
+	Fleet    string              `json:"fleet"`
+	Channels []types.NodeChannel `json:"channels"`
+}
+
+func setFleetTemplateHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req setFleetTemplateReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		admin, err := sdk.AccAddressFromBech32(req.Admin)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgSetFleetTemplate(admin, req.Fleet, req.Channels)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type publishDeviceTypeReq struct {
+	BaseReq     rest.BaseReq        `json:"base_req"`
+	Owner       string              `json:"owner"`
+	DeviceType  string              `json:"device_type"`
+	Description string              `json:"description"`
+	Channels    []types.NodeChannel `json:"channels"`
+}
+
+func publishDeviceTypeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req publishDeviceTypeReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgPublishDeviceType(owner, req.DeviceType, req.Description, req.Channels)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type transferDeviceTypeReq struct {
+	BaseReq    rest.BaseReq `json:"base_req"`
+	Owner      string       `json:"owner"`
+	DeviceType string       `json:"device_type"`
+	NewOwner   string       `json:"new_owner"`
+}
+
+func transferDeviceTypeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req transferDeviceTypeReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newOwner, err := sdk.AccAddressFromBech32(req.NewOwner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message
+		msg := types.NewMsgTransferDeviceType(owner, req.DeviceType, newOwner)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type linkDeviceTypeReq struct {
+	BaseReq    rest.BaseReq `json:"base_req"`
+	Owner      string       `json:"owner"`
+	DataNode   string       `json:"datanode"`
+	DeviceType string       `json:"device_type"`
+	Version    uint32       `json:"version"`
+}
+
+func linkDeviceTypeHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req linkDeviceTypeReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		dataNode, err := sdk.AccAddressFromBech32(req.DataNode)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// create the message, an empty device type unlinks the datanode
+		msg := types.NewMsgLinkDeviceType(owner, dataNode, req.DeviceType, req.Version)
+		err = msg.ValidateBasic()
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type upgradeDataNodesReq struct {
+	BaseReq    rest.BaseReq `json:"base_req"`
+	Owner      string       `json:"owner"`
+	DeviceType string       `json:"device_type"`
+	Version    uint32       `json:"version"`
+	DataNodes  []string     `json:"datanodes"`
+}
+
+func upgradeDataNodesHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req upgradeDataNodesReq
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.Owner)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var dataNodes []sdk.AccAddress
+		for _, a := range req.DataNodes {
+			dataNode, err := sdk.AccAddressFromBech32(a)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			dataNodes = append(dataNodes, dataNode)
+		}
+
+		// create the message
+		msg := types.NewMsgUpgradeDataNodes(owner, req.DeviceType, req.Version, dataNodes)
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
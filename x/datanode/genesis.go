@@ -1,19 +1,71 @@
 package datanode
 
 import (
+	"fmt"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/qonico/cosmos-iot/x/datanode/types"
 )
 
 // InitGenesis initialize default parameters
 // and the keeper's address to pubkey map
 func InitGenesis(ctx sdk.Context, k DataNodeKeeper, data GenesisState) {
+	k.SetParams(ctx, data.Params)
+
+	for _, fleet := range data.Fleets {
+		k.SetFleet(ctx, fleet)
+	}
+
+	for _, deviceType := range data.DeviceTypes {
+		k.SetDeviceType(ctx, deviceType)
+	}
+
+	// fleet members and device types are indexed as the datanodes are set
 	for _, dn := range data.DataNodes {
-		k.SetDataNode(ctx, dn.ID, &dn)
+		dataNode := dn
+		k.SetDataNode(ctx, dataNode.ID, &dataNode)
 	}
 
+	// records are indexed by time frame as they are set
 	for _, dr := range data.DataRecords {
-		k.SetDataRecord(ctx, &dr)
+		dataRecord := dr
+		k.SetDataRecord(ctx, &dataRecord)
+	}
+
+	for _, offer := range data.OwnershipOffers {
+		k.SetOwnershipOffer(ctx, offer)
+	}
+
+	for _, grant := range data.RoleGrants {
+		k.SetRoleGrant(ctx, grant)
+	}
+
+	for _, allowance := range data.FeeAllowances {
+		k.SetFeeAllowance(ctx, allowance)
+	}
+
+	// the coins bonded and unbonding are held by the module account
+	for _, bond := range data.BandwidthBonds {
+		k.SetBandwidthBond(ctx, bond)
+		for _, unbonding := range bond.Unbonding {
+			k.InsertBandwidthUnbondingQueue(ctx, bond.Owner, unbonding.CompletionTime)
+		}
+	}
+
+	// the bytes of the deposits are counted as the records are set, they must match the exported ones so
+	// the coins keep their ratio to the bytes. The coins are held by the storage deposit pool
+	for _, deposit := range data.StorageDeposits {
+		stored := k.GetStorageDeposit(ctx, deposit.DataNode)
+		if stored.Bytes != deposit.Bytes {
+			panic(fmt.Sprintf("storage deposit of %s has %d bytes, its records take %d", deposit.DataNode, deposit.Bytes, stored.Bytes))
+		}
+		stored.Amount = deposit.Amount
+		stored.Depositors = deposit.Depositors
+		k.SetStorageDeposit(ctx, stored)
+	}
+
+	// the retention queue is filled as the records are set
+	for _, rollup := range data.Rollups {
+		k.SetRollup(ctx, rollup)
 	}
 }
 
@@ -21,27 +73,62 @@ func InitGenesis(ctx sdk.Context, k DataNodeKeeper, data GenesisState) {
 // to a genesis file, which can be imported again
 // with InitGenesis
 func ExportGenesis(ctx sdk.Context, k DataNodeKeeper) (data GenesisState) {
-	var dataNodes []DataNode
-	var dataRecords []DataRecord
-
-	iterator := k.GetIterator(ctx)
-	for ; iterator.Valid(); iterator.Next() {
-		address, err := sdk.AccAddressFromBech32(string(iterator.Key()))
-		if err == nil {
-			// DataNode
-			dataNode, err := k.GetDataNode(ctx, address)
-			if err != nil {
-				dataNodes = append(dataNodes, *dataNode)
-			}
-		} else {
-			// DataRecord
-			var hash types.DataRecordHash
-			copy(hash[:], []byte(iterator.Key())[:16])
-			dataRecord, err := k.GetDataRecord(ctx, hash)
-			if err != nil {
-				dataRecords = append(dataRecords, *dataRecord)
-			}
-		}
-	}
-	return NewGenesisState()
+	dataNodes := []DataNode{}
+	dataRecords := []DataRecord{}
+	ownershipOffers := []OwnershipOffer{}
+	roleGrants := []RoleGrant{}
+	fleets := []Fleet{}
+	deviceTypes := []DeviceType{}
+	feeAllowances := []FeeAllowance{}
+	bandwidthBonds := []BandwidthBond{}
+	storageDeposits := []StorageDeposit{}
+
+	k.IterateDataNodes(ctx, func(dataNode DataNode) bool {
+		dataNodes = append(dataNodes, dataNode)
+		return false
+	})
+
+	// records of deleted channels are exported too, they are still stored, counted on the storage deposits
+	// and prunable, their channel has only the id
+	k.IterateDataRecords(ctx, func(dataRecord DataRecord) bool {
+		dataRecords = append(dataRecords, dataRecord)
+		return false
+	})
+
+	k.IterateOwnershipOffers(ctx, func(offer OwnershipOffer) bool {
+		ownershipOffers = append(ownershipOffers, offer)
+		return false
+	})
+
+	k.IterateRoleGrants(ctx, nil, func(grant RoleGrant) bool {
+		roleGrants = append(roleGrants, grant)
+		return false
+	})
+
+	k.IterateFleets(ctx, func(fleet Fleet) bool {
+		fleets = append(fleets, fleet)
+		return false
+	})
+
+	k.IterateDeviceTypes(ctx, func(deviceType DeviceType) bool {
+		deviceTypes = append(deviceTypes, deviceType)
+		return false
+	})
+
+	k.IterateFeeAllowances(ctx, func(allowance FeeAllowance) bool {
+		feeAllowances = append(feeAllowances, allowance)
+		return false
+	})
+
+	k.IterateBandwidthBonds(ctx, func(bond BandwidthBond) bool {
+		bandwidthBonds = append(bandwidthBonds, bond)
+		return false
+	})
+
+	k.IterateStorageDeposits(ctx, func(deposit StorageDeposit) bool {
+		storageDeposits = append(storageDeposits, deposit)
+		return false
+	})
+
+	return NewGenesisState(k.GetParams(ctx), dataNodes, dataRecords, ownershipOffers, roleGrants, fleets, deviceTypes, feeAllowances, bandwidthBonds, storageDeposits, k.GetAllRollups(ctx))
 }
@@ -2,6 +2,9 @@ package datanode
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/qonico/cosmos-iot/x/datanode/types"
 
@@ -16,10 +19,58 @@ func NewHandler(k DataNodeKeeper) sdk.Handler {
 		switch msg := msg.(type) {
 		case types.MsgSetOwner:
 			return handleMsgSetOwner(ctx, k, msg)
+		case types.MsgOfferOwnership:
+			return handleMsgOfferOwnership(ctx, k, msg)
+		case types.MsgAcceptOwnership:
+			return handleMsgAcceptOwnership(ctx, k, msg)
+		case types.MsgCancelOwnershipOffer:
+			return handleMsgCancelOwnershipOffer(ctx, k, msg)
+		case types.MsgUpdateDataNode:
+			return handleMsgUpdateDataNode(ctx, k, msg)
+		case types.MsgDeleteDataNode:
+			return handleMsgDeleteDataNode(ctx, k, msg)
+		case types.MsgSetBackfill:
+			return handleMsgSetBackfill(ctx, k, msg)
+		case types.MsgSetFeeAllowance:
+			return handleMsgSetFeeAllowance(ctx, k, msg)
+		case types.MsgSetFeePayer:
+			return handleMsgSetFeePayer(ctx, k, msg)
+		case types.MsgBondBandwidth:
+			return handleMsgBondBandwidth(ctx, k, msg)
+		case types.MsgUnbondBandwidth:
+			return handleMsgUnbondBandwidth(ctx, k, msg)
 		case types.MsgUpdateChannels:
 			return handleMsgUpdateChannels(ctx, k, msg)
 		case types.MsgAddRecords:
 			return handleMsgAddRecords(ctx, k, msg)
+		case types.MsgPruneRecords:
+			return handleMsgPruneRecords(ctx, k, msg)
+		case types.MsgGrantRole:
+			return handleMsgGrantRole(ctx, k, msg)
+		case types.MsgRevokeRole:
+			return handleMsgRevokeRole(ctx, k, msg)
+		case types.MsgGatewayAddRecords:
+			return handleMsgGatewayAddRecords(ctx, k, msg)
+		case types.MsgCreateFleet:
+			return handleMsgCreateFleet(ctx, k, msg)
+		case types.MsgSetFleetAdmins:
+			return handleMsgSetFleetAdmins(ctx, k, msg)
+		case types.MsgSetFleetFeePayer:
+			return handleMsgSetFleetFeePayer(ctx, k, msg)
+		case types.MsgAddFleetMember:
+			return handleMsgAddFleetMember(ctx, k, msg)
+		case types.MsgRemoveFleetMember:
+			return handleMsgRemoveFleetMember(ctx, k, msg)
+		case types.MsgSetFleetTemplate:
+			return handleMsgSetFleetTemplate(ctx, k, msg)
+		case types.MsgPublishDeviceType:
+			return handleMsgPublishDeviceType(ctx, k, msg)
+		case types.MsgTransferDeviceType:
+			return handleMsgTransferDeviceType(ctx, k, msg)
+		case types.MsgLinkDeviceType:
+			return handleMsgLinkDeviceType(ctx, k, msg)
+		case types.MsgUpgradeDataNodes:
+			return handleMsgUpgradeDataNodes(ctx, k, msg)
 		default:
 			errMsg := fmt.Sprintf("unrecognized %s message type: %T", ModuleName, msg)
 			return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, errMsg)
@@ -35,12 +86,349 @@ func handleMsgSetOwner(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetOwner)
 		if !msg.Owner.Equals(msg.DataNode) {
 			return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - owner must be the same as datanode for datanode creation")
 		}
-	} else if !dataNode.Owner.Equals(msg.Owner) {
-		// only owner can reassign owner
-		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - existing datanode and owner don't match")
+	} else if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionTransfer); err != nil {
+		// only owner and admins can reassign owner
+		return nil, err
+	} else if msg.DeviceType != "" {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "the device type is only set on creation, use MsgLinkDeviceType")
 	}
 
+	var deviceType *types.DeviceType
+	if msg.DeviceType != "" {
+		deviceType, err = k.GetLatestDeviceType(ctx, msg.DeviceType)
+		if err != nil {
+			return nil, sdkerrors.Wrap(err, msg.DeviceType)
+		}
+	}
+
+	k.SetDataNodeOwner(ctx, msg.DataNode, msg.NewOwner)
+
+	if dataNode == nil {
+		// the name is only taken on creation, renames go through MsgUpdateDataNode
+		if err := k.SetDataNodeName(ctx, msg.DataNode, msg.Name); err != nil {
+			return nil, err
+		}
+		if deviceType != nil {
+			if err := k.LinkDeviceType(ctx, msg.DataNode, deviceType); err != nil {
+				return nil, err
+			}
+			if err := checkMaxChannels(ctx, k, msg.DataNode); err != nil {
+				return nil, err
+			}
+		}
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeDataNodeCreated,
+				sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyOwner, msg.NewOwner.String()),
+			),
+		)
+	} else {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeOwnerChanged,
+				sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyPreviousOwner, dataNode.Owner.String()),
+				sdk.NewAttribute(types.AttributeKeyOwner, msg.NewOwner.String()),
+			),
+		)
+	}
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgOfferOwnership - handle a messsage to offer the datanode ownership to another account
+func handleMsgOfferOwnership(ctx sdk.Context, k DataNodeKeeper, msg types.MsgOfferOwnership) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionTransfer); err != nil {
+		return nil, err
+	}
+
+	offer := types.OwnershipOffer{
+		DataNode: msg.DataNode,
+		Owner:    dataNode.Owner,
+		NewOwner: msg.NewOwner,
+		Expiry:   ctx.BlockTime().Add(time.Duration(k.OwnershipOfferDuration(ctx)) * time.Second),
+	}
+	k.SetOwnershipOffer(ctx, offer)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeOwnershipOffered,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyOwner, offer.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyNewOwner, msg.NewOwner.String()),
+			sdk.NewAttribute(types.AttributeKeyExpiry, offer.Expiry.Format(time.RFC3339)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgAcceptOwnership - handle a messsage to accept a pending datanode ownership offer
+func handleMsgAcceptOwnership(ctx sdk.Context, k DataNodeKeeper, msg types.MsgAcceptOwnership) (*sdk.Result, error) {
+	offer, err := k.GetOwnershipOffer(ctx, msg.DataNode)
+	if err != nil {
+		return nil, err
+	}
+	if !offer.NewOwner.Equals(msg.NewOwner) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect NewOwner - the offer is for another account")
+	}
+	// expired offers are dropped at the end of the block, they can't be accepted meanwhile
+	if !ctx.BlockTime().Before(offer.Expiry) {
+		return nil, sdkerrors.Wrap(types.ErrNoOwnershipOffer, "the offer expired")
+	}
+
+	// the offer is dropped with the owner change
 	k.SetDataNodeOwner(ctx, msg.DataNode, msg.NewOwner)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeOwnerChanged,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyPreviousOwner, offer.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.NewOwner.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.NewOwner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgCancelOwnershipOffer - handle a messsage to cancel a pending datanode ownership offer
+func handleMsgCancelOwnershipOffer(ctx sdk.Context, k DataNodeKeeper, msg types.MsgCancelOwnershipOffer) (*sdk.Result, error) {
+	offer, err := k.GetOwnershipOffer(ctx, msg.DataNode)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionTransfer); err != nil {
+		return nil, err
+	}
+
+	k.DeleteOwnershipOffer(ctx, msg.DataNode)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeOwnershipOfferCancelled,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyOwner, offer.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyNewOwner, offer.NewOwner.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgUpdateDataNode - handle a messsage to update the datanode metadata
+func handleMsgUpdateDataNode(ctx sdk.Context, k DataNodeKeeper, msg types.MsgUpdateDataNode) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionEditMetadata); err != nil {
+		return nil, err
+	}
+
+	dataNode.Name = msg.Name
+	if dataNode.Name == "" {
+		dataNode.Name = msg.DataNode.String()
+	}
+	dataNode.Description = msg.Description
+	dataNode.Tags = msg.Tags
+	dataNode.Location = msg.Location
+	dataNode.FirmwareVersion = msg.FirmwareVersion
+	k.SetDataNode(ctx, msg.DataNode, dataNode)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDataNodeUpdated,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyName, dataNode.Name),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgDeleteDataNode - handle a messsage to delete or archive a datanode
+func handleMsgDeleteDataNode(ctx sdk.Context, k DataNodeKeeper, msg types.MsgDeleteDataNode) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionDelete); err != nil {
+		return nil, err
+	}
+
+	eventType := types.EventTypeDataNodeDeleted
+	var refund types.StorageRefund
+	if msg.Archive {
+		if dataNode.Archived {
+			return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, msg.DataNode.String())
+		}
+		if err := k.ArchiveDataNode(ctx, msg.DataNode); err != nil {
+			return nil, err
+		}
+		eventType = types.EventTypeDataNodeArchived
+	} else {
+		refund = k.DeleteDataNode(ctx, msg.DataNode)
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			eventType,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyOwner, dataNode.Owner.String()),
+		),
+	)
+	emitStorageRefunded(ctx, refund)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetBackfill - handle a messsage to enable or disable the backfill mode of a datanode
+func handleMsgSetBackfill(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetBackfill) (*sdk.Result, error) {
+	dataNode, err := getWritable(ctx, k, msg.DataNode)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionBackfill); err != nil {
+		return nil, err
+	}
+	if dataNode.Backfill == msg.Enabled {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "backfill mode already set to %t", msg.Enabled)
+	}
+
+	if err := k.SetDataNodeBackfill(ctx, msg.DataNode, msg.Enabled); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeBackfillSet,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyEnabled, strconv.FormatBool(msg.Enabled)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetFeeAllowance - handle a messsage to set or remove the fee allowance of a datanode
+func handleMsgSetFeeAllowance(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetFeeAllowance) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	// the allowance caps the spending of the owner, admins can't change it
+	if !dataNode.Owner.Equals(msg.Owner) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - only the owner can set the fee allowance")
+	}
+
+	previous, err := k.GetFeeAllowance(ctx, msg.DataNode)
+	if msg.IsRemoval() {
+		if err != nil {
+			return nil, err
+		}
+		k.DeleteFeeAllowance(ctx, msg.DataNode)
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeFeeAllowanceRemoved,
+				sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			),
+		)
+		emitMessageEvent(ctx, msg.Owner)
+		return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+	}
+
+	allowance := types.NewFeeAllowance(msg.DataNode, msg.TotalLimit, msg.PeriodLimit, msg.Period, msg.TxLimit)
+	if err == nil {
+		// the fees already charged count for the new limits, the current period goes on if its length is kept
+		allowance.TotalSpent = previous.TotalSpent
+		if previous.Period == msg.Period {
+			allowance.PeriodSpent = previous.PeriodSpent
+			allowance.PeriodEnd = previous.PeriodEnd
+		}
+	}
+	k.SetFeeAllowance(ctx, allowance)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFeeAllowanceSet,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetFeePayer - handle a messsage to set the account paying the fees of a datanode
+func handleMsgSetFeePayer(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetFeePayer) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	// the fee payer takes over the fees of the owner, admins can't change it
+	if !dataNode.Owner.Equals(msg.Owner) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - only the owner can set the fee payer")
+	}
+
+	if err := k.SetDataNodeFeePayer(ctx, msg.DataNode, msg.FeePayer); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFeePayerSet,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyFeePayer, msg.FeePayer.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgBondBandwidth - handle a messsage to bond coins for the bandwidth quota of the datanodes of an owner
+func handleMsgBondBandwidth(ctx sdk.Context, k DataNodeKeeper, msg types.MsgBondBandwidth) (*sdk.Result, error) {
+	// coins of other denoms wouldn't grant any quota
+	denom := k.BandwidthBondPerRecord(ctx).Denom
+	if len(msg.Amount) != 1 || msg.Amount[0].Denom != denom {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "only %s can be bonded for bandwidth, got %s", denom, msg.Amount)
+	}
+
+	if err := k.BondBandwidth(ctx, msg.Owner, msg.Amount); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeBandwidthBonded,
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyAmount, msg.Amount.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgUnbondBandwidth - handle a messsage to unbond coins bonded for bandwidth
+func handleMsgUnbondBandwidth(ctx sdk.Context, k DataNodeKeeper, msg types.MsgUnbondBandwidth) (*sdk.Result, error) {
+	completion, err := k.UnbondBandwidth(ctx, msg.Owner, msg.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeBandwidthUnbonding,
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyAmount, msg.Amount.String()),
+			sdk.NewAttribute(types.AttributeKeyCompletion, completion.Format(time.RFC3339)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
 
@@ -50,41 +438,833 @@ func handleMsgUpdateChannels(ctx sdk.Context, k DataNodeKeeper, msg types.MsgUpd
 	if err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
 	}
-	if !dataNode.Owner.Equals(msg.Owner) {
-		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - existing datanode and owner don't match")
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionEditChannels); err != nil {
+		return nil, err
+	}
+	if dataNode.Archived {
+		return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, msg.DataNode.String())
 	}
 
 	for _, ch := range msg.Updates {
 		switch ch.Action {
 		case "set":
-			channel := types.NodeChannel{
-				ID:       ch.ID,
-				Variable: ch.Variable,
-			}
-			k.ChangeChannel(ctx, msg.DataNode, channel)
+			k.ChangeChannel(ctx, msg.DataNode, ch.Channel())
+			ctx.EventManager().EmitEvent(
+				sdk.NewEvent(
+					types.EventTypeChannelSet,
+					sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+					sdk.NewAttribute(types.AttributeKeyChannel, ch.ID),
+					sdk.NewAttribute(types.AttributeKeyVariable, ch.Variable),
+				),
+			)
 			break
 		case "delete":
 			k.DeleteChannel(ctx, msg.DataNode, ch.ID)
+			ctx.EventManager().EmitEvent(
+				sdk.NewEvent(
+					types.EventTypeChannelDeleted,
+					sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+					sdk.NewAttribute(types.AttributeKeyChannel, ch.ID),
+				),
+			)
 			break
 		}
 	}
+
+	if err := checkMaxChannels(ctx, k, msg.DataNode); err != nil {
+		return nil, err
+	}
+
+	emitMessageEvent(ctx, msg.Owner)
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
 
+// checkMaxChannels - checks the datanode doesn't have more channels than allowed
+func checkMaxChannels(ctx sdk.Context, k DataNodeKeeper, address sdk.AccAddress) error {
+	channels, err := k.GetChannels(ctx, address)
+	if err != nil {
+		return err
+	}
+	if maxChannels := k.GetParams(ctx).MaxChannels; uint32(len(*channels)) > maxChannels {
+		return sdkerrors.Wrapf(types.ErrTooManyChannels, "datanode can't have more than %d channels", maxChannels)
+	}
+	return nil
+}
+
+// handleMsgGrantRole - handle a messsage to grant a role on the datanode to an account
+func handleMsgGrantRole(ctx sdk.Context, k DataNodeKeeper, msg types.MsgGrantRole) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkRoleManagement(ctx, k, dataNode, msg.Granter, msg.Address); err != nil {
+		return nil, err
+	}
+	if msg.Role == types.RoleAdmin && !dataNode.Owner.Equals(msg.Granter) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Granter - only the owner can grant the admin role")
+	}
+	for _, ch := range msg.Channels {
+		if !dataNode.HasChannelID(ch) {
+			return nil, sdkerrors.Wrap(types.ErrInvalidDataNodeChannel, ch)
+		}
+	}
+	if !msg.Expiry.IsZero() && !msg.Expiry.After(ctx.BlockTime()) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expiry must be after the block time")
+	}
+
+	grant := types.RoleGrant{
+		DataNode: msg.DataNode,
+		Address:  msg.Address,
+		Role:     msg.Role,
+		Channels: msg.Channels,
+		Expiry:   msg.Expiry,
+	}
+	k.SetRoleGrant(ctx, grant)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeRoleGranted,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyAddress, msg.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyRole, string(msg.Role)),
+			sdk.NewAttribute(types.AttributeKeyChannel, strings.Join(msg.Channels, ",")),
+		),
+	)
+	emitMessageEvent(ctx, msg.Granter)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgRevokeRole - handle a messsage to revoke the role of an account on the datanode
+func handleMsgRevokeRole(ctx sdk.Context, k DataNodeKeeper, msg types.MsgRevokeRole) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkRoleManagement(ctx, k, dataNode, msg.Granter, msg.Address); err != nil {
+		return nil, err
+	}
+	grant, err := k.GetRoleGrant(ctx, msg.DataNode, msg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	k.DeleteRoleGrant(ctx, msg.DataNode, msg.Address)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeRoleRevoked,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyAddress, msg.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyRole, string(grant.Role)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Granter)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// checkRoleManagement - checks the granter can change the role of the account, the owner manages all
+// the roles and the admins the ones of the non admin accounts
+func checkRoleManagement(ctx sdk.Context, k DataNodeKeeper, dataNode *types.DataNode, granter sdk.AccAddress, account sdk.AccAddress) error {
+	if err := checkPermission(ctx, k, dataNode.ID, granter, types.PermissionManageRoles); err != nil {
+		return err
+	}
+	if dataNode.Owner.Equals(granter) {
+		return nil
+	}
+	if grant, err := k.GetRoleGrant(ctx, dataNode.ID, account); err == nil && grant.Role == types.RoleAdmin {
+		return sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Granter - only the owner can change the admins")
+	}
+	return nil
+}
+
+// checkPermission - checks the account holds the permission on the datanode
+func checkPermission(ctx sdk.Context, k DataNodeKeeper, address sdk.AccAddress, account sdk.AccAddress, permission types.Permission) error {
+	if !k.HasPermission(ctx, address, account, permission) {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "Incorrect Owner - %s doesn't have the permission on the datanode", account)
+	}
+	return nil
+}
+
 // handleMsgAddRecords - handle a messsage to add records to persist
 func handleMsgAddRecords(ctx sdk.Context, k DataNodeKeeper, msg types.MsgAddRecords) (*sdk.Result, error) {
-	_, err := k.GetDataNode(ctx, msg.DataNode)
+	dataNode, err := getWritable(ctx, k, msg.DataNode)
 	if err != nil {
+		return nil, err
+	}
+
+	params := k.GetParams(ctx)
+	if uint32(len(msg.Records)) > params.MaxRecordsPerMsg {
+		return nil, sdkerrors.Wrapf(types.ErrTooManyRecords, "%d records, max %d", len(msg.Records), params.MaxRecordsPerMsg)
+	}
+	result := types.NewRecordsResult(msg.DataNode)
+	records, err := prepareRecords(ctx, params, *dataNode, nil, msg.Records, msg.Strict, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addRecords(ctx, k, *dataNode, msg.DataNode, records, &result); err != nil {
+		return nil, err
+	}
+	emitMessageEvent(ctx, msg.DataNode)
+	results := []types.RecordsResult{result}
+	return &sdk.Result{Data: types.ModuleCdc.MustMarshalJSON(results), Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgPruneRecords - handle a messsage to delete the records of a datanode channel older than a timestamp
+func handleMsgPruneRecords(ctx sdk.Context, k DataNodeKeeper, msg types.MsgPruneRecords) (*sdk.Result, error) {
+	if !k.IsDataNodePresent(ctx, msg.DataNode) {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
 	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionDelete); err != nil {
+		return nil, err
+	}
+
+	// records of deleted channels can be pruned too, to get their deposit back
+	count, refund, err := k.PruneRecords(ctx, msg.DataNode, msg.Channel, msg.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeRecordsPruned,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyChannel, msg.Channel),
+			sdk.NewAttribute(types.AttributeKeyCount, strconv.Itoa(count)),
+			sdk.NewAttribute(types.AttributeKeyTo, strconv.FormatInt(msg.Before, 10)),
+		),
+	)
+	emitStorageRefunded(ctx, refund)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgGatewayAddRecords - handle a messsage to add records of several datanodes from an authorized writer
+func handleMsgGatewayAddRecords(ctx sdk.Context, k DataNodeKeeper, msg types.MsgGatewayAddRecords) (*sdk.Result, error) {
+	params := k.GetParams(ctx)
+	if count := msg.RecordsCount(); uint32(count) > params.MaxRecordsPerMsg {
+		return nil, sdkerrors.Wrapf(types.ErrTooManyRecords, "%d records, max %d", count, params.MaxRecordsPerMsg)
+	}
 
-	for _, re := range msg.Records {
-		record := types.Record{
-			TimeStamp: re.TimeStamp,
-			Value:     re.Value,
-			Misc:      re.Misc,
+	dataNodes := make([]types.DataNode, len(msg.DataNodes))
+	records := make([][]channelRecord, len(msg.DataNodes))
+	results := make([]types.RecordsResult, len(msg.DataNodes))
+	for i, batch := range msg.DataNodes {
+		dataNode, err := getWritable(ctx, k, batch.DataNode)
+		if err != nil {
+			return nil, err
+		}
+		dataNodes[i] = *dataNode
+		results[i] = types.NewRecordsResult(batch.DataNode)
+		canWrite := k.ChannelWriter(ctx, *dataNode, msg.Gateway)
+		records[i], err = prepareRecords(ctx, params, *dataNode, canWrite, batch.Records, msg.Strict, &results[i])
+		if err != nil {
+			return nil, sdkerrors.Wrap(err, batch.DataNode.String())
 		}
-		k.AddRecordAtTimestamp(ctx, msg.DataNode, re.NodeChannelID, record)
 	}
+
+	for i, batch := range msg.DataNodes {
+		if err := addRecords(ctx, k, dataNodes[i], msg.Gateway, records[i], &results[i]); err != nil {
+			return nil, sdkerrors.Wrap(err, batch.DataNode.String())
+		}
+	}
+	emitMessageEvent(ctx, msg.Gateway)
+	return &sdk.Result{Data: types.ModuleCdc.MustMarshalJSON(results), Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgCreateFleet - handle a messsage to create a new fleet
+func handleMsgCreateFleet(ctx sdk.Context, k DataNodeKeeper, msg types.MsgCreateFleet) (*sdk.Result, error) {
+	if k.IsFleetPresent(ctx, msg.Fleet) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "fleet %s already exists", msg.Fleet)
+	}
+
+	k.SetFleet(ctx, types.NewFleet(msg.Fleet, msg.Admin))
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetCreated,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyAdmin, msg.Admin.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Admin)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetFleetAdmins - handle a messsage to replace the admins of a fleet
+func handleMsgSetFleetAdmins(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetFleetAdmins) (*sdk.Result, error) {
+	fleet, err := getFleetAsAdmin(ctx, k, msg.Fleet, msg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet.Admins = msg.Admins
+	k.SetFleet(ctx, *fleet)
+
+	admins := make([]string, len(msg.Admins))
+	for i, admin := range msg.Admins {
+		admins[i] = admin.String()
+	}
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetAdminsChanged,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyAdmin, strings.Join(admins, ",")),
+		),
+	)
+	emitMessageEvent(ctx, msg.Admin)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetFleetFeePayer - handle a messsage to set the account paying the fees of the fleet members
+func handleMsgSetFleetFeePayer(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetFleetFeePayer) (*sdk.Result, error) {
+	fleet, err := getFleetAsAdmin(ctx, k, msg.Fleet, msg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet.FeePayer = msg.FeePayer
+	k.SetFleet(ctx, *fleet)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetFeePayerSet,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyFeePayer, msg.FeePayer.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Admin)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgAddFleetMember - handle a messsage to add a datanode to a fleet, applying the fleet template
+func handleMsgAddFleetMember(ctx sdk.Context, k DataNodeKeeper, msg types.MsgAddFleetMember) (*sdk.Result, error) {
+	fleet, err := getFleetAsAdmin(ctx, k, msg.Fleet, msg.Admin)
+	if err != nil {
+		return nil, err
+	}
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if !dataNode.Owner.Equals(msg.Owner) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Owner - only the owner can add the datanode to a fleet")
+	}
+	if dataNode.Archived {
+		return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, msg.DataNode.String())
+	}
+	if dataNode.Fleet != "" {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "datanode is already member of fleet %s", dataNode.Fleet)
+	}
+
+	if err := k.SetFleetMember(ctx, msg.Fleet, msg.DataNode); err != nil {
+		return nil, err
+	}
+	if err := k.ApplyChannelTemplate(ctx, msg.DataNode, fleet.ChannelTemplate); err != nil {
+		return nil, err
+	}
+	if err := checkMaxChannels(ctx, k, msg.DataNode); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetMemberAdded,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Admin)
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
+
+// handleMsgRemoveFleetMember - handle a messsage to remove a datanode from a fleet
+func handleMsgRemoveFleetMember(ctx sdk.Context, k DataNodeKeeper, msg types.MsgRemoveFleetMember) (*sdk.Result, error) {
+	fleet, err := k.GetFleet(ctx, msg.Fleet)
+	if err != nil {
+		return nil, err
+	}
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if dataNode.Fleet != msg.Fleet {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "datanode is not member of fleet %s", msg.Fleet)
+	}
+	// both sides agreed on joining, either of them can leave
+	if !fleet.IsAdmin(msg.Sender) && !dataNode.Owner.Equals(msg.Sender) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "Incorrect Sender - must be a fleet admin or the datanode owner")
+	}
+
+	if err := k.SetFleetMember(ctx, "", msg.DataNode); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetMemberRemoved,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Sender)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgSetFleetTemplate - handle a messsage to set the channel template of a fleet on all its members,
+// archived members are left unchanged
+func handleMsgSetFleetTemplate(ctx sdk.Context, k DataNodeKeeper, msg types.MsgSetFleetTemplate) (*sdk.Result, error) {
+	fleet, err := getFleetAsAdmin(ctx, k, msg.Fleet, msg.Admin)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet.ChannelTemplate = msg.Channels
+	k.SetFleet(ctx, *fleet)
+
+	// members are collected first, the index must not change while iterating it
+	var members []sdk.AccAddress
+	k.IterateFleetMembers(ctx, msg.Fleet, func(address sdk.AccAddress) bool {
+		members = append(members, address)
+		return false
+	})
+
+	updated := 0
+	for _, address := range members {
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil || dataNode.Archived {
+			continue
+		}
+		if err := k.ApplyChannelTemplate(ctx, address, msg.Channels); err != nil {
+			return nil, err
+		}
+		if err := checkMaxChannels(ctx, k, address); err != nil {
+			return nil, sdkerrors.Wrapf(err, "datanode %s", address)
+		}
+		updated++
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeFleetTemplateSet,
+			sdk.NewAttribute(types.AttributeKeyFleet, msg.Fleet),
+			sdk.NewAttribute(types.AttributeKeyCount, strconv.Itoa(updated)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Admin)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgPublishDeviceType - handle a messsage to publish a new version of a device type
+func handleMsgPublishDeviceType(ctx sdk.Context, k DataNodeKeeper, msg types.MsgPublishDeviceType) (*sdk.Result, error) {
+	version := uint32(1)
+	if latest, err := k.GetLatestDeviceType(ctx, msg.DeviceType); err == nil {
+		if !latest.Owner.Equals(msg.Owner) {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "Incorrect Owner - device type %s is owned by %s", msg.DeviceType, latest.Owner)
+		}
+		version = latest.Version + 1
+	}
+	if maxChannels := k.GetParams(ctx).MaxChannels; uint32(len(msg.Channels)) > maxChannels {
+		return nil, sdkerrors.Wrapf(types.ErrTooManyChannels, "device type can't have more than %d channels", maxChannels)
+	}
+
+	k.SetDeviceType(ctx, types.DeviceType{
+		ID:          msg.DeviceType,
+		Version:     version,
+		Owner:       msg.Owner,
+		Description: msg.Description,
+		Channels:    msg.Channels,
+	})
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDeviceTypePublished,
+			sdk.NewAttribute(types.AttributeKeyDeviceType, msg.DeviceType),
+			sdk.NewAttribute(types.AttributeKeyVersion, strconv.FormatUint(uint64(version), 10)),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgTransferDeviceType - handle a messsage to transfer a device type to a new owner
+func handleMsgTransferDeviceType(ctx sdk.Context, k DataNodeKeeper, msg types.MsgTransferDeviceType) (*sdk.Result, error) {
+	latest, err := k.GetLatestDeviceType(ctx, msg.DeviceType)
+	if err != nil {
+		return nil, sdkerrors.Wrap(err, msg.DeviceType)
+	}
+	if !latest.Owner.Equals(msg.Owner) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "Incorrect Owner - device type %s is owned by %s", msg.DeviceType, latest.Owner)
+	}
+
+	k.TransferDeviceType(ctx, msg.DeviceType, msg.NewOwner)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDeviceTypeTransferred,
+			sdk.NewAttribute(types.AttributeKeyDeviceType, msg.DeviceType),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyNewOwner, msg.NewOwner.String()),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgLinkDeviceType - handle a messsage to link a datanode to a device type or unlink it
+func handleMsgLinkDeviceType(ctx sdk.Context, k DataNodeKeeper, msg types.MsgLinkDeviceType) (*sdk.Result, error) {
+	dataNode, err := k.GetDataNode(ctx, msg.DataNode)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if err := checkPermission(ctx, k, msg.DataNode, msg.Owner, types.PermissionEditChannels); err != nil {
+		return nil, err
+	}
+	if dataNode.Archived {
+		return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, msg.DataNode.String())
+	}
+
+	if msg.DeviceType == "" {
+		if dataNode.DeviceType == "" {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "datanode is not linked to a device type")
+		}
+		if err := k.LinkDeviceType(ctx, msg.DataNode, nil); err != nil {
+			return nil, err
+		}
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeDeviceTypeUnlinked,
+				sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyDeviceType, dataNode.DeviceType),
+			),
+		)
+		emitMessageEvent(ctx, msg.Owner)
+		return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+	}
+
+	deviceType, err := k.GetDeviceType(ctx, msg.DeviceType, msg.Version)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(err, "%s version %d", msg.DeviceType, msg.Version)
+	}
+	if err := k.LinkDeviceType(ctx, msg.DataNode, deviceType); err != nil {
+		return nil, err
+	}
+	if err := checkMaxChannels(ctx, k, msg.DataNode); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDeviceTypeLinked,
+			sdk.NewAttribute(types.AttributeKeyDataNode, msg.DataNode.String()),
+			sdk.NewAttribute(types.AttributeKeyDeviceType, deviceType.ID),
+			sdk.NewAttribute(types.AttributeKeyVersion, strconv.FormatUint(uint64(deviceType.Version), 10)),
+		),
+	)
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgUpgradeDataNodes - handle a messsage to upgrade datanodes to a newer version of their device type
+func handleMsgUpgradeDataNodes(ctx sdk.Context, k DataNodeKeeper, msg types.MsgUpgradeDataNodes) (*sdk.Result, error) {
+	deviceType, err := k.GetDeviceType(ctx, msg.DeviceType, msg.Version)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(err, "%s version %d", msg.DeviceType, msg.Version)
+	}
+
+	for _, address := range msg.DataNodes {
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - %s not defined", address)
+		}
+		if err := checkPermission(ctx, k, address, msg.Owner, types.PermissionEditChannels); err != nil {
+			return nil, err
+		}
+		if dataNode.Archived {
+			return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, address.String())
+		}
+		if dataNode.DeviceType != msg.DeviceType {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "datanode %s is not linked to device type %s", address, msg.DeviceType)
+		}
+		if dataNode.TypeVersion >= deviceType.Version {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "datanode %s is already on version %d", address, dataNode.TypeVersion)
+		}
+
+		if err := k.LinkDeviceType(ctx, address, deviceType); err != nil {
+			return nil, err
+		}
+		if err := checkMaxChannels(ctx, k, address); err != nil {
+			return nil, sdkerrors.Wrapf(err, "datanode %s", address)
+		}
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeDeviceTypeUpgraded,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyDeviceType, deviceType.ID),
+				sdk.NewAttribute(types.AttributeKeyPrevVersion, strconv.FormatUint(uint64(dataNode.TypeVersion), 10)),
+				sdk.NewAttribute(types.AttributeKeyVersion, strconv.FormatUint(uint64(deviceType.Version), 10)),
+			),
+		)
+	}
+	emitMessageEvent(ctx, msg.Owner)
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// getFleetAsAdmin - gets the fleet checking the account is one of its admins
+func getFleetAsAdmin(ctx sdk.Context, k DataNodeKeeper, id string, admin sdk.AccAddress) (*types.Fleet, error) {
+	fleet, err := k.GetFleet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !fleet.IsAdmin(admin) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "Incorrect Admin - %s is not admin of fleet %s", admin, id)
+	}
+	return fleet, nil
+}
+
+// getWritable - gets the datanode if it exists and accepts new records
+func getWritable(ctx sdk.Context, k DataNodeKeeper, address sdk.AccAddress) (*types.DataNode, error) {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownAddress, "Incorrect DataNode - not defined")
+	}
+	if dataNode.Archived {
+		return nil, sdkerrors.Wrap(types.ErrDataNodeArchived, address.String())
+	}
+	return dataNode, nil
+}
+
+// channelRecord - record to be added to a channel of the datanode
+type channelRecord struct {
+	index     int
+	channelID string
+	record    types.Record
+}
+
+// prepareRecords - converts the new records to records v2 and returns the ones that can be added. The
+// invalid records and the ones of the channels the signer can't write, when canWrite is set, are rejected
+// on the result, or fail the message on strict mode
+func prepareRecords(ctx sdk.Context, params types.Params, dataNode types.DataNode, canWrite func(channelID string) bool, newRecords []types.NewRecord, strict bool, result *types.RecordsResult) ([]channelRecord, error) {
+	minTimeStamp, maxTimeStamp := acceptanceWindow(ctx, params, dataNode)
+	records := make([]channelRecord, 0, len(newRecords))
+	for i, re := range newRecords {
+		var record types.Record
+		var err error
+		if canWrite != nil && !canWrite(re.NodeChannelID) {
+			err = sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "Incorrect Writer - can't write channel %s", re.NodeChannelID)
+		} else {
+			record, err = prepareRecord(params, dataNode, re, minTimeStamp, maxTimeStamp)
+		}
+		if err != nil {
+			if strict {
+				return nil, sdkerrors.Wrapf(err, "record %d", i)
+			}
+			result.Rejected = append(result.Rejected, types.RecordResult{Index: i, Channel: re.NodeChannelID, Time: re.Millis(), Reason: err.Error()})
+			continue
+		}
+		records = append(records, channelRecord{index: i, channelID: re.NodeChannelID, record: record})
+	}
+	return records, nil
+}
+
+// prepareRecord - converts the new record to a record v2 and checks it against the module params, the
+// acceptance window and the schema of its channel
+func prepareRecord(params types.Params, dataNode types.DataNode, re types.NewRecord, minTimeStamp int64, maxTimeStamp int64) (types.Record, error) {
+	if re.IsLegacy() && !params.LegacyRecords {
+		return types.Record{}, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "legacy records are not accepted, set time and int_value")
+	}
+	if uint32(len(re.Misc)) > params.MaxMiscLength {
+		return types.Record{}, sdkerrors.Wrapf(types.ErrMiscTooLong, "%d bytes, max %d", len(re.Misc), params.MaxMiscLength)
+	}
+
+	channel, ok := dataNode.GetChannel(re.NodeChannelID)
+	if !ok {
+		return types.Record{}, sdkerrors.Wrap(types.ErrInvalidDataNodeChannel, re.NodeChannelID)
+	}
+	record, err := re.Record(channel)
+	if err != nil {
+		return types.Record{}, sdkerrors.Wrapf(types.ErrInvalidRecordValue, "channel %s: %s", re.NodeChannelID, err)
+	}
+	if record.TimeStamp > maxTimeStamp {
+		return types.Record{}, sdkerrors.Wrapf(types.ErrInvalidTimestamp, "%d ms is ahead of block time", record.TimeStamp)
+	}
+	if record.TimeStamp < minTimeStamp {
+		return types.Record{}, sdkerrors.Wrapf(types.ErrInvalidTimestamp, "%d ms is older than the max backfill age", record.TimeStamp)
+	}
+	if err := channel.ValidateValue(record); err != nil {
+		return types.Record{}, sdkerrors.Wrapf(types.ErrInvalidRecordValue, "channel %s at %d ms: %s", re.NodeChannelID, record.TimeStamp, err)
+	}
+	return record, nil
+}
+
+// acceptanceWindow - first and last timestamps in milliseconds of the records accepted at the block time,
+// the datanodes on backfill mode accept records of any age
+func acceptanceWindow(ctx sdk.Context, params types.Params, dataNode types.DataNode) (int64, int64) {
+	now := ctx.BlockTime().Unix()
+	minTimeStamp := (now - params.MaxBackfillAge) * types.MillisPerSecond
+	if dataNode.Backfill {
+		minTimeStamp = 0
+	}
+	return minTimeStamp, (now + params.MaxTimestampSkew) * types.MillisPerSecond
+}
+
+// addRecords - adds the records to the datanode, completing the result with the accepted and the duplicated
+// ones, and emits a records added event by channel and an event by duplicated or rejected record. The
+// storage deposit of the records added is paid by the fee payers of the datanode within its fee allowance,
+// or else by the signer
+func addRecords(ctx sdk.Context, k DataNodeKeeper, dataNode types.DataNode, signer sdk.AccAddress, records []channelRecord, result *types.RecordsResult) error {
+	address := dataNode.ID
+	stored := k.GetStorageDeposit(ctx, address).Bytes
+
+	// added records summary by channel, in order of appearance
+	var channels []string
+	added := make(map[string]*recordsAdded)
+	for _, re := range records {
+		res := types.RecordResult{Index: re.index, Channel: re.channelID, Time: re.record.TimeStamp}
+		if err := k.AddRecord(ctx, address, re.channelID, re.record); err != nil {
+			if types.ErrDuplicateRecord.Is(err) {
+				result.Duplicates = append(result.Duplicates, res)
+			} else {
+				res.Reason = err.Error()
+				result.Rejected = append(result.Rejected, res)
+			}
+			continue
+		}
+		result.Accepted = append(result.Accepted, res)
+
+		summary, ok := added[re.channelID]
+		if !ok {
+			summary = &recordsAdded{from: re.record.TimeStamp, to: re.record.TimeStamp}
+			added[re.channelID] = summary
+			channels = append(channels, re.channelID)
+		}
+		summary.add(re.record.TimeStamp)
+	}
+
+	bytes := k.GetStorageDeposit(ctx, address).Bytes - stored
+	payer, deposit, caps, err := k.DepositStorage(ctx, address, storagePayers(ctx, k, dataNode, signer), signer, bytes)
+	if err != nil {
+		return err
+	}
+	for _, feeCap := range caps {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeFeeCapReached,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyCap, feeCap),
+			),
+		)
+	}
+	if !deposit.Empty() {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeStorageDeposited,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyFeePayer, payer.String()),
+				sdk.NewAttribute(types.AttributeKeyBytes, strconv.FormatInt(bytes, 10)),
+				sdk.NewAttribute(types.AttributeKeyAmount, deposit.String()),
+			),
+		)
+	}
+
+	for _, ch := range channels {
+		summary := added[ch]
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeRecordsAdded,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyChannel, ch),
+				sdk.NewAttribute(types.AttributeKeyCount, strconv.Itoa(summary.count)),
+				sdk.NewAttribute(types.AttributeKeyFrom, strconv.FormatInt(summary.from, 10)),
+				sdk.NewAttribute(types.AttributeKeyTo, strconv.FormatInt(summary.to, 10)),
+			),
+		)
+	}
+	for _, res := range result.Duplicates {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeRecordDuplicate,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyIndex, strconv.Itoa(res.Index)),
+				sdk.NewAttribute(types.AttributeKeyChannel, res.Channel),
+				sdk.NewAttribute(types.AttributeKeyTime, strconv.FormatInt(res.Time, 10)),
+			),
+		)
+	}
+	for _, res := range result.Rejected {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeRecordRejected,
+				sdk.NewAttribute(types.AttributeKeyDataNode, address.String()),
+				sdk.NewAttribute(types.AttributeKeyIndex, strconv.Itoa(res.Index)),
+				sdk.NewAttribute(types.AttributeKeyChannel, res.Channel),
+				sdk.NewAttribute(types.AttributeKeyTime, strconv.FormatInt(res.Time, 10)),
+				sdk.NewAttribute(types.AttributeKeyReason, res.Reason),
+			),
+		)
+	}
+	return nil
+}
+
+// storagePayers - accounts that can pay the storage deposit of the records of the datanode in order of
+// preference, the fee payers of the datanode, capped by its fee allowance, and then the signer writing the
+// records
+func storagePayers(ctx sdk.Context, k DataNodeKeeper, dataNode types.DataNode, signer sdk.AccAddress) []sdk.AccAddress {
+	payers := k.GetFeePayers(ctx, dataNode)
+	for _, payer := range payers {
+		if payer.Equals(signer) {
+			return payers
+		}
+	}
+	return append(payers, signer)
+}
+
+// emitStorageRefunded - emits a storage refunded event by depositor refunded when records were deleted,
+// each with the bytes released and the coins refunded to the depositor
+func emitStorageRefunded(ctx sdk.Context, refund types.StorageRefund) {
+	if refund.Bytes == 0 {
+		return
+	}
+	for _, share := range refund.Refunds {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeStorageRefunded,
+				sdk.NewAttribute(types.AttributeKeyDataNode, refund.DataNode.String()),
+				sdk.NewAttribute(types.AttributeKeyDepositor, share.Address.String()),
+				sdk.NewAttribute(types.AttributeKeyBytes, strconv.FormatInt(refund.Bytes, 10)),
+				sdk.NewAttribute(types.AttributeKeyAmount, share.Amount.String()),
+			),
+		)
+	}
+}
+
+// recordsAdded - count and time range of the records added to a channel
+type recordsAdded struct {
+	count int
+	from  int64
+	to    int64
+}
+
+func (r *recordsAdded) add(timeStamp int64) {
+	r.count++
+	if timeStamp < r.from {
+		r.from = timeStamp
+	}
+	if timeStamp > r.to {
+		r.to = timeStamp
+	}
+}
+
+// emitMessageEvent - emits the module message event with the sender of the message
+func emitMessageEvent(ctx sdk.Context, sender sdk.AccAddress) {
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, sender.String()),
+		),
+	)
+}
@@ -0,0 +1,392 @@
+package datanode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/qonico/cosmos-iot/x/datanode/keeper"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+var (
+	testDataNode = sdk.AccAddress([]byte("test-datanode-addr01"))
+	testOwner    = sdk.AccAddress([]byte("test-owner-address01"))
+)
+
+// createTestHandler returns the test input of the keeper with a datanode owning channel 1, and the
+// module handler
+func createTestHandler(t *testing.T, blockTime time.Time) (sdk.Context, DataNodeKeeper, sdk.Handler) {
+	ctx, k := keeper.CreateTestInput(t, blockTime)
+	// there's no bank keeper to take the storage deposits
+	params := k.GetParams(ctx)
+	params.StorageDepositPerByte = sdk.NewInt64Coin(sdk.DefaultBondDenom, 0)
+	k.SetParams(ctx, params)
+
+	k.SetDataNodeOwner(ctx, testDataNode, testOwner)
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+	return ctx, k, NewHandler(k)
+}
+
+func TestAddRecordsResults(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, _, handler := createTestHandler(t, now)
+	nowMs := now.Unix() * types.MillisPerSecond
+	oldMs := now.Add(-30*24*time.Hour).Unix() * types.MillisPerSecond
+
+	records := []types.NewRecord{
+		{NodeChannelID: "1", Time: nowMs, IntValue: 1},
+		{NodeChannelID: "1", Time: nowMs, IntValue: 2},
+		{NodeChannelID: "1", Time: nowMs + time.Hour.Milliseconds(), IntValue: 3},
+		{NodeChannelID: "1", Time: oldMs, IntValue: 4},
+		{NodeChannelID: "2", Time: nowMs, IntValue: 5},
+	}
+
+	// strict mode fails the whole message on the first invalid record
+	cacheCtx, _ := ctx.CacheContext()
+	_, err := handler(cacheCtx, types.NewMsgAddRecords(testDataNode, records, true))
+	require.True(t, types.ErrInvalidTimestamp.Is(err))
+
+	res, err := handler(ctx, types.NewMsgAddRecords(testDataNode, records, false))
+	require.NoError(t, err)
+	var results []types.RecordsResult
+	types.ModuleCdc.MustUnmarshalJSON(res.Data, &results)
+	require.Len(t, results, 1)
+	require.Equal(t, testDataNode, results[0].DataNode)
+	require.Equal(t, []types.RecordResult{{Index: 0, Channel: "1", Time: nowMs}}, results[0].Accepted)
+	require.Equal(t, []types.RecordResult{{Index: 1, Channel: "1", Time: nowMs}}, results[0].Duplicates)
+	require.Len(t, results[0].Rejected, 3)
+	for i, re := range results[0].Rejected {
+		require.Equal(t, i+2, re.Index)
+		require.Equal(t, records[i+2].NodeChannelID, re.Channel)
+		require.Equal(t, records[i+2].Time, re.Time)
+		require.NotEmpty(t, re.Reason)
+	}
+	requireEventCount(t, res.Events, types.EventTypeRecordDuplicate, 1)
+	requireEventCount(t, res.Events, types.EventTypeRecordRejected, 3)
+
+	// a second message reports the records already stored as duplicates
+	res, err = handler(ctx, types.NewMsgAddRecords(testDataNode, records[:1], true))
+	require.NoError(t, err)
+	types.ModuleCdc.MustUnmarshalJSON(res.Data, &results)
+	require.Empty(t, results[0].Accepted)
+	require.Equal(t, []types.RecordResult{{Index: 0, Channel: "1", Time: nowMs}}, results[0].Duplicates)
+}
+
+func TestGatewayAddRecordsResults(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k, handler := createTestHandler(t, now)
+	nowMs := now.Unix() * types.MillisPerSecond
+
+	gateway := sdk.AccAddress([]byte("test-gateway-addr001"))
+	otherDataNode := sdk.AccAddress([]byte("test-datanode-addr02"))
+	k.SetDataNodeOwner(ctx, otherDataNode, testOwner)
+	require.NoError(t, k.ChangeChannel(ctx, otherDataNode, types.NodeChannel{ID: "1", Variable: "humidity"}))
+	for _, address := range []sdk.AccAddress{testDataNode, otherDataNode} {
+		_, err := handler(ctx, types.NewMsgGrantRole(testOwner, address, gateway, types.RoleWriter, nil, time.Time{}))
+		require.NoError(t, err)
+	}
+
+	res, err := handler(ctx, types.NewMsgGatewayAddRecords(gateway, []types.DataNodeRecords{
+		{DataNode: testDataNode, Records: []types.NewRecord{
+			{NodeChannelID: "1", Time: nowMs, IntValue: 1},
+			{NodeChannelID: "1", Time: nowMs, IntValue: 2},
+		}},
+		{DataNode: otherDataNode, Records: []types.NewRecord{
+			{NodeChannelID: "2", Time: nowMs, IntValue: 3},
+			{NodeChannelID: "1", Time: nowMs, IntValue: 4},
+		}},
+	}, false))
+	require.NoError(t, err)
+
+	// a result by datanode, in the order of the message
+	var results []types.RecordsResult
+	types.ModuleCdc.MustUnmarshalJSON(res.Data, &results)
+	require.Len(t, results, 2)
+	require.Equal(t, testDataNode, results[0].DataNode)
+	require.Equal(t, []types.RecordResult{{Index: 0, Channel: "1", Time: nowMs}}, results[0].Accepted)
+	require.Equal(t, []types.RecordResult{{Index: 1, Channel: "1", Time: nowMs}}, results[0].Duplicates)
+	require.Empty(t, results[0].Rejected)
+	require.Equal(t, otherDataNode, results[1].DataNode)
+	require.Equal(t, []types.RecordResult{{Index: 1, Channel: "1", Time: nowMs}}, results[1].Accepted)
+	require.Empty(t, results[1].Duplicates)
+	require.Len(t, results[1].Rejected, 1)
+	require.Equal(t, 0, results[1].Rejected[0].Index)
+	require.True(t, strings.Contains(results[1].Rejected[0].Reason, types.ErrInvalidDataNodeChannel.Error()))
+}
+
+func TestGatewayAddRecordsChannelPermission(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k, handler := createTestHandler(t, now)
+	nowMs := now.Unix() * types.MillisPerSecond
+
+	gateway := sdk.AccAddress([]byte("test-gateway-addr001"))
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "2", Variable: "humidity"}))
+	_, err := handler(ctx, types.NewMsgGrantRole(testOwner, testDataNode, gateway, types.RoleWriter, []string{"1"}, time.Time{}))
+	require.NoError(t, err)
+
+	newRecords := []types.DataNodeRecords{{DataNode: testDataNode, Records: []types.NewRecord{
+		{NodeChannelID: "2", Time: nowMs, IntValue: 1},
+		{NodeChannelID: "1", Time: nowMs, IntValue: 2},
+	}}}
+
+	// strict mode fails the whole message
+	cacheCtx, _ := ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgGatewayAddRecords(gateway, newRecords, true))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+
+	// otherwise only the records of the channels the gateway can't write are rejected
+	res, err := handler(ctx, types.NewMsgGatewayAddRecords(gateway, newRecords, false))
+	require.NoError(t, err)
+	var results []types.RecordsResult
+	types.ModuleCdc.MustUnmarshalJSON(res.Data, &results)
+	require.Len(t, results, 1)
+	require.Equal(t, []types.RecordResult{{Index: 1, Channel: "1", Time: nowMs}}, results[0].Accepted)
+	require.Len(t, results[0].Rejected, 1)
+	require.Equal(t, 0, results[0].Rejected[0].Index)
+	require.True(t, strings.Contains(results[0].Rejected[0].Reason, sdkerrors.ErrUnauthorized.Error()))
+}
+
+// requireEventCount - requires the events to hold count events of the type
+func requireEventCount(t *testing.T, events sdk.Events, eventType string, count int) {
+	var found int
+	for _, event := range events {
+		if event.Type == eventType {
+			found++
+		}
+	}
+	require.Equal(t, count, found, eventType)
+}
+
+// requireEvent - requires the events to hold an event of the type and returns its attributes
+func requireEvent(t *testing.T, events sdk.Events, eventType string) map[string]string {
+	for _, event := range events {
+		if event.Type == eventType {
+			attributes := make(map[string]string)
+			for _, attribute := range event.Attributes {
+				attributes[string(attribute.Key)] = string(attribute.Value)
+			}
+			return attributes
+		}
+	}
+	require.FailNow(t, "event not found", eventType)
+	return nil
+}
+
+func TestDataNodeEvents(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, _, handler := createTestHandler(t, now)
+	nowMs := now.Unix() * types.MillisPerSecond
+
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr02"))
+	res, err := handler(ctx, types.NewMsgSetOwner(dataNode, dataNode, testOwner, "boiler", ""))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDataNode: dataNode.String(),
+		types.AttributeKeyOwner:    testOwner.String(),
+	}, requireEvent(t, res.Events, types.EventTypeDataNodeCreated))
+	require.Equal(t, map[string]string{
+		sdk.AttributeKeyModule: types.AttributeValueCategory,
+		sdk.AttributeKeySender: dataNode.String(),
+	}, requireEvent(t, res.Events, sdk.EventTypeMessage))
+
+	newOwner := sdk.AccAddress([]byte("test-owner-address02"))
+	res, err = handler(ctx, types.NewMsgSetOwner(dataNode, testOwner, newOwner, "", ""))
+	require.NoError(t, err)
+	requireEventCount(t, res.Events, types.EventTypeDataNodeCreated, 0)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDataNode:      dataNode.String(),
+		types.AttributeKeyPreviousOwner: testOwner.String(),
+		types.AttributeKeyOwner:         newOwner.String(),
+	}, requireEvent(t, res.Events, types.EventTypeOwnerChanged))
+
+	res, err = handler(ctx, types.NewMsgUpdateChannels(newOwner, dataNode, []types.ChannelUpdate{
+		{Action: "set", ID: "1", Variable: "temperature"},
+		{Action: "set", ID: "2", Variable: "humidity"},
+	}))
+	require.NoError(t, err)
+	requireEventCount(t, res.Events, types.EventTypeChannelSet, 2)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDataNode: dataNode.String(),
+		types.AttributeKeyChannel:  "1",
+		types.AttributeKeyVariable: "temperature",
+	}, requireEvent(t, res.Events, types.EventTypeChannelSet))
+
+	res, err = handler(ctx, types.NewMsgUpdateChannels(newOwner, dataNode, []types.ChannelUpdate{{Action: "delete", ID: "2"}}))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDataNode: dataNode.String(),
+		types.AttributeKeyChannel:  "2",
+	}, requireEvent(t, res.Events, types.EventTypeChannelDeleted))
+
+	// a records added event by channel summarizes the records stored
+	res, err = handler(ctx, types.NewMsgAddRecords(dataNode, []types.NewRecord{
+		{NodeChannelID: "1", Time: nowMs - 2000, IntValue: 1},
+		{NodeChannelID: "1", Time: nowMs, IntValue: 2},
+		{NodeChannelID: "1", Time: nowMs - 1000, IntValue: 3},
+	}, true))
+	require.NoError(t, err)
+	requireEventCount(t, res.Events, types.EventTypeRecordsAdded, 1)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDataNode: dataNode.String(),
+		types.AttributeKeyChannel:  "1",
+		types.AttributeKeyCount:    "3",
+		types.AttributeKeyFrom:     strconv.FormatInt(nowMs-2000, 10),
+		types.AttributeKeyTo:       strconv.FormatInt(nowMs, 10),
+	}, requireEvent(t, res.Events, types.EventTypeRecordsAdded))
+}
+
+func TestUpdateDataNode(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k, handler := createTestHandler(t, now)
+	querier := keeper.NewQuerier(k)
+	queryDataNode := func(address sdk.AccAddress) types.DataNode {
+		bz, err := querier(ctx, []string{types.QueryDataNode, address.String()}, abci.RequestQuery{})
+		require.NoError(t, err)
+		var res types.QueryResDataNode
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		return res.DataNode
+	}
+
+	// the name is taken on creation
+	dataNode := sdk.AccAddress([]byte("test-datanode-addr02"))
+	_, err := handler(ctx, types.NewMsgSetOwner(dataNode, dataNode, testOwner, "boiler", ""))
+	require.NoError(t, err)
+	require.Equal(t, "boiler", queryDataNode(dataNode).Name)
+
+	msg := types.NewMsgUpdateDataNode(testOwner, dataNode, "boiler room", "gas boiler", []string{"heating", "basement"}, "building A", "1.2.0")
+	require.NoError(t, msg.ValidateBasic())
+	res, err := handler(ctx, msg)
+	require.NoError(t, err)
+	require.Equal(t, "boiler room", requireEvent(t, res.Events, types.EventTypeDataNodeUpdated)[types.AttributeKeyName])
+
+	updated := queryDataNode(dataNode)
+	require.Equal(t, "boiler room", updated.Name)
+	require.Equal(t, "gas boiler", updated.Description)
+	require.Equal(t, []string{"heating", "basement"}, updated.Tags)
+	require.Equal(t, "building A", updated.Location)
+	require.Equal(t, "1.2.0", updated.FirmwareVersion)
+	require.Equal(t, testOwner, updated.Owner)
+
+	// an empty name resets it to the address
+	_, err = handler(ctx, types.NewMsgUpdateDataNode(testOwner, dataNode, "", "", nil, "", ""))
+	require.NoError(t, err)
+	updated = queryDataNode(dataNode)
+	require.Equal(t, dataNode.String(), updated.Name)
+	require.Empty(t, updated.Description)
+	require.Empty(t, updated.Tags)
+
+	// only the owner and the operators edit the metadata
+	cacheCtx, _ := ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgUpdateDataNode(dataNode, dataNode, "renamed", "", nil, "", ""))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+
+	require.Error(t, types.NewMsgUpdateDataNode(testOwner, dataNode, strings.Repeat("n", types.MaxNameLength+1), "", nil, "", "").ValidateBasic())
+	require.Error(t, types.NewMsgUpdateDataNode(testOwner, dataNode, "", "", []string{"heating", "heating"}, "", "").ValidateBasic())
+}
+
+func TestAcceptanceWindow(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k, handler := createTestHandler(t, now)
+	params := k.GetParams(ctx)
+	nowMs := now.Unix() * types.MillisPerSecond
+	newestMs := nowMs + params.MaxTimestampSkew*types.MillisPerSecond
+	oldestMs := nowMs - params.MaxBackfillAge*types.MillisPerSecond
+
+	// the bounds of the window are accepted, the records out of it rejected
+	for _, re := range []struct {
+		time     int64
+		accepted bool
+	}{
+		{newestMs, true},
+		{oldestMs, true},
+		{newestMs + 1, false},
+		{oldestMs - 1, false},
+	} {
+		cacheCtx, _ := ctx.CacheContext()
+		_, err := handler(cacheCtx, types.NewMsgAddRecords(testDataNode, []types.NewRecord{{NodeChannelID: "1", Time: re.time, IntValue: 1}}, true))
+		if re.accepted {
+			require.NoError(t, err, re.time)
+		} else {
+			require.True(t, types.ErrInvalidTimestamp.Is(err), re.time)
+		}
+	}
+
+	// only the owner and the admins set the backfill mode
+	cacheCtx, _ := ctx.CacheContext()
+	_, err := handler(cacheCtx, types.NewMsgSetBackfill(testDataNode, testDataNode, true))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+
+	res, err := handler(ctx, types.NewMsgSetBackfill(testOwner, testDataNode, true))
+	require.NoError(t, err)
+	require.Equal(t, "true", requireEvent(t, res.Events, types.EventTypeBackfillSet)[types.AttributeKeyEnabled])
+	dataNode, err := k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.True(t, dataNode.Backfill)
+
+	// the backfill mode accepts records of any age, still not ahead of the block time skew
+	old := []types.NewRecord{{NodeChannelID: "1", Time: oldestMs - 1, IntValue: 1}}
+	_, err = handler(ctx, types.NewMsgAddRecords(testDataNode, old, true))
+	require.NoError(t, err)
+	require.True(t, k.HasRecord(ctx, testDataNode, "1", oldestMs-1))
+	cacheCtx, _ = ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgAddRecords(testDataNode, []types.NewRecord{{NodeChannelID: "1", Time: newestMs + 1, IntValue: 1}}, true))
+	require.True(t, types.ErrInvalidTimestamp.Is(err))
+
+	cacheCtx, _ = ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgSetBackfill(testOwner, testDataNode, true))
+	require.True(t, sdkerrors.ErrInvalidRequest.Is(err))
+
+	_, err = handler(ctx, types.NewMsgSetBackfill(testOwner, testDataNode, false))
+	require.NoError(t, err)
+	_, err = handler(ctx, types.NewMsgAddRecords(testDataNode, []types.NewRecord{{NodeChannelID: "1", Time: oldestMs - 2, IntValue: 1}}, true))
+	require.True(t, types.ErrInvalidTimestamp.Is(err))
+}
+
+func TestDeviceTypeOwnership(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k, handler := createTestHandler(t, now)
+
+	other := sdk.AccAddress([]byte("test-owner-address02"))
+	channels := []types.NodeChannel{{ID: "1", Variable: "temperature"}}
+	_, err := handler(ctx, types.NewMsgPublishDeviceType(testOwner, "thermo", "", channels))
+	require.NoError(t, err)
+
+	// a second publisher can't publish nor transfer the device type
+	cacheCtx, _ := ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgPublishDeviceType(other, "thermo", "", channels))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+	_, err = handler(cacheCtx, types.NewMsgTransferDeviceType(other, "thermo", other))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+
+	// the owner transfers every version, the new owner publishes the next one
+	_, err = handler(ctx, types.NewMsgPublishDeviceType(testOwner, "thermo", "", channels))
+	require.NoError(t, err)
+	res, err := handler(ctx, types.NewMsgTransferDeviceType(testOwner, "thermo", other))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		types.AttributeKeyDeviceType: "thermo",
+		types.AttributeKeyOwner:      testOwner.String(),
+		types.AttributeKeyNewOwner:   other.String(),
+	}, requireEvent(t, res.Events, types.EventTypeDeviceTypeTransferred))
+	for _, version := range []uint32{1, 2} {
+		deviceType, err := k.GetDeviceType(ctx, "thermo", version)
+		require.NoError(t, err)
+		require.Equal(t, other, deviceType.Owner)
+	}
+
+	cacheCtx, _ = ctx.CacheContext()
+	_, err = handler(cacheCtx, types.NewMsgPublishDeviceType(testOwner, "thermo", "", channels))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err))
+	_, err = handler(ctx, types.NewMsgPublishDeviceType(other, "thermo", "", channels))
+	require.NoError(t, err)
+	latest, err := k.GetLatestDeviceType(ctx, "thermo")
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), latest.Version)
+}
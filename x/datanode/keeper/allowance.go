@@ -0,0 +1,77 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Fee allowance methods
+
+// GetFeeAllowance - get the fee allowance of the datanode
+func (k DataNodeKeeper) GetFeeAllowance(ctx sdk.Context, address sdk.AccAddress) (*types.FeeAllowance, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.FeeAllowanceKey(address))
+	if bz == nil {
+		return nil, types.ErrNoFeeAllowance
+	}
+	var allowance types.FeeAllowance
+	k.cdc.MustUnmarshalBinaryBare(bz, &allowance)
+	return &allowance, nil
+}
+
+// SetFeeAllowance - sets the fee allowance, replacing the previous one of the datanode
+func (k DataNodeKeeper) SetFeeAllowance(ctx sdk.Context, allowance types.FeeAllowance) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.FeeAllowanceKey(allowance.DataNode), k.cdc.MustMarshalBinaryBare(allowance))
+}
+
+// DeleteFeeAllowance - removes the fee allowance of the datanode, its fees are not capped anymore
+func (k DataNodeKeeper) DeleteFeeAllowance(ctx sdk.Context, address sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.FeeAllowanceKey(address))
+}
+
+// IterateFeeAllowances - iterate over all the fee allowances
+func (k DataNodeKeeper) IterateFeeAllowances(ctx sdk.Context, cb func(allowance types.FeeAllowance) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.FeeAllowancePrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var allowance types.FeeAllowance
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &allowance)
+		if cb(allowance) {
+			break
+		}
+	}
+}
+
+// SpendFeeAllowance - charges the fee to the allowance of the datanode, if any, at the block time. It fails
+// without charging when the fee goes over a cap, otherwise it returns the caps fully spent by the fee
+func (k DataNodeKeeper) SpendFeeAllowance(ctx sdk.Context, address sdk.AccAddress, fee sdk.Coins) ([]string, error) {
+	allowance, err := k.GetFeeAllowance(ctx, address)
+	if err != nil {
+		return nil, nil
+	}
+	if feeCap := allowance.ExceededCap(fee, ctx.BlockTime()); feeCap != "" {
+		return nil, sdkerrors.Wrapf(types.ErrFeeAllowanceExceeded, "%s cap of %s, fee %s", feeCap, address, fee)
+	}
+
+	// spending nothing resets the period spending when the period has ended
+	before := allowance.Spend(sdk.Coins{}, ctx.BlockTime())
+	after := allowance.Spend(fee, ctx.BlockTime())
+	k.SetFeeAllowance(ctx, after)
+
+	reached := make(map[string]bool)
+	for _, feeCap := range before.ReachedCaps() {
+		reached[feeCap] = true
+	}
+	var caps []string
+	for _, feeCap := range after.ReachedCaps() {
+		if !reached[feeCap] {
+			caps = append(caps, feeCap)
+		}
+	}
+	return caps, nil
+}
@@ -0,0 +1,157 @@
+package keeper
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Bandwidth bond methods
+
+// GetBandwidthBond - get the bandwidth bond of the owner
+func (k DataNodeKeeper) GetBandwidthBond(ctx sdk.Context, owner sdk.AccAddress) (*types.BandwidthBond, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.BandwidthBondKey(owner))
+	if bz == nil {
+		return nil, types.ErrNoBandwidthBond
+	}
+	var bond types.BandwidthBond
+	k.cdc.MustUnmarshalBinaryBare(bz, &bond)
+	return &bond, nil
+}
+
+// SetBandwidthBond - sets the bandwidth bond of the owner, an empty bond is removed. Its unbondings must be
+// queued apart with InsertBandwidthUnbondingQueue
+func (k DataNodeKeeper) SetBandwidthBond(ctx sdk.Context, bond types.BandwidthBond) {
+	store := ctx.KVStore(k.storeKey)
+	if bond.IsEmpty() {
+		store.Delete(types.BandwidthBondKey(bond.Owner))
+		return
+	}
+	store.Set(types.BandwidthBondKey(bond.Owner), k.cdc.MustMarshalBinaryBare(bond))
+}
+
+// InsertBandwidthUnbondingQueue - queues an unbonding of the owner bond at its completion time
+func (k DataNodeKeeper) InsertBandwidthUnbondingQueue(ctx sdk.Context, owner sdk.AccAddress, completion time.Time) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.BandwidthUnbondingKey(completion, owner), []byte{})
+}
+
+// IterateBandwidthBonds - iterate over all the bandwidth bonds
+func (k DataNodeKeeper) IterateBandwidthBonds(ctx sdk.Context, cb func(bond types.BandwidthBond) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.BandwidthBondPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var bond types.BandwidthBond
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &bond)
+		if cb(bond) {
+			break
+		}
+	}
+}
+
+// BondBandwidth - moves the coins from the owner to the module account, adding them to the bond of the owner
+func (k DataNodeKeeper) BondBandwidth(ctx sdk.Context, owner sdk.AccAddress, amount sdk.Coins) error {
+	if err := k.supplyKeeper.SendCoinsFromAccountToModule(ctx, owner, types.ModuleName, amount); err != nil {
+		return err
+	}
+	bond, err := k.GetBandwidthBond(ctx, owner)
+	if err != nil {
+		newBond := types.NewBandwidthBond(owner)
+		bond = &newBond
+	}
+	bond.Amount = bond.Amount.Add(amount...)
+	k.SetBandwidthBond(ctx, *bond)
+	return nil
+}
+
+// UnbondBandwidth - takes the coins out of the bond of the owner, they stop granting quota right away and
+// are returned to the owner after the unbonding time. It returns the completion time of the unbonding
+func (k DataNodeKeeper) UnbondBandwidth(ctx sdk.Context, owner sdk.AccAddress, amount sdk.Coins) (time.Time, error) {
+	bond, err := k.GetBandwidthBond(ctx, owner)
+	if err != nil {
+		return time.Time{}, err
+	}
+	left, hasNeg := bond.Amount.SafeSub(amount)
+	if hasNeg {
+		return time.Time{}, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "bonded %s, unbonding %s", bond.Amount, amount)
+	}
+
+	completion := ctx.BlockTime().Add(time.Duration(k.BandwidthUnbondingTime(ctx)) * time.Second)
+	bond.Amount = left
+	bond.Unbonding = append(bond.Unbonding, types.BandwidthUnbonding{Amount: amount, CompletionTime: completion})
+	k.SetBandwidthBond(ctx, *bond)
+	k.InsertBandwidthUnbondingQueue(ctx, owner, completion)
+	return completion, nil
+}
+
+// CompleteBandwidthUnbondings - returns the coins of the unbondings completed at the time to their owners,
+// it returns the bonds of the owners paid with the coins returned as amount
+func (k DataNodeKeeper) CompleteBandwidthUnbondings(ctx sdk.Context, now time.Time) []types.BandwidthBond {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.BandwidthUnbondingPrefix, sdk.PrefixEndBytes(types.BandwidthUnbondingTimePrefix(now)))
+
+	var keys [][]byte
+	owners := make(map[string]bool)
+	var matured []types.BandwidthBond
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		owner := types.SplitBandwidthUnbondingKey(iterator.Key())
+		if owners[owner.String()] {
+			continue
+		}
+		owners[owner.String()] = true
+		if bond, err := k.GetBandwidthBond(ctx, owner); err == nil {
+			matured = append(matured, *bond)
+		}
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+
+	// the unbondings left keep their entries on the queue
+	var paid []types.BandwidthBond
+	for _, bond := range matured {
+		amount, left := bond.Matured(now)
+		k.SetBandwidthBond(ctx, left)
+		if amount.Empty() {
+			continue
+		}
+		// the module account holds every coin bonded and unbonding
+		if err := k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, bond.Owner, amount); err != nil {
+			panic(err)
+		}
+		paid = append(paid, types.BandwidthBond{Owner: bond.Owner, Amount: amount})
+	}
+	return paid
+}
+
+// BandwidthQuota - returns the bond of the owner at the block time, a new one without coins if there's
+// none, with the records it can write without fees on the current period
+func (k DataNodeKeeper) BandwidthQuota(ctx sdk.Context, owner sdk.AccAddress) (types.BandwidthBond, int64) {
+	bond, err := k.GetBandwidthBond(ctx, owner)
+	if err != nil {
+		newBond := types.NewBandwidthBond(owner)
+		bond = &newBond
+	}
+	current := bond.Current(ctx.BlockTime(), k.BandwidthPeriod(ctx))
+	return current, current.Quota(k.BandwidthBondPerRecord(ctx))
+}
+
+// ConsumeBandwidth - uses the quota of the owner to write the records without fees at the block time, it
+// fails without using it when the records go over the quota left on the period
+func (k DataNodeKeeper) ConsumeBandwidth(ctx sdk.Context, owner sdk.AccAddress, records int64) error {
+	bond, quota := k.BandwidthQuota(ctx, owner)
+	if bond.Used+records > quota {
+		return sdkerrors.Wrapf(types.ErrBandwidthExceeded, "%s used %d of %d records, writing %d", owner, bond.Used, quota, records)
+	}
+	bond.Used += records
+	k.SetBandwidthBond(ctx, bond)
+	return nil
+}
@@ -0,0 +1,91 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// records of a full day reporting every second
+var benchmarkFills = []int{1000, 10000, 86400}
+
+var benchmarkChannel = types.NodeChannel{ID: "1", Variable: "temperature"}
+
+// legacyAddRecord - adds a record the way the daily datarecord layout did, loading the whole time frame,
+// scanning it for a duplicate timestamp and writing it back
+func legacyAddRecord(ctx sdk.Context, k DataNodeKeeper, hash types.DataRecordHash, record legacyRecord) {
+	store := ctx.KVStore(k.storeKey)
+	var dataRecord legacyDataRecord
+	k.cdc.MustUnmarshalBinaryBare(store.Get(types.DataRecordKey(hash)), &dataRecord)
+	for _, r := range dataRecord.Records {
+		if r.TimeStamp == record.TimeStamp {
+			return
+		}
+	}
+	dataRecord.Records = append(dataRecord.Records, record)
+	store.Set(types.DataRecordKey(hash), k.cdc.MustMarshalBinaryBare(dataRecord))
+}
+
+func setupBenchmark(b *testing.B) (sdk.Context, DataNodeKeeper, time.Time) {
+	start := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(b, start)
+	k.SetDataNodeOwner(ctx, testDataNode, testOwner)
+	require.NoError(b, k.ChangeChannel(ctx, testDataNode, benchmarkChannel))
+	return ctx, k, start
+}
+
+func reportGas(b *testing.B, ctx sdk.Context) {
+	b.ReportMetric(float64(ctx.GasMeter().GasConsumed())/float64(b.N), "gas/op")
+}
+
+func BenchmarkAddRecordLegacyLayout(b *testing.B) {
+	for _, fill := range benchmarkFills {
+		b.Run(fmt.Sprintf("fill=%d", fill), func(b *testing.B) {
+			ctx, k, start := setupBenchmark(b)
+
+			timeFrame := types.GetTimeFrame(start.Unix(), types.DefaultFrameSize)
+			hash := types.GetDataRecordHash(testDataNode, &benchmarkChannel, timeFrame)
+			dataRecord := legacyDataRecord{DataNode: testDataNode, NodeChannel: benchmarkChannel, TimeFrame: timeFrame}
+			for i := 0; i < fill; i++ {
+				dataRecord.Records = append(dataRecord.Records, legacyRecord{TimeStamp: uint32(start.Unix()) + uint32(i), Value: uint32(i)})
+			}
+			ctx.KVStore(k.storeKey).Set(types.DataRecordKey(hash), k.cdc.MustMarshalBinaryBare(dataRecord))
+
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				legacyAddRecord(ctx, k, hash, legacyRecord{TimeStamp: uint32(start.Unix()) + uint32(fill+i), Value: uint32(i)})
+			}
+			b.StopTimer()
+			reportGas(b, ctx)
+		})
+	}
+}
+
+func BenchmarkAddRecord(b *testing.B) {
+	for _, fill := range benchmarkFills {
+		b.Run(fmt.Sprintf("fill=%d", fill), func(b *testing.B) {
+			ctx, k, start := setupBenchmark(b)
+
+			for i := 0; i < fill; i++ {
+				k.SetRecord(ctx, testDataNode, benchmarkChannel.ID, types.Record{TimeStamp: (start.Unix() + int64(i)) * types.MillisPerSecond, Value: int64(i)})
+			}
+
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := k.AddRecord(ctx, testDataNode, benchmarkChannel.ID, types.Record{TimeStamp: (start.Unix() + int64(fill+i)) * types.MillisPerSecond, Value: int64(i)})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			reportGas(b, ctx)
+		})
+	}
+}
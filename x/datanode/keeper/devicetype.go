@@ -0,0 +1,174 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Device type methods
+
+// GetDeviceType - get the version of the device type, the latest one when version is 0
+func (k DataNodeKeeper) GetDeviceType(ctx sdk.Context, id string, version uint32) (*types.DeviceType, error) {
+	if version == 0 {
+		return k.GetLatestDeviceType(ctx, id)
+	}
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.DeviceTypeKey(id, version))
+	if bz == nil {
+		return nil, types.ErrInvalidDeviceType
+	}
+	var deviceType types.DeviceType
+	k.cdc.MustUnmarshalBinaryBare(bz, &deviceType)
+	return &deviceType, nil
+}
+
+// GetLatestDeviceType - get the latest version of the device type
+func (k DataNodeKeeper) GetLatestDeviceType(ctx sdk.Context, id string) (*types.DeviceType, error) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStoreReversePrefixIterator(store, types.DeviceTypeVersionsPrefix(id))
+	defer iterator.Close()
+
+	if !iterator.Valid() {
+		return nil, types.ErrInvalidDeviceType
+	}
+	var deviceType types.DeviceType
+	k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &deviceType)
+	return &deviceType, nil
+}
+
+// SetDeviceType - sets the version of the device type
+func (k DataNodeKeeper) SetDeviceType(ctx sdk.Context, deviceType types.DeviceType) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.DeviceTypeKey(deviceType.ID, deviceType.Version), k.cdc.MustMarshalBinaryBare(deviceType))
+}
+
+// TransferDeviceType - sets the new owner on every version of the device type
+func (k DataNodeKeeper) TransferDeviceType(ctx sdk.Context, id string, newOwner sdk.AccAddress) {
+	var deviceTypes []types.DeviceType
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.DeviceTypeVersionsPrefix(id))
+	for ; iterator.Valid(); iterator.Next() {
+		var deviceType types.DeviceType
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &deviceType)
+		deviceTypes = append(deviceTypes, deviceType)
+	}
+	iterator.Close()
+
+	for _, deviceType := range deviceTypes {
+		deviceType.Owner = newOwner
+		k.SetDeviceType(ctx, deviceType)
+	}
+}
+
+// IterateDeviceTypes - iterate over all the versions of all the device types, sorted by id length, id
+// and version
+func (k DataNodeKeeper) IterateDeviceTypes(ctx sdk.Context, cb func(deviceType types.DeviceType) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.DeviceTypePrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var deviceType types.DeviceType
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &deviceType)
+		if cb(deviceType) {
+			break
+		}
+	}
+}
+
+// GetDeviceTypes - get the latest version of up to limit device types sorted by id length and id starting
+// from start (inclusive, empty for the first one). When the limit is reached, the id of the first device
+// type left out is returned to be used as start on the next call, otherwise it returns an empty id
+func (k DataNodeKeeper) GetDeviceTypes(ctx sdk.Context, start string, limit int) ([]types.DeviceType, string) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.DeviceTypeVersionsPrefix(start), sdk.PrefixEndBytes(types.DeviceTypePrefix))
+	defer iterator.Close()
+
+	// versions of a device type are contiguous, the last one seen is the latest
+	deviceTypes := []types.DeviceType{}
+	for ; iterator.Valid(); iterator.Next() {
+		var deviceType types.DeviceType
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &deviceType)
+		if n := len(deviceTypes); n > 0 && deviceTypes[n-1].ID == deviceType.ID {
+			deviceTypes[n-1] = deviceType
+			continue
+		}
+		if len(deviceTypes) == limit {
+			return deviceTypes, deviceType.ID
+		}
+		deviceTypes = append(deviceTypes, deviceType)
+	}
+	return deviceTypes, ""
+}
+
+// GetDeviceTypeNodes - get up to limit datanodes linked to the device type sorted by address starting from
+// start (inclusive, nil for the first one). When the limit is reached, the address of the first datanode
+// left out is returned to be used as start on the next call, otherwise it returns nil
+func (k DataNodeKeeper) GetDeviceTypeNodes(ctx sdk.Context, id string, start sdk.AccAddress, limit int) ([]types.DataNode, sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.DeviceTypeNodeKey(id, start), sdk.PrefixEndBytes(types.DeviceTypeNodesPrefix(id)))
+	defer iterator.Close()
+
+	dataNodes := []types.DataNode{}
+	for ; iterator.Valid(); iterator.Next() {
+		_, address := types.SplitDeviceTypeNodeKey(iterator.Key())
+		if len(dataNodes) == limit {
+			return dataNodes, address
+		}
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil {
+			continue
+		}
+		dataNodes = append(dataNodes, *dataNode)
+	}
+	return dataNodes, nil
+}
+
+// LinkDeviceType - links the datanode to the device type version and migrates its channels, the channels
+// of the device type it was linked to that are not on the new version are removed and the channels of
+// the new version are set. Channels not defined by device types are kept. A nil device type unlinks
+// the datanode keeping its channels
+func (k DataNodeKeeper) LinkDeviceType(ctx sdk.Context, address sdk.AccAddress, deviceType *types.DeviceType) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	if deviceType == nil {
+		dataNode.DeviceType = ""
+		dataNode.TypeVersion = 0
+		k.SetDataNode(ctx, address, dataNode)
+		return nil
+	}
+
+	if dataNode.DeviceType != "" {
+		if previous, err := k.GetDeviceType(ctx, dataNode.DeviceType, dataNode.TypeVersion); err == nil {
+			channels := []types.NodeChannel{}
+			for _, ch := range dataNode.Channels {
+				if !previous.HasChannelID(ch.ID) || deviceType.HasChannelID(ch.ID) {
+					channels = append(channels, ch)
+				}
+			}
+			dataNode.Channels = channels
+		}
+	}
+
+	for _, channel := range deviceType.Channels {
+		replaced := false
+		for i, ch := range dataNode.Channels {
+			if ch.ID == channel.ID {
+				dataNode.Channels[i] = channel
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dataNode.Channels = append(dataNode.Channels, channel)
+		}
+	}
+
+	dataNode.DeviceType = deviceType.ID
+	dataNode.TypeVersion = deviceType.Version
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
@@ -0,0 +1,132 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Fleet methods
+
+// GetFleet - get the fleet with the id
+func (k DataNodeKeeper) GetFleet(ctx sdk.Context, id string) (*types.Fleet, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.FleetKey(id))
+	if bz == nil {
+		return nil, types.ErrInvalidFleet
+	}
+	var fleet types.Fleet
+	k.cdc.MustUnmarshalBinaryBare(bz, &fleet)
+	return &fleet, nil
+}
+
+// SetFleet - sets the fleet
+func (k DataNodeKeeper) SetFleet(ctx sdk.Context, fleet types.Fleet) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.FleetKey(fleet.ID), k.cdc.MustMarshalBinaryBare(fleet))
+}
+
+// IsFleetPresent - check if the fleet is present in the store or not
+func (k DataNodeKeeper) IsFleetPresent(ctx sdk.Context, id string) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(types.FleetKey(id))
+}
+
+// IterateFleets - iterate over all the fleets
+func (k DataNodeKeeper) IterateFleets(ctx sdk.Context, cb func(fleet types.Fleet) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.FleetPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var fleet types.Fleet
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &fleet)
+		if cb(fleet) {
+			break
+		}
+	}
+}
+
+// SetFleetMember - makes the datanode member of the fleet, leaving its previous fleet if any. An empty
+// fleet id removes the datanode from its fleet
+func (k DataNodeKeeper) SetFleetMember(ctx sdk.Context, id string, address sdk.AccAddress) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+	dataNode.Fleet = id
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
+
+// IterateFleetMembers - iterate over the addresses of the member datanodes of the fleet
+func (k DataNodeKeeper) IterateFleetMembers(ctx sdk.Context, id string, cb func(address sdk.AccAddress) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.FleetMembersPrefix(id))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		_, address := types.SplitFleetMemberKey(iterator.Key())
+		if cb(address) {
+			break
+		}
+	}
+}
+
+// GetFleetMembers - get up to limit member datanodes of the fleet sorted by address starting from start
+// (inclusive, nil for the first one). When the limit is reached, the address of the first datanode left
+// out is returned to be used as start on the next call, otherwise it returns nil
+func (k DataNodeKeeper) GetFleetMembers(ctx sdk.Context, id string, start sdk.AccAddress, limit int) ([]types.DataNode, sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.FleetMemberKey(id, start), sdk.PrefixEndBytes(types.FleetMembersPrefix(id)))
+	defer iterator.Close()
+
+	dataNodes := []types.DataNode{}
+	for ; iterator.Valid(); iterator.Next() {
+		_, address := types.SplitFleetMemberKey(iterator.Key())
+		if len(dataNodes) == limit {
+			return dataNodes, address
+		}
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil {
+			continue
+		}
+		dataNodes = append(dataNodes, *dataNode)
+	}
+	return dataNodes, nil
+}
+
+// ApplyChannelTemplate - sets the channels of the template on the datanode, other channels are kept
+func (k DataNodeKeeper) ApplyChannelTemplate(ctx sdk.Context, address sdk.AccAddress, template []types.NodeChannel) error {
+	for _, channel := range template {
+		if err := k.ChangeChannel(ctx, address, channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFeePayer - get the account paying the fees of the datanode: its own fee payer when set, otherwise
+// the fee payer of its fleet when set, otherwise the owner
+func (k DataNodeKeeper) GetFeePayer(ctx sdk.Context, dataNode types.DataNode) sdk.AccAddress {
+	if !dataNode.FeePayer.Empty() {
+		return dataNode.FeePayer
+	}
+	if dataNode.Fleet == "" {
+		return dataNode.Owner
+	}
+	fleet, err := k.GetFleet(ctx, dataNode.Fleet)
+	if err != nil || fleet.FeePayer.Empty() {
+		return dataNode.Owner
+	}
+	return fleet.FeePayer
+}
+
+// GetFeePayers - get the accounts that can pay the fees of the datanode in order of preference, the fee
+// payer and then the owner when they are different
+func (k DataNodeKeeper) GetFeePayers(ctx sdk.Context, dataNode types.DataNode) []sdk.AccAddress {
+	feePayer := k.GetFeePayer(ctx, dataNode)
+	if feePayer.Equals(dataNode.Owner) {
+		return []sdk.AccAddress{feePayer}
+	}
+	return []sdk.AccAddress{feePayer, dataNode.Owner}
+}
@@ -0,0 +1,87 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// RegisterInvariants registers the datanode module invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k DataNodeKeeper) {
+	ir.RegisterRoute(types.ModuleName, "storage-deposits-pool", StorageDepositsPoolInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "storage-deposits-bytes", StorageDepositsBytesInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "storage-deposits-depositors", StorageDepositsDepositorsInvariant(k))
+}
+
+// StorageDepositsPoolInvariant checks that the storage deposit pool holds the coins of every storage deposit
+func StorageDepositsPoolInvariant(k DataNodeKeeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		deposited := sdk.Coins{}
+		k.IterateStorageDeposits(ctx, func(deposit types.StorageDeposit) bool {
+			deposited = deposited.Add(deposit.Amount...)
+			return false
+		})
+		pool := k.bankKeeper.GetCoins(ctx, k.supplyKeeper.GetModuleAddress(types.StorageDepositPoolName))
+		broken := !pool.IsEqual(deposited)
+
+		return sdk.FormatInvariant(types.ModuleName, "storage-deposits-pool",
+			fmt.Sprintf("\tpool coins: %s\n\tsum of deposits: %s\n", pool, deposited)), broken
+	}
+}
+
+// StorageDepositsBytesInvariant checks that the storage deposit of every datanode accounts the bytes taken
+// by its records on the store
+func StorageDepositsBytesInvariant(k DataNodeKeeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		stored := k.storedBytes(ctx)
+		storedByAddress := make(map[string]int64)
+		for _, bytes := range stored {
+			storedByAddress[bytes.DataNode.String()] = bytes.Bytes
+		}
+
+		var msg string
+		var count int
+		deposited := make(map[string]bool)
+		k.IterateStorageDeposits(ctx, func(deposit types.StorageDeposit) bool {
+			deposited[deposit.DataNode.String()] = true
+			if bytes := storedByAddress[deposit.DataNode.String()]; bytes != deposit.Bytes {
+				count++
+				msg += fmt.Sprintf("\t%s deposit for %d bytes, storing %d bytes\n", deposit.DataNode, deposit.Bytes, bytes)
+			}
+			return false
+		})
+		for _, bytes := range stored {
+			if !deposited[bytes.DataNode.String()] {
+				count++
+				msg += fmt.Sprintf("\t%s without deposit, storing %d bytes\n", bytes.DataNode, bytes.Bytes)
+			}
+		}
+
+		return sdk.FormatInvariant(types.ModuleName, "storage-deposits-bytes",
+			fmt.Sprintf("amount of datanodes with unaccounted bytes found %d\n%s", count, msg)), count != 0
+	}
+}
+
+// StorageDepositsDepositorsInvariant checks that the shares of the depositors of every storage deposit don't
+// exceed its coins
+func StorageDepositsDepositorsInvariant(k DataNodeKeeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var msg string
+		var count int
+		k.IterateStorageDeposits(ctx, func(deposit types.StorageDeposit) bool {
+			deposited := sdk.Coins{}
+			for _, depositor := range deposit.Depositors {
+				deposited = deposited.Add(depositor.Amount...)
+			}
+			if !deposit.Amount.IsAllGTE(deposited) {
+				count++
+				msg += fmt.Sprintf("\t%s deposit of %s, depositors shares of %s\n", deposit.DataNode, deposit.Amount, deposited)
+			}
+			return false
+		})
+
+		return sdk.FormatInvariant(types.ModuleName, "storage-deposits-depositors",
+			fmt.Sprintf("amount of deposits with depositors over their coins found %d\n%s", count, msg)), count != 0
+	}
+}
@@ -8,15 +8,22 @@ import (
 
 // DataNodeKeeper - keeper of the datanode store
 type DataNodeKeeper struct {
-	storeKey sdk.StoreKey
-	cdc      *codec.Codec
+	storeKey     sdk.StoreKey
+	cdc          *codec.Codec
+	paramspace   types.ParamSubspace
+	supplyKeeper types.SupplyKeeper
+	bankKeeper   types.BankKeeper
 }
 
-// NewKeeper - creates a datanode keeper
-func NewKeeper(cdc *codec.Codec, key sdk.StoreKey) DataNodeKeeper {
+// NewKeeper - creates a datanode keeper, the coins bonded by the owners are held by the module account
+// of the supply keeper and the storage deposits are moved to the storage deposit pool by the bank keeper
+func NewKeeper(cdc *codec.Codec, key sdk.StoreKey, paramspace types.ParamSubspace, supplyKeeper types.SupplyKeeper, bankKeeper types.BankKeeper) DataNodeKeeper {
 	keeper := DataNodeKeeper{
-		storeKey: key,
-		cdc:      cdc,
+		storeKey:     key,
+		cdc:          cdc,
+		paramspace:   paramspace.WithKeyTable(types.ParamKeyTable()),
+		supplyKeeper: supplyKeeper,
+		bankKeeper:   bankKeeper,
 	}
 	return keeper
 }
@@ -29,7 +36,7 @@ func (k DataNodeKeeper) GetDataNode(ctx sdk.Context, address sdk.AccAddress) (*t
 	if !k.IsDataNodePresent(ctx, address) {
 		return nil, types.ErrInvalidDataNode
 	}
-	bz := store.Get([]byte(address))
+	bz := store.Get(types.DataNodeKey(address))
 	var dataNode types.DataNode
 	k.cdc.MustUnmarshalBinaryBare(bz, &dataNode)
 	return &dataNode, nil
@@ -46,27 +53,126 @@ func (k DataNodeKeeper) SetDataNode(ctx sdk.Context, address sdk.AccAddress, dat
 	}
 
 	store := ctx.KVStore(k.storeKey)
-	store.Set([]byte(address), k.cdc.MustMarshalBinaryBare(dataNode))
+
+	// keep the owner, fleet members and device type indexes and the retention queue in sync
+	if previous, err := k.GetDataNode(ctx, address); err == nil {
+		k.requeueChannels(ctx, *previous, *dataNode)
+		if !previous.Owner.Equals(dataNode.Owner) {
+			k.deleteOwnerIndex(ctx, previous.Owner, address)
+		}
+		if previous.Fleet != "" && previous.Fleet != dataNode.Fleet {
+			store.Delete(types.FleetMemberKey(previous.Fleet, address))
+		}
+		if previous.DeviceType != "" && previous.DeviceType != dataNode.DeviceType {
+			store.Delete(types.DeviceTypeNodeKey(previous.DeviceType, address))
+		}
+	}
+
+	store.Set(types.DataNodeKey(address), k.cdc.MustMarshalBinaryBare(dataNode))
+	store.Set(types.OwnerDataNodeKey(dataNode.Owner, address), []byte{})
+	if dataNode.Fleet != "" {
+		store.Set(types.FleetMemberKey(dataNode.Fleet, address), []byte{})
+	}
+	if dataNode.DeviceType != "" {
+		store.Set(types.DeviceTypeNodeKey(dataNode.DeviceType, address), []byte{})
+	}
 }
 
-// DeleteDataNode - Deletes the entire metadata struct for an address and all related datarecords
-func (k DataNodeKeeper) DeleteDataNode(ctx sdk.Context, address sdk.AccAddress) {
+// DeleteDataNode - Deletes the entire metadata struct for an address and all related datarecords, the
+// storage deposit of the records is refunded to its depositors
+func (k DataNodeKeeper) DeleteDataNode(ctx sdk.Context, address sdk.AccAddress) types.StorageRefund {
 	store := ctx.KVStore(k.storeKey)
 	dataNode, err := k.GetDataNode(ctx, address)
 	if err != nil {
-		return
+		return types.StorageRefund{}
 	}
 
-	for _, hash := range dataNode.Records {
-		store.Delete(hash[:])
+	var keys [][]byte
+	var bytes int64
+	// the retention queue entries of the datanode are dropped once reached
+	for _, prefix := range [][]byte{types.RecordDataNodePrefix(dataNode.ID), types.TimeFrameDataNodePrefix(dataNode.ID), types.RollupDataNodePrefix(dataNode.ID)} {
+		iterator := sdk.KVStorePrefixIterator(store, prefix)
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, append([]byte{}, iterator.Key()...))
+			if prefix[0] == types.RecordPrefix[0] {
+				bytes += recordBytes(iterator.Key(), iterator.Value())
+			}
+		}
+		iterator.Close()
 	}
-	store.Delete([]byte(address))
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+	k.deleteOwnerIndex(ctx, dataNode.Owner, address)
+	if dataNode.Fleet != "" {
+		store.Delete(types.FleetMemberKey(dataNode.Fleet, address))
+	}
+	if dataNode.DeviceType != "" {
+		store.Delete(types.DeviceTypeNodeKey(dataNode.DeviceType, address))
+	}
+	k.DeleteOwnershipOffer(ctx, address)
+	k.DeleteRoleGrants(ctx, address)
+	k.DeleteFeeAllowance(ctx, address)
+	// refund the deposit before the fee payer of the coins without depositor is gone with the datanode
+	refund := k.releaseStorage(ctx, *dataNode, bytes)
+	store.Delete(types.DataNodeKey(address))
+	return refund
+}
+
+// deleteOwnerIndex - removes the datanode from the owner index
+func (k DataNodeKeeper) deleteOwnerIndex(ctx sdk.Context, owner sdk.AccAddress, address sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.OwnerDataNodeKey(owner, address))
+}
+
+// GetDataNodes - get up to limit datanodes sorted by address starting from start (inclusive, nil for
+// the first one). When the limit is reached, the address of the first datanode left out is returned to
+// be used as start on the next call, otherwise it returns nil
+func (k DataNodeKeeper) GetDataNodes(ctx sdk.Context, start sdk.AccAddress, limit int) ([]types.DataNode, sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.DataNodeKey(start), sdk.PrefixEndBytes(types.DataNodePrefix))
+	defer iterator.Close()
+
+	dataNodes := []types.DataNode{}
+	for ; iterator.Valid(); iterator.Next() {
+		var dataNode types.DataNode
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &dataNode)
+		if len(dataNodes) == limit {
+			return dataNodes, dataNode.ID
+		}
+		dataNodes = append(dataNodes, dataNode)
+	}
+	return dataNodes, nil
+}
+
+// GetDataNodesByOwner - get up to limit datanodes of the owner sorted by address starting from start
+// (inclusive, nil for the first one). When the limit is reached, the address of the first datanode left
+// out is returned to be used as start on the next call, otherwise it returns nil
+func (k DataNodeKeeper) GetDataNodesByOwner(ctx sdk.Context, owner sdk.AccAddress, start sdk.AccAddress, limit int) ([]types.DataNode, sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.OwnerDataNodeKey(owner, start), sdk.PrefixEndBytes(types.OwnerDataNodesPrefix(owner)))
+	defer iterator.Close()
+
+	dataNodes := []types.DataNode{}
+	for ; iterator.Valid(); iterator.Next() {
+		_, address := types.SplitOwnerDataNodeKey(iterator.Key())
+		if len(dataNodes) == limit {
+			return dataNodes, address
+		}
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil {
+			continue
+		}
+		dataNodes = append(dataNodes, *dataNode)
+	}
+	return dataNodes, nil
 }
 
 // IsDataNodePresent - check if the datanode is present in the store or not
 func (k DataNodeKeeper) IsDataNodePresent(ctx sdk.Context, address sdk.AccAddress) bool {
 	store := ctx.KVStore(k.storeKey)
-	return store.Has([]byte(address))
+	return store.Has(types.DataNodeKey(address))
 }
 
 // GetChannels - get the channels of the datanode
@@ -92,26 +198,6 @@ func (k DataNodeKeeper) GetChannel(ctx sdk.Context, address sdk.AccAddress, id s
 	return nil, types.ErrInvalidDataNodeChannel
 }
 
-// GetRecordHashes - get the datarecord hashes belonging to the datanode
-func (k DataNodeKeeper) GetRecordHashes(ctx sdk.Context, address sdk.AccAddress) (*[]types.DataRecordHash, error) {
-	datanode, err := k.GetDataNode(ctx, address)
-	if err != nil {
-		return nil, err
-	}
-	return &datanode.Records, nil
-}
-
-// AddRecordHash - add a recordhash to the datanode
-func (k DataNodeKeeper) AddRecordHash(ctx sdk.Context, address sdk.AccAddress, hash types.DataRecordHash) error {
-	datanode, err := k.GetDataNode(ctx, address)
-	if err != nil {
-		return err
-	}
-	datanode.Records = append(datanode.Records, hash)
-	k.SetDataNode(ctx, address, datanode)
-	return nil
-}
-
 // AddChannel - add a new channel to the datanode
 func (k DataNodeKeeper) AddChannel(ctx sdk.Context, address sdk.AccAddress, channel types.NodeChannel) error {
 	datanode, err := k.GetDataNode(ctx, address)
@@ -123,16 +209,16 @@ func (k DataNodeKeeper) AddChannel(ctx sdk.Context, address sdk.AccAddress, chan
 	return nil
 }
 
-// ChangeChannel - change a channel on the datanode
+// ChangeChannel - replaces the channel with the same id on the datanode or adds it if not present
 func (k DataNodeKeeper) ChangeChannel(ctx sdk.Context, address sdk.AccAddress, channel types.NodeChannel) error {
 	datanode, err := k.GetDataNode(ctx, address)
 	modified := false
 	if err != nil {
 		return err
 	}
-	for _, c := range datanode.Channels {
+	for i, c := range datanode.Channels {
 		if c.ID == channel.ID {
-			c.Variable = channel.Variable
+			datanode.Channels[i] = channel
 			modified = true
 			break
 		}
@@ -162,60 +248,172 @@ func (k DataNodeKeeper) DeleteChannel(ctx sdk.Context, address sdk.AccAddress, c
 	return nil
 }
 
-// SetDataNodeOwner - change the owner of the datanode
+// SetDataNodeOwner - change the owner of the datanode, a pending ownership offer, the role grants
+// and the fleet membership are dropped when the owner changes
 func (k DataNodeKeeper) SetDataNodeOwner(ctx sdk.Context, address sdk.AccAddress, owner sdk.AccAddress) {
 	dataNode, err := k.GetDataNode(ctx, address)
 	if err != nil {
 		newDataNode := types.NewDataNode(address, owner)
 		dataNode = &newDataNode
 	} else {
+		if !dataNode.Owner.Equals(owner) {
+			// the new owner pays the writes, previous roles and fee caps are not carried over
+			k.DeleteOwnershipOffer(ctx, address)
+			k.DeleteRoleGrants(ctx, address)
+			k.DeleteFeeAllowance(ctx, address)
+			// the new owner didn't agree on joining the fleet nor the fee payer on paying for them
+			dataNode.Fleet = ""
+			dataNode.FeePayer = nil
+		}
 		dataNode.Owner = owner
 	}
 	k.SetDataNode(ctx, address, dataNode)
 }
 
-// DataRecord methods
+// ArchiveDataNode - archive the datanode, its records are kept but no new ones are accepted
+func (k DataNodeKeeper) ArchiveDataNode(ctx sdk.Context, address sdk.AccAddress) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+	dataNode.Archived = true
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
+
+// SetDataNodeBackfill - enables or disables the backfill mode of the datanode
+func (k DataNodeKeeper) SetDataNodeBackfill(ctx sdk.Context, address sdk.AccAddress, enabled bool) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+	dataNode.Backfill = enabled
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
 
-// GetDataRecord - gets the datarecord from the KVStore
-func (k DataNodeKeeper) GetDataRecord(ctx sdk.Context, hash types.DataRecordHash) (*types.DataRecord, error) {
+// SetDataNodeFeePayer - sets the account paying the fees of the datanode, empty to remove it
+func (k DataNodeKeeper) SetDataNodeFeePayer(ctx sdk.Context, address sdk.AccAddress, feePayer sdk.AccAddress) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+	dataNode.FeePayer = feePayer
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
+
+// SetDataNodeName - change the name of the datanode, an empty name resets it to the address
+func (k DataNodeKeeper) SetDataNodeName(ctx sdk.Context, address sdk.AccAddress, name string) error {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = address.String()
+	}
+	dataNode.Name = name
+	k.SetDataNode(ctx, address, dataNode)
+	return nil
+}
+
+// Record methods
+
+// HasRecord - check if the datanode channel has a record at the timestamp in milliseconds
+func (k DataNodeKeeper) HasRecord(ctx sdk.Context, address sdk.AccAddress, channelID string, timeStamp int64) bool {
 	store := ctx.KVStore(k.storeKey)
-	if !k.IsDataRecordPresent(ctx, hash) {
+	return store.Has(types.RecordKey(address, channelID, uint64(timeStamp)))
+}
+
+// SetRecord - sets a single record of the datanode channel and indexes its time frame, queued for pruning
+// when new. The bytes it takes are added to the storage deposit of the datanode
+func (k DataNodeKeeper) SetRecord(ctx sdk.Context, address sdk.AccAddress, channelID string, record types.Record) {
+	store := ctx.KVStore(k.storeKey)
+	key := types.RecordKey(address, channelID, uint64(record.TimeStamp))
+	value := k.cdc.MustMarshalBinaryBare(record)
+	bytes := recordBytes(key, value)
+	if previous := store.Get(key); previous != nil {
+		bytes -= recordBytes(key, previous)
+	}
+	store.Set(key, value)
+	k.addStoredBytes(ctx, address, bytes)
+
+	timeFrame := types.GetTimeFrame(record.Unix(), k.FrameSize(ctx))
+	timeFrameKey := types.TimeFrameKey(address, channelID, uint64(timeFrame))
+	if !store.Has(timeFrameKey) {
+		store.Set(timeFrameKey, []byte{})
+		if dataNode, err := k.GetDataNode(ctx, address); err == nil {
+			k.enqueueTimeFrame(ctx, address, channelID, timeFrame, k.ChannelRetention(ctx, *dataNode, channelID))
+		}
+	}
+}
+
+// IterateRecords - iterates over the records of the datanode channel between from and to timestamps in
+// milliseconds (both inclusive) sorted by timestamp until cb returns true
+func (k DataNodeKeeper) IterateRecords(ctx sdk.Context, address sdk.AccAddress, channelID string, from int64, to int64, cb func(record types.Record) (stop bool)) {
+	if from < 0 {
+		from = 0
+	}
+	if to < from {
+		return
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(
+		types.RecordKey(address, channelID, uint64(from)),
+		types.RecordKey(address, channelID, uint64(to)+1),
+	)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var record types.Record
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &record)
+		if cb(record) {
+			break
+		}
+	}
+}
+
+// DataRecord methods
+
+// GetDataRecord - gets the records of the datanode channel time frame
+func (k DataNodeKeeper) GetDataRecord(ctx sdk.Context, address sdk.AccAddress, channel *types.NodeChannel, timeFrame int64) (*types.DataRecord, error) {
+	frameSize := k.FrameSize(ctx)
+	dataRecord := types.NewDataRecord(address, channel, timeFrame)
+	from := timeFrame * frameSize * types.MillisPerSecond
+	to := (timeFrame+1)*frameSize*types.MillisPerSecond - 1
+	k.IterateRecords(ctx, address, channel.ID, from, to, func(record types.Record) bool {
+		dataRecord.Records = append(dataRecord.Records, record)
+		return false
+	})
+
+	if len(dataRecord.Records) == 0 {
 		return nil, types.ErrInvalidDataRecord
 	}
-	bz := store.Get(hash[:])
-	var dataRecord types.DataRecord
-	k.cdc.MustUnmarshalBinaryBare(bz, &dataRecord)
 	return &dataRecord, nil
 }
 
-// SetDataRecord - sets the datarecord on the KVStore
+// SetDataRecord - sets every record of the datarecord time frame
 func (k DataNodeKeeper) SetDataRecord(ctx sdk.Context, dataRecord *types.DataRecord) {
 	if dataRecord.DataNode.Empty() || len(dataRecord.NodeChannel.ID) == 0 {
 		return
 	}
 
-	hash := types.GetDataRecordHash(dataRecord.DataNode, &dataRecord.NodeChannel, dataRecord.TimeFrame)
-
-	store := ctx.KVStore(k.storeKey)
-	store.Set(hash[:], k.cdc.MustMarshalBinaryBare(dataRecord))
-}
-
-// IsDataRecordPresent - check if the datarecord is present in the store or not
-func (k DataNodeKeeper) IsDataRecordPresent(ctx sdk.Context, hash types.DataRecordHash) bool {
-	store := ctx.KVStore(k.storeKey)
-	return store.Has(hash[:])
+	for _, record := range dataRecord.Records {
+		k.SetRecord(ctx, dataRecord.DataNode, dataRecord.NodeChannel.ID, record)
+	}
 }
 
-// GetLastRecords - get the latest time frame records
+// GetLastRecords - get the records of the time frame containing the block time
 func (k DataNodeKeeper) GetLastRecords(ctx sdk.Context, address sdk.AccAddress, channelID string) (*[]types.Record, error) {
 	channel, err := k.GetChannel(ctx, address, channelID)
 	if err != nil {
 		return nil, err
 	}
 
-	hash := types.GetActualDataRecordHash(address, channel)
+	timeFrame := types.GetTimeFrame(ctx.BlockTime().Unix(), k.FrameSize(ctx))
 
-	dataRecord, err := k.GetDataRecord(ctx, hash)
+	dataRecord, err := k.GetDataRecord(ctx, address, channel, timeFrame)
 	if err != nil {
 		return nil, err
 	}
@@ -223,16 +421,19 @@ func (k DataNodeKeeper) GetLastRecords(ctx sdk.Context, address sdk.AccAddress,
 	return &dataRecord.Records, nil
 }
 
-// GetRecords - get records from the date time frame
-func (k DataNodeKeeper) GetRecords(ctx sdk.Context, address sdk.AccAddress, channelID string, date int64) (*[]types.Record, error) {
+// GetRecords - get records from the time frame containing the date expressed in the time unit
+func (k DataNodeKeeper) GetRecords(ctx sdk.Context, address sdk.AccAddress, channelID string, date int64, unit types.TimeUnit) (*[]types.Record, error) {
 	channel, err := k.GetChannel(ctx, address, channelID)
 	if err != nil {
 		return nil, err
 	}
 
-	hash := types.GetDataRecordHash(address, channel, date)
+	timeFrame, err := unit.TimeFrame(date, k.FrameSize(ctx))
+	if err != nil {
+		return nil, err
+	}
 
-	dataRecord, err := k.GetDataRecord(ctx, hash)
+	dataRecord, err := k.GetDataRecord(ctx, address, channel, timeFrame)
 	if err != nil {
 		return nil, err
 	}
@@ -240,55 +441,156 @@ func (k DataNodeKeeper) GetRecords(ctx sdk.Context, address sdk.AccAddress, chan
 	return &dataRecord.Records, nil
 }
 
-// AddRecord - add a new record to the time frame
-func (k DataNodeKeeper) AddRecord(ctx sdk.Context, address sdk.AccAddress, channelID string, date int64, record types.Record) error {
-	channel, err := k.GetChannel(ctx, address, channelID)
+// GetRecordsRange - get up to limit records between from and to timestamps in milliseconds (both inclusive)
+// sorted by timestamp. When the limit is reached, the timestamp of the first record left out is returned
+// to be used as from on the next call, otherwise it returns 0
+func (k DataNodeKeeper) GetRecordsRange(ctx sdk.Context, address sdk.AccAddress, channelID string, from int64, to int64, limit int) ([]types.Record, int64, error) {
+	if _, err := k.GetChannel(ctx, address, channelID); err != nil {
+		return nil, 0, err
+	}
+
+	var next int64
+	records := []types.Record{}
+	k.IterateRecords(ctx, address, channelID, from, to, func(record types.Record) bool {
+		if len(records) == limit {
+			next = record.TimeStamp
+			return true
+		}
+		records = append(records, record)
+		return false
+	})
+	return records, next, nil
+}
+
+// AddRecord - add a new record to the datanode channel, it fails if there's a record at the same timestamp
+func (k DataNodeKeeper) AddRecord(ctx sdk.Context, address sdk.AccAddress, channelID string, record types.Record) error {
+	dataNode, err := k.GetDataNode(ctx, address)
 	if err != nil {
 		return err
 	}
+	if dataNode.Archived {
+		return types.ErrDataNodeArchived
+	}
+	if !dataNode.HasChannelID(channelID) {
+		return types.ErrInvalidDataNodeChannel
+	}
+
+	if k.HasRecord(ctx, address, channelID, record.TimeStamp) {
+		return types.ErrDuplicateRecord
+	}
 
-	hash := types.GetDataRecordHash(address, channel, date)
+	k.SetRecord(ctx, address, channelID, record)
+	return nil
+}
+
+// IterateTimeFrames - iterates over the time frames holding records of the datanode, sorted by channel
+// and time frame, until cb returns true
+func (k DataNodeKeeper) IterateTimeFrames(ctx sdk.Context, address sdk.AccAddress, cb func(channelID string, timeFrame int64) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.TimeFrameDataNodePrefix(address))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		_, channelID, timeFrame := types.SplitTimeFrameKey(iterator.Key())
+		if cb(channelID, int64(timeFrame)) {
+			break
+		}
+	}
+}
 
-	dataRecord, err := k.GetDataRecord(ctx, hash)
+// GetDataNodeStats - get the summary of the records stored by the datanode
+func (k DataNodeKeeper) GetDataNodeStats(ctx sdk.Context, address sdk.AccAddress) (types.DataNodeStats, error) {
+	var stats types.DataNodeStats
+	channels, err := k.GetChannels(ctx, address)
 	if err != nil {
-		if err == types.ErrInvalidDataRecord {
-			newDataRecord := types.NewDataRecord(address, channel, date)
-			dataRecord = &newDataRecord
+		return stats, err
+	}
 
-			// add datarecord hash to the datanode
-			datanode, err := k.GetDataNode(ctx, address)
-			if err != nil {
-				return err
+	store := ctx.KVStore(k.storeKey)
+	channelIDs := make(map[string]bool)
+	for _, ch := range *channels {
+		channelIDs[ch.ID] = true
+		prefix := types.RecordChannelPrefix(address, ch.ID)
+
+		first := sdk.KVStorePrefixIterator(store, prefix)
+		if first.Valid() {
+			_, _, timeStamp := types.SplitRecordKey(first.Key())
+			if stats.FirstTimeStamp == 0 || int64(timeStamp) < stats.FirstTimeStamp {
+				stats.FirstTimeStamp = int64(timeStamp)
 			}
-			datanode.Records = append(datanode.Records, hash)
-			k.SetDataNode(ctx, address, datanode)
-		} else {
-			return err
 		}
-	}
+		first.Close()
 
-	duplicate := false
-	for _, r := range dataRecord.Records {
-		if r.TimeStamp == record.TimeStamp {
-			duplicate = true
-			break
+		last := sdk.KVStoreReversePrefixIterator(store, prefix)
+		if last.Valid() {
+			_, _, timeStamp := types.SplitRecordKey(last.Key())
+			if int64(timeStamp) > stats.LastTimeStamp {
+				stats.LastTimeStamp = int64(timeStamp)
+			}
 		}
+		last.Close()
 	}
 
-	if !duplicate {
-		dataRecord.Records = append(dataRecord.Records, record)
-		k.SetDataRecord(ctx, dataRecord)
-	}
-	return nil
+	k.IterateTimeFrames(ctx, address, func(channelID string, _ int64) bool {
+		if channelIDs[channelID] {
+			stats.TimeFrames++
+		}
+		return false
+	})
+	return stats, nil
 }
 
-// AddRecordAtTimestamp - add a new record to the time frame using timestamp data
-func (k DataNodeKeeper) AddRecordAtTimestamp(ctx sdk.Context, address sdk.AccAddress, channelID string, record types.Record) error {
-	return k.AddRecord(ctx, address, channelID, int64(record.TimeStamp), record)
+// IterateDataNodes - iterates over all the datanodes in the store until cb returns true
+func (k DataNodeKeeper) IterateDataNodes(ctx sdk.Context, cb func(dataNode types.DataNode) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.DataNodePrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var dataNode types.DataNode
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &dataNode)
+		if cb(dataNode) {
+			break
+		}
+	}
 }
 
-// GetIterator - get an iterator over all datanodes and datrecords
-func (k DataNodeKeeper) GetIterator(ctx sdk.Context) sdk.Iterator {
+// IterateDataRecords - iterates over all the records in the store grouped by datanode channel
+// time frame until cb returns true
+func (k DataNodeKeeper) IterateDataRecords(ctx sdk.Context, cb func(dataRecord types.DataRecord) (stop bool)) {
+	frameSize := k.FrameSize(ctx)
 	store := ctx.KVStore(k.storeKey)
-	return sdk.KVStorePrefixIterator(store, []byte{})
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordPrefix)
+	defer iterator.Close()
+
+	var dataRecord *types.DataRecord
+	for ; iterator.Valid(); iterator.Next() {
+		address, channelID, timeStamp := types.SplitRecordKey(iterator.Key())
+		timeFrame := types.GetTimeFrame(int64(timeStamp)/types.MillisPerSecond, frameSize)
+
+		if dataRecord != nil && (!dataRecord.DataNode.Equals(address) || dataRecord.NodeChannel.ID != channelID || dataRecord.TimeFrame != timeFrame) {
+			if cb(*dataRecord) {
+				return
+			}
+			dataRecord = nil
+		}
+
+		if dataRecord == nil {
+			channel, err := k.GetChannel(ctx, address, channelID)
+			if err != nil {
+				// records of a deleted channel
+				channel = &types.NodeChannel{ID: channelID}
+			}
+			newDataRecord := types.NewDataRecord(address, channel, timeFrame)
+			dataRecord = &newDataRecord
+		}
+
+		var record types.Record
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &record)
+		dataRecord.Records = append(dataRecord.Records, record)
+	}
+
+	if dataRecord != nil {
+		cb(*dataRecord)
+	}
 }
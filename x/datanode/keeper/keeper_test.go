@@ -0,0 +1,548 @@
+package keeper
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+var (
+	testDataNode = sdk.AccAddress([]byte("test-datanode-addr01"))
+	testOwner    = sdk.AccAddress([]byte("test-owner-address01"))
+)
+
+func setupDataNode(t *testing.T, ctx sdk.Context, k DataNodeKeeper) {
+	k.SetDataNodeOwner(ctx, testDataNode, testOwner)
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+}
+
+func TestGetLastRecordsAtFrameBoundaries(t *testing.T) {
+	// midnight UTC, the boundary between two daily time frames
+	midnight := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+	before := midnight.Add(-time.Second)
+
+	ctx, k := CreateTestInput(t, before)
+	setupDataNode(t, ctx, k)
+
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: before.Unix() * 1000, Value: 1}))
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: midnight.Unix() * 1000, Value: 2}))
+
+	cases := []struct {
+		name      string
+		blockTime time.Time
+		expected  int64
+	}{
+		{"last second of the frame", before, 1},
+		{"first second of the next frame", midnight, 2},
+		{"last nanosecond of the frame", midnight.Add(-time.Nanosecond), 1},
+		{"non UTC location", midnight.In(time.FixedZone("UTC-3", -3*3600)), 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blockCtx := ctx.WithBlockTime(tc.blockTime)
+
+			first, err := k.GetLastRecords(blockCtx, testDataNode, "1")
+			require.NoError(t, err)
+			require.Len(t, *first, 1)
+			require.Equal(t, tc.expected, (*first)[0].Value)
+
+			// the same block time must always resolve the same records
+			second, err := k.GetLastRecords(blockCtx, testDataNode, "1")
+			require.NoError(t, err)
+			require.Equal(t, *first, *second)
+		})
+	}
+}
+
+func TestGetLastRecordsEmptyFrame(t *testing.T) {
+	midnight := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	ctx, k := CreateTestInput(t, midnight)
+	setupDataNode(t, ctx, k)
+
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: midnight.Unix()*1000 - 1, Value: 1}))
+
+	_, err := k.GetLastRecords(ctx, testDataNode, "1")
+	require.Equal(t, types.ErrInvalidDataRecord, err)
+}
+
+func TestGetRecordsLegacyChannelFrameSize(t *testing.T) {
+	midnight := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	ctx, k := CreateTestInput(t, midnight.Add(3*time.Hour))
+	k.SetDataNodeOwner(ctx, testDataNode, testOwner)
+	// channels stored with a frame size are queried with the one of the time frame index
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature", FrameSize: 3600}))
+
+	midnightMs := midnight.Unix() * types.MillisPerSecond
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: midnightMs, Value: 1}))
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: midnightMs + 2*3600*types.MillisPerSecond, Value: 2}))
+
+	last, err := k.GetLastRecords(ctx, testDataNode, "1")
+	require.NoError(t, err)
+	require.Len(t, *last, 2)
+
+	records, err := k.GetRecords(ctx, testDataNode, "1", midnight.Unix(), types.TimeUnitSecond)
+	require.NoError(t, err)
+	require.Equal(t, *last, *records)
+
+	var dataRecords []types.DataRecord
+	k.IterateDataRecords(ctx, func(dataRecord types.DataRecord) bool {
+		dataRecords = append(dataRecords, dataRecord)
+		return false
+	})
+	require.Len(t, dataRecords, 1)
+	require.Equal(t, *last, dataRecords[0].Records)
+}
+
+func TestGetDataNodesByOwner(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Now())
+	newOwner := sdk.AccAddress([]byte("test-owner-address02"))
+	otherDataNode := sdk.AccAddress([]byte("test-datanode-addr02"))
+
+	k.SetDataNodeOwner(ctx, testDataNode, testOwner)
+	k.SetDataNodeOwner(ctx, otherDataNode, testOwner)
+
+	dataNodes, next := k.GetDataNodesByOwner(ctx, testOwner, nil, 1)
+	require.Len(t, dataNodes, 1)
+	require.Equal(t, testDataNode, dataNodes[0].ID)
+	require.Equal(t, otherDataNode, next)
+
+	dataNodes, next = k.GetDataNodesByOwner(ctx, testOwner, next, 1)
+	require.Len(t, dataNodes, 1)
+	require.Equal(t, otherDataNode, dataNodes[0].ID)
+	require.Nil(t, next)
+
+	// changing the owner moves the datanode to the new owner index
+	k.SetDataNodeOwner(ctx, testDataNode, newOwner)
+
+	dataNodes, _ = k.GetDataNodesByOwner(ctx, testOwner, nil, 10)
+	require.Len(t, dataNodes, 1)
+	require.Equal(t, otherDataNode, dataNodes[0].ID)
+
+	dataNodes, _ = k.GetDataNodesByOwner(ctx, newOwner, nil, 10)
+	require.Len(t, dataNodes, 1)
+	require.Equal(t, testDataNode, dataNodes[0].ID)
+
+	all, _ := k.GetDataNodes(ctx, nil, 10)
+	require.Len(t, all, 2)
+}
+
+func TestArchiveDataNode(t *testing.T) {
+	now := time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: now.Unix() * 1000, Value: 1}))
+	require.NoError(t, k.ArchiveDataNode(ctx, testDataNode))
+
+	// records are kept but no new ones accepted
+	err := k.AddRecord(ctx, testDataNode, "1", types.Record{TimeStamp: now.Unix()*1000 + 1, Value: 2})
+	require.True(t, types.ErrDataNodeArchived.Is(err))
+
+	records, _, err := k.GetRecordsRange(ctx, testDataNode, "1", 0, now.Unix()*1000+1, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+}
+
+func TestOwnershipOfferExpiry(t *testing.T) {
+	now := time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+	newOwner := sdk.AccAddress([]byte("test-owner-address02"))
+
+	k.SetOwnershipOffer(ctx, types.OwnershipOffer{DataNode: testDataNode, Owner: testOwner, NewOwner: newOwner, Expiry: now.Add(time.Hour)})
+
+	require.Empty(t, k.GetExpiredOwnershipOffers(ctx, now.Add(time.Hour-time.Second)))
+	expired := k.GetExpiredOwnershipOffers(ctx, now.Add(time.Hour))
+	require.Len(t, expired, 1)
+	require.Equal(t, newOwner, expired[0].NewOwner)
+
+	// a new offer replaces the pending one and its place on the queue
+	k.SetOwnershipOffer(ctx, types.OwnershipOffer{DataNode: testDataNode, Owner: testOwner, NewOwner: newOwner, Expiry: now.Add(2 * time.Hour)})
+	require.Empty(t, k.GetExpiredOwnershipOffers(ctx, now.Add(time.Hour)))
+
+	// changing the owner drops the offer
+	k.SetDataNodeOwner(ctx, testDataNode, newOwner)
+	_, err := k.GetOwnershipOffer(ctx, testDataNode)
+	require.True(t, types.ErrNoOwnershipOffer.Is(err))
+	require.Empty(t, k.GetExpiredOwnershipOffers(ctx, now.Add(2*time.Hour)))
+}
+
+func TestRoleGrants(t *testing.T) {
+	now := time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+	gateway := sdk.AccAddress([]byte("test-gateway-addr-01"))
+	operator := sdk.AccAddress([]byte("test-operator-addr01"))
+
+	require.False(t, k.CanWriteChannel(ctx, testDataNode, gateway, "1"))
+	require.True(t, k.HasPermission(ctx, testDataNode, testOwner, types.PermissionDelete))
+
+	k.SetRoleGrant(ctx, types.RoleGrant{DataNode: testDataNode, Address: gateway, Role: types.RoleWriter, Channels: []string{"1"}, Expiry: now.Add(time.Hour)})
+	require.True(t, k.CanWriteChannel(ctx, testDataNode, gateway, "1"))
+	require.False(t, k.CanWriteChannel(ctx, testDataNode, gateway, "2"))
+	require.False(t, k.HasPermission(ctx, testDataNode, gateway, types.PermissionEditChannels))
+	require.False(t, k.CanWriteChannel(ctx.WithBlockTime(now.Add(time.Hour)), testDataNode, gateway, "1"))
+	require.False(t, k.HasPermission(ctx.WithBlockTime(now.Add(time.Hour)), testDataNode, gateway, types.PermissionWrite))
+
+	k.SetRoleGrant(ctx, types.RoleGrant{DataNode: testDataNode, Address: operator, Role: types.RoleOperator})
+	require.True(t, k.CanWriteChannel(ctx, testDataNode, operator, "2"))
+	require.True(t, k.HasPermission(ctx, testDataNode, operator, types.PermissionEditChannels))
+	require.False(t, k.HasPermission(ctx, testDataNode, operator, types.PermissionManageRoles))
+	require.False(t, k.HasPermission(ctx, testDataNode, operator, types.PermissionTransfer))
+
+	k.SetRoleGrant(ctx, types.RoleGrant{DataNode: testDataNode, Address: operator, Role: types.RoleAdmin})
+	require.True(t, k.HasPermission(ctx, testDataNode, operator, types.PermissionTransfer))
+	require.Len(t, k.GetRoleGrants(ctx, testDataNode), 2)
+
+	// grants are dropped with an owner change
+	k.SetDataNodeOwner(ctx, testDataNode, sdk.AccAddress([]byte("test-owner-address02")))
+	require.Empty(t, k.GetRoleGrants(ctx, testDataNode))
+}
+
+func TestFleetMembers(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC))
+	setupDataNode(t, ctx, k)
+	admin := sdk.AccAddress([]byte("test-fleet-admin-001"))
+	payer := sdk.AccAddress([]byte("test-fleet-payer-001"))
+
+	fleet := types.NewFleet("factory-1", admin)
+	fleet.ChannelTemplate = []types.NodeChannel{{ID: "1", Variable: "pressure"}, {ID: "2", Variable: "humidity"}}
+	k.SetFleet(ctx, fleet)
+	require.Equal(t, testOwner, k.GetFeePayer(ctx, types.NewDataNode(testDataNode, testOwner)))
+
+	require.NoError(t, k.SetFleetMember(ctx, fleet.ID, testDataNode))
+	require.NoError(t, k.ApplyChannelTemplate(ctx, testDataNode, fleet.ChannelTemplate))
+	members, next := k.GetFleetMembers(ctx, fleet.ID, nil, 10)
+	require.Nil(t, next)
+	require.Len(t, members, 1)
+	require.Equal(t, fleet.ChannelTemplate, members[0].Channels)
+
+	// the fleet fee payer takes over the owner
+	require.Equal(t, testOwner, k.GetFeePayer(ctx, members[0]))
+	fleet.FeePayer = payer
+	k.SetFleet(ctx, fleet)
+	require.Equal(t, payer, k.GetFeePayer(ctx, members[0]))
+	require.Equal(t, []sdk.AccAddress{payer, testOwner}, k.GetFeePayers(ctx, members[0]))
+
+	// the fee payer of the datanode takes over the fleet fee payer
+	dataNodePayer := sdk.AccAddress([]byte("test-node-payer-0001"))
+	require.NoError(t, k.SetDataNodeFeePayer(ctx, testDataNode, dataNodePayer))
+	dataNode, err := k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.Equal(t, dataNodePayer, k.GetFeePayer(ctx, *dataNode))
+
+	// the membership and the fee payer are dropped with an owner change
+	k.SetDataNodeOwner(ctx, testDataNode, sdk.AccAddress([]byte("test-owner-address02")))
+	members, _ = k.GetFleetMembers(ctx, fleet.ID, nil, 10)
+	require.Empty(t, members)
+	dataNode, err = k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.Empty(t, dataNode.Fleet)
+	require.Empty(t, dataNode.FeePayer)
+}
+
+func TestDeviceTypeUpgrade(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC))
+	setupDataNode(t, ctx, k)
+
+	k.SetDeviceType(ctx, types.DeviceType{ID: "sensor", Version: 1, Owner: testOwner,
+		Channels: []types.NodeChannel{{ID: "2", Variable: "pressure"}, {ID: "3", Variable: "humidity"}}})
+	k.SetDeviceType(ctx, types.DeviceType{ID: "sensor", Version: 2, Owner: testOwner,
+		Channels: []types.NodeChannel{{ID: "2", Variable: "pressure", Unit: "kPa"}, {ID: "4", Variable: "co2"}}})
+	k.SetDeviceType(ctx, types.DeviceType{ID: "meter", Version: 1, Owner: testOwner,
+		Channels: []types.NodeChannel{{ID: "1", Variable: "power"}}})
+
+	latest, err := k.GetDeviceType(ctx, "sensor", 0)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), latest.Version)
+
+	v1, err := k.GetDeviceType(ctx, "sensor", 1)
+	require.NoError(t, err)
+	require.NoError(t, k.LinkDeviceType(ctx, testDataNode, v1))
+	nodes, _ := k.GetDeviceTypeNodes(ctx, "sensor", nil, 10)
+	require.Len(t, nodes, 1)
+	require.Len(t, nodes[0].Channels, 3)
+
+	// channels dropped by the new version are removed, custom channels are kept
+	require.NoError(t, k.LinkDeviceType(ctx, testDataNode, latest))
+	dataNode, err := k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), dataNode.TypeVersion)
+	require.Equal(t, []types.NodeChannel{
+		{ID: "1", Variable: "temperature"},
+		{ID: "2", Variable: "pressure", Unit: "kPa"},
+		{ID: "4", Variable: "co2"},
+	}, dataNode.Channels)
+
+	// only the latest version of each type is listed, shorter ids first
+	deviceTypes, next := k.GetDeviceTypes(ctx, "", 1)
+	require.Len(t, deviceTypes, 1)
+	require.Equal(t, "meter", deviceTypes[0].ID)
+	require.Equal(t, "sensor", next)
+	deviceTypes, next = k.GetDeviceTypes(ctx, next, 1)
+	require.Len(t, deviceTypes, 1)
+	require.Equal(t, uint32(2), deviceTypes[0].Version)
+	require.Empty(t, next)
+
+	// unlinking keeps the channels
+	require.NoError(t, k.LinkDeviceType(ctx, testDataNode, nil))
+	nodes, _ = k.GetDeviceTypeNodes(ctx, "sensor", nil, 10)
+	require.Empty(t, nodes)
+	dataNode, err = k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.Empty(t, dataNode.DeviceType)
+	require.Len(t, dataNode.Channels, 3)
+}
+
+func TestDecodedRecords(t *testing.T) {
+	now := time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+
+	channel := types.NodeChannel{ID: "1", Variable: "temperature", Unit: "Cel", Encoding: types.EncodingDecimal, Scale: 1, Min: "-40", Max: "85"}
+	require.NoError(t, types.ValidateChannel(channel))
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, channel))
+
+	value, tooLow := int32(-125), int32(-401)
+	record := types.Record{TimeStamp: now.Unix() * 1000, Value: int64(value)}
+	require.NoError(t, channel.ValidateValue(record))
+	require.Error(t, channel.ValidateValue(types.Record{Value: 900}))
+	require.Error(t, channel.ValidateValue(types.Record{Value: int64(tooLow)}))
+	require.NoError(t, k.AddRecord(ctx, testDataNode, "1", record))
+
+	path := []string{testDataNode.String(), "1", strconv.FormatInt(now.Unix()-60, 10), strconv.FormatInt(now.Unix()+60, 10)}
+	res, err := queryRecordsRange(ctx, path, abci.RequestQuery{}, k)
+	require.NoError(t, err)
+	var out types.QueryResRecordsRange
+	k.cdc.MustUnmarshalJSON(res, &out)
+	require.Len(t, out.Records, 1)
+	require.Equal(t, "-12.5", out.Records[0].Decoded)
+	require.Equal(t, "Cel", out.Records[0].Unit)
+
+	enum := types.NodeChannel{ID: "2", Encoding: types.EncodingEnum, Enum: []string{"off", "on"}}
+	require.NoError(t, types.ValidateChannel(enum))
+	decoded, err := enum.DecodeValue(types.Record{Value: 1})
+	require.NoError(t, err)
+	require.Equal(t, "on", decoded)
+	require.Error(t, enum.ValidateValue(types.Record{Value: 2}))
+	require.Error(t, types.ValidateChannel(types.NodeChannel{ID: "3", Encoding: types.EncodingBool, Max: "1"}))
+}
+
+func TestFeeAllowance(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 40))
+	caps, err := k.SpendFeeAllowance(ctx, testDataNode, fee)
+	require.NoError(t, err)
+	require.Empty(t, caps)
+
+	k.SetFeeAllowance(ctx, types.NewFeeAllowance(testDataNode,
+		sdk.NewCoins(sdk.NewInt64Coin("stake", 200)),
+		sdk.NewCoins(sdk.NewInt64Coin("stake", 80)), 3600,
+		sdk.NewCoins(sdk.NewInt64Coin("stake", 50))))
+
+	_, err = k.SpendFeeAllowance(ctx, testDataNode, sdk.NewCoins(sdk.NewInt64Coin("stake", 60)))
+	require.True(t, types.ErrFeeAllowanceExceeded.Is(err))
+	_, err = k.SpendFeeAllowance(ctx, testDataNode, sdk.NewCoins(sdk.NewInt64Coin("atom", 1)))
+	require.True(t, types.ErrFeeAllowanceExceeded.Is(err))
+
+	caps, err = k.SpendFeeAllowance(ctx, testDataNode, fee)
+	require.NoError(t, err)
+	require.Empty(t, caps)
+	caps, err = k.SpendFeeAllowance(ctx, testDataNode, fee)
+	require.NoError(t, err)
+	require.Equal(t, []string{types.FeeCapPeriod}, caps)
+	_, err = k.SpendFeeAllowance(ctx, testDataNode, fee)
+	require.True(t, types.ErrFeeAllowanceExceeded.Is(err))
+
+	// the period spending resets once the period has ended
+	ctx = ctx.WithBlockTime(now.Add(time.Hour))
+	allowance, err := k.GetFeeAllowance(ctx, testDataNode)
+	require.NoError(t, err)
+	total, period := allowance.Remaining(ctx.BlockTime())
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 120)), total)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 80)), period)
+	for i := 0; i < 3; i++ {
+		_, err = k.SpendFeeAllowance(ctx, testDataNode, fee)
+		require.NoError(t, err)
+		ctx = ctx.WithBlockTime(ctx.BlockTime().Add(time.Hour))
+	}
+	_, err = k.SpendFeeAllowance(ctx, testDataNode, fee)
+	require.True(t, types.ErrFeeAllowanceExceeded.Is(err))
+
+	// the allowance of the previous owner doesn't cap the new one
+	k.SetDataNodeOwner(ctx, testDataNode, sdk.AccAddress([]byte("test-owner-address02")))
+	_, err = k.GetFeeAllowance(ctx, testDataNode)
+	require.Error(t, err)
+}
+
+func TestPruneExpiredRecords(t *testing.T) {
+	day := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, day)
+	setupDataNode(t, ctx, k)
+	params := k.GetParams(ctx)
+	params.MaxPrunedPerBlock = 2
+	k.SetParams(ctx, params)
+
+	dayMs := day.Unix() * types.MillisPerSecond
+	for i, ts := range []int64{dayMs, dayMs + 1000, dayMs + 2000, dayMs + 24*3600*1000} {
+		k.SetRecord(ctx, testDataNode, "1", types.Record{TimeStamp: ts, Value: int64(i + 1)})
+	}
+	// records are kept forever without retention
+	require.Empty(t, k.PruneExpiredRecords(ctx, day.Add(365*24*time.Hour)))
+
+	// the time frames stored are queued once the channel gets a retention
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature", Retention: 24 * 3600, Rollup: true}))
+	expiry := day.Add(48 * time.Hour)
+	require.Empty(t, k.PruneExpiredRecords(ctx, expiry.Add(-time.Second)))
+
+	// the pruning is bounded, the time frame is continued on the next block
+	pruned := k.PruneExpiredRecords(ctx, expiry)
+	require.Len(t, pruned, 1)
+	require.Equal(t, 2, pruned[0].Count)
+	pruned = k.PruneExpiredRecords(ctx, expiry)
+	require.Len(t, pruned, 1)
+	require.Equal(t, 1, pruned[0].Count)
+	require.Empty(t, k.PruneExpiredRecords(ctx, expiry))
+
+	records, _, err := k.GetRecordsRange(ctx, testDataNode, "1", 0, dayMs+48*3600*1000, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	rollups := k.GetRollups(ctx, testDataNode, "1")
+	require.Len(t, rollups, 1)
+	require.Equal(t, int64(3), rollups[0].Count)
+	require.Equal(t, int64(6), rollups[0].Sum.Int64())
+	require.Equal(t, int64(1), rollups[0].Min)
+	require.Equal(t, int64(3), rollups[0].Max)
+
+	// dropping the retention keeps the records left
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature"}))
+	require.Empty(t, k.PruneExpiredRecords(ctx, expiry.Add(365*24*time.Hour)))
+	records, _, err = k.GetRecordsRange(ctx, testDataNode, "1", 0, dayMs+48*3600*1000, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	// deleting the datanode drops its rollups
+	k.DeleteDataNode(ctx, testDataNode)
+	require.Empty(t, k.GetRollups(ctx, testDataNode, "1"))
+}
+
+func TestGetRecordsRange(t *testing.T) {
+	now := time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, now)
+	setupDataNode(t, ctx, k)
+
+	// records spread over time frames years apart
+	nowMs := now.Unix() * types.MillisPerSecond
+	timeStamps := []int64{1000, nowMs - 1, nowMs, nowMs + 3*365*24*3600*1000}
+	for i, ts := range timeStamps {
+		k.SetRecord(ctx, testDataNode, "1", types.Record{TimeStamp: ts, Value: int64(i)})
+	}
+
+	// an unbounded range walks the records, not the time frames between them
+	records, next, err := k.GetRecordsRange(ctx, testDataNode, "1", 0, math.MaxInt64, 3)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, timeStamps[3], next)
+	records, next, err = k.GetRecordsRange(ctx, testDataNode, "1", next, math.MaxInt64, 3)
+	require.NoError(t, err)
+	require.Equal(t, []types.Record{{TimeStamp: timeStamps[3], Value: 3}}, records)
+	require.Equal(t, int64(0), next)
+
+	records, _, err = k.GetRecordsRange(ctx, testDataNode, "1", nowMs-1, nowMs, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	// the query takes seconds out of the milliseconds range
+	querier := NewQuerier(k)
+	path := []string{types.QueryRecordsRange, testDataNode.String(), "1", "0", strconv.FormatInt(math.MaxInt64, 10), "10", string(types.TimeUnitSecond)}
+	bz, err := querier(ctx, path, abci.RequestQuery{})
+	require.NoError(t, err)
+	var res types.QueryResRecordsRange
+	k.cdc.MustUnmarshalJSON(bz, &res)
+	require.Len(t, res.Records, 4)
+}
+
+func TestFrameSizeChange(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC))
+	require.Equal(t, types.DefaultFrameSize, k.FrameSize(ctx))
+	require.Equal(t, types.DefaultFrameSize, k.GetParams(ctx).FrameSize)
+
+	// a param change proposal sets the parameter, the index keeps its frame size until it is rebuilt
+	require.NoError(t, k.paramspace.(params.Subspace).Update(ctx, types.KeyFrameSize, []byte(`"3600"`)))
+	require.Equal(t, int64(3600), k.GetParams(ctx).FrameSize)
+	require.Equal(t, types.DefaultFrameSize, k.FrameSize(ctx))
+	k.ReindexTimeFrames(ctx)
+	require.Equal(t, int64(3600), k.FrameSize(ctx))
+
+	moduleParams := k.GetParams(ctx)
+	moduleParams.FrameSize = 600
+	require.NotPanics(t, func() { k.SetParams(ctx, moduleParams) })
+	require.Equal(t, int64(3600), k.FrameSize(ctx))
+	k.ReindexTimeFrames(ctx)
+	require.Equal(t, int64(600), k.FrameSize(ctx))
+}
+
+func TestPruneExpiredRecordsFrameSizeChange(t *testing.T) {
+	day := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+	ctx, k := CreateTestInput(t, day)
+	setupDataNode(t, ctx, k)
+	require.NoError(t, k.ChangeChannel(ctx, testDataNode, types.NodeChannel{ID: "1", Variable: "temperature", Retention: 3600, Rollup: true}))
+
+	// records at the start and the end of the first time frame and on the next one
+	dayMs := day.Unix() * types.MillisPerSecond
+	frameMs := types.DefaultFrameSize * types.MillisPerSecond
+	for i, ts := range []int64{dayMs, dayMs + frameMs - 1, dayMs + frameMs} {
+		k.SetRecord(ctx, testDataNode, "1", types.Record{TimeStamp: ts, Value: int64(i)})
+	}
+
+	// the first time frame is pruned at its expiry with the frame size it was indexed with
+	dayFrame := types.GetTimeFrame(day.Unix(), types.DefaultFrameSize)
+	pruned := k.PruneExpiredRecords(ctx, types.TimeFrameExpiry(dayFrame, types.DefaultFrameSize, 3600))
+	require.Len(t, pruned, 1)
+	require.Equal(t, 2, pruned[0].Count)
+
+	// governance changes the frame size, the index, the retention queue and the rollups are rebuilt
+	require.NoError(t, k.paramspace.(params.Subspace).Update(ctx, types.KeyFrameSize, []byte(`"3600"`)))
+	require.Equal(t, 1, k.ReindexTimeFrames(ctx))
+
+	var timeFrames []int64
+	k.IterateChannelTimeFrames(ctx, testDataNode, "1", func(timeFrame int64) bool {
+		timeFrames = append(timeFrames, timeFrame)
+		return false
+	})
+	nextHour := types.GetTimeFrame(day.Unix()+types.DefaultFrameSize, 3600)
+	require.Equal(t, []int64{nextHour}, timeFrames)
+
+	pruned = k.PruneExpiredRecords(ctx, types.TimeFrameExpiry(nextHour, 3600, 3600))
+	require.Len(t, pruned, 1)
+	require.Equal(t, 1, pruned[0].Count)
+	require.Equal(t, nextHour, pruned[0].TimeFrame)
+
+	rollups := k.GetRollups(ctx, testDataNode, "1")
+	require.Len(t, rollups, 2)
+	require.Equal(t, types.GetTimeFrame(day.Unix(), 3600), rollups[0].TimeFrame)
+	require.Equal(t, int64(2), rollups[0].Count)
+	require.Equal(t, dayMs+frameMs-1, rollups[0].Last)
+	require.Equal(t, nextHour, rollups[1].TimeFrame)
+	require.Equal(t, int64(1), rollups[1].Count)
+
+	records, _, err := k.GetRecordsRange(ctx, testDataNode, "1", 0, dayMs+2*frameMs, 10)
+	require.NoError(t, err)
+	require.Empty(t, records)
+	msg, broken := StorageDepositsBytesInvariant(k)(ctx)
+	require.False(t, broken, msg)
+}
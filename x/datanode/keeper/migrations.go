@@ -0,0 +1,280 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// MigrateKeyPrefixes - moves the datanodes stored under their raw address and the datarecords stored
+// under their raw hash to the prefixed keys. Legacy keys are told apart by their length, which only holds
+// on a store without prefixed keys, so it must run once before any other migration
+func (k DataNodeKeeper) MigrateKeyPrefixes(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	var legacyKeys [][]byte
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		switch len(iterator.Key()) {
+		case sdk.AddrLen, len(types.DataRecordHash{}):
+			legacyKeys = append(legacyKeys, append([]byte{}, iterator.Key()...))
+		}
+	}
+	iterator.Close()
+
+	for _, key := range legacyKeys {
+		bz := store.Get(key)
+		if len(key) == sdk.AddrLen {
+			store.Set(types.DataNodeKey(key), bz)
+		} else {
+			var hash types.DataRecordHash
+			copy(hash[:], key)
+			store.Set(types.DataRecordKey(hash), bz)
+		}
+		store.Delete(key)
+	}
+
+	ctx.Logger().Info("migrated datanode store to prefixed keys", "keys", len(legacyKeys))
+}
+
+// MigrateRecordsLayout - splits every legacy datarecord holding the records of a whole time frame into
+// single records keyed by datanode, channel and timestamp. Datarecord hashes on the datanodes are kept
+func (k DataNodeKeeper) MigrateRecordsLayout(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	var legacyKeys [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, types.DataRecordPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		legacyKeys = append(legacyKeys, append([]byte{}, iterator.Key()...))
+	}
+	iterator.Close()
+
+	records := 0
+	for _, key := range legacyKeys {
+		var dataRecord legacyDataRecord
+		k.cdc.MustUnmarshalBinaryBare(store.Get(key), &dataRecord)
+		for _, record := range dataRecord.Records {
+			// legacy datarecords never hold two records at the same timestamp, keep the first one anyway
+			recordKey := types.RecordKey(dataRecord.DataNode, dataRecord.NodeChannel.ID, uint64(record.TimeStamp))
+			if !store.Has(recordKey) {
+				store.Set(recordKey, k.cdc.MustMarshalBinaryBare(record))
+				records++
+			}
+		}
+		store.Delete(key)
+	}
+
+	ctx.Logger().Info("migrated datanode datarecords to single records", "datarecords", len(legacyKeys), "records", records)
+}
+
+// legacyRecord - record layout with the timestamp in seconds and a 32-bit value, stored under the
+// timestamp in seconds
+type legacyRecord struct {
+	TimeStamp uint32 `json:"t"`
+	Value     uint32 `json:"v"`
+	Misc      string `json:"m"`
+}
+
+// legacyDataRecord - datarecord layout holding the legacy records of a whole time frame
+type legacyDataRecord struct {
+	DataNode    sdk.AccAddress    `json:"datanode"`
+	NodeChannel types.NodeChannel `json:"channel"`
+	TimeFrame   int64             `json:"timeframe"`
+	Records     []legacyRecord    `json:"records"`
+}
+
+// legacyDataNode - datanode layout holding the hashes of its datarecords
+type legacyDataNode struct {
+	ID       sdk.AccAddress         `json:"id,omitempty"`
+	Owner    sdk.AccAddress         `json:"owner"`
+	Name     string                 `json:"name"`
+	Channels []types.NodeChannel    `json:"channels"`
+	Records  []types.DataRecordHash `json:"records"`
+}
+
+// MigrateTimeFrameIndex - drops the datarecord hashes stored on every datanode and indexes the time
+// frames holding records from the records themselves
+func (k DataNodeKeeper) MigrateTimeFrameIndex(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	frameSize := k.FrameSize(ctx)
+
+	timeFrames := 0
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		address, channelID, timeStamp := types.SplitRecordKey(iterator.Key())
+		timeFrameKey := types.TimeFrameKey(address, channelID, uint64(types.GetTimeFrame(int64(timeStamp), frameSize)))
+		if !store.Has(timeFrameKey) {
+			store.Set(timeFrameKey, []byte{})
+			timeFrames++
+		}
+	}
+	iterator.Close()
+
+	var dataNodes []legacyDataNode
+	iterator = sdk.KVStorePrefixIterator(store, types.DataNodePrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var dataNode legacyDataNode
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &dataNode)
+		dataNodes = append(dataNodes, dataNode)
+	}
+	iterator.Close()
+
+	for _, dn := range dataNodes {
+		dataNode := types.DataNode{
+			ID:       dn.ID,
+			Owner:    dn.Owner,
+			Name:     dn.Name,
+			Channels: dn.Channels,
+		}
+		// the legacy value can't be read as a datanode, drop it before setting the new one
+		store.Delete(types.DataNodeKey(dataNode.ID))
+		k.SetDataNode(ctx, dataNode.ID, &dataNode)
+	}
+
+	ctx.Logger().Info("migrated datanode datarecord hashes to the time frame index", "datanodes", len(dataNodes), "timeframes", timeFrames)
+}
+
+// MigrateOwnerIndex - indexes every datanode by its owner
+func (k DataNodeKeeper) MigrateOwnerIndex(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	var keys [][]byte
+	k.IterateDataNodes(ctx, func(dataNode types.DataNode) bool {
+		keys = append(keys, types.OwnerDataNodeKey(dataNode.Owner, dataNode.ID))
+		return false
+	})
+
+	for _, key := range keys {
+		store.Set(key, []byte{})
+	}
+
+	ctx.Logger().Info("indexed datanodes by owner", "datanodes", len(keys))
+}
+
+// MigrateParams - sets the default value of the parameters missing on the param store, parameters
+// added after the chain started have no value until set and reading them would panic
+func (k DataNodeKeeper) MigrateParams(ctx sdk.Context) {
+	defaults := types.DefaultParams()
+	// the frame size missing is the one the time frame index is built with
+	defaults.FrameSize = k.FrameSize(ctx)
+	for _, pair := range defaults.ParamSetPairs() {
+		if !k.paramspace.Has(ctx, pair.Key) {
+			k.paramspace.Set(ctx, pair.Key, pair.Value)
+			ctx.Logger().Info("set default datanode parameter", "key", string(pair.Key))
+		}
+	}
+}
+
+// MigrateRecordsV2 - converts every legacy record to a record v2 stored under its timestamp in milliseconds.
+// Values are converted with the encoding of their channel, the time frame index is kept as is
+func (k DataNodeKeeper) MigrateRecordsV2(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	var keys [][]byte
+	var records []types.Record
+	channels := make(map[string]types.NodeChannel)
+	failed := 0
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		address, channelID, _ := types.SplitRecordKey(iterator.Key())
+		var legacy legacyRecord
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &legacy)
+
+		channelKey := address.String() + "/" + channelID
+		channel, ok := channels[channelKey]
+		if !ok {
+			// records of deleted channels are read as uint32
+			if ch, err := k.GetChannel(ctx, address, channelID); err == nil {
+				channel = *ch
+			}
+			channels[channelKey] = channel
+		}
+
+		value, misc, err := channel.LegacyValue(legacy.Value, legacy.Misc)
+		if err != nil {
+			// keep the data of the records that can't be converted on misc
+			value, misc = int64(legacy.Value), legacy.Misc
+			failed++
+		}
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		records = append(records, types.Record{
+			TimeStamp: int64(legacy.TimeStamp) * types.MillisPerSecond,
+			Value:     value,
+			Misc:      misc,
+		})
+	}
+	iterator.Close()
+
+	// delete every legacy key before setting the new ones, so no new key is taken for a legacy one
+	for _, key := range keys {
+		store.Delete(key)
+	}
+	for i, key := range keys {
+		address, channelID, _ := types.SplitRecordKey(key)
+		store.Set(types.RecordKey(address, channelID, uint64(records[i].TimeStamp)), k.cdc.MustMarshalBinaryBare(records[i]))
+	}
+
+	ctx.Logger().Info("migrated datanode records to v2", "records", len(keys), "unconverted", failed)
+}
+
+// MigrateStorageBytes - counts the bytes taken by the records of every datanode on their storage deposits,
+// the records stored before the deposits have no coins deposited for them
+func (k DataNodeKeeper) MigrateStorageBytes(ctx sdk.Context) {
+	var deposits []types.StorageDeposit
+	k.IterateStorageDeposits(ctx, func(deposit types.StorageDeposit) bool {
+		deposits = append(deposits, deposit)
+		return false
+	})
+	for _, deposit := range deposits {
+		deposit.Bytes = 0
+		k.SetStorageDeposit(ctx, deposit)
+	}
+
+	stored := k.storedBytes(ctx)
+	for _, bytes := range stored {
+		deposit := k.GetStorageDeposit(ctx, bytes.DataNode)
+		deposit.Bytes = bytes.Bytes
+		k.SetStorageDeposit(ctx, deposit)
+	}
+	ctx.Logger().Info("counted datanode stored bytes", "datanodes", len(stored))
+}
+
+// MigrateRetentionQueue - queues every time frame holding records at its expiry, the time frames of the
+// channels kept forever are left out
+func (k DataNodeKeeper) MigrateRetentionQueue(ctx sdk.Context) {
+	queued := k.queueTimeFrames(ctx)
+	ctx.Logger().Info("queued datanode time frames for pruning", "timeframes", queued)
+}
+
+// MigrateFleetKeys - moves every fleet stored under its raw id to its length prefixed key, the fleets are
+// read from their values so it can run more than once
+func (k DataNodeKeeper) MigrateFleetKeys(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	var keys [][]byte
+	var fleets []types.Fleet
+	iterator := sdk.KVStorePrefixIterator(store, types.FleetPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		var fleet types.Fleet
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &fleet)
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		fleets = append(fleets, fleet)
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+	for _, fleet := range fleets {
+		k.SetFleet(ctx, fleet)
+	}
+	ctx.Logger().Info("migrated datanode fleets to length prefixed keys", "fleets", len(fleets))
+}
+
+// MigrateFrameSize - records the frame size on the param store as the one the time frame index is built
+// with, the index is rebuilt once governance changes the parameter
+func (k DataNodeKeeper) MigrateFrameSize(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	frameSize := k.FrameSize(ctx)
+	store.Set(types.FrameSizeKey, sdk.Uint64ToBigEndian(uint64(frameSize)))
+	ctx.Logger().Info("recorded datanode index frame size", "seconds", frameSize)
+}
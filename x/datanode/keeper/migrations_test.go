@@ -0,0 +1,90 @@
+package keeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+func TestMigrateLegacyStore(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC))
+	store := ctx.KVStore(k.storeKey)
+
+	channel := types.NodeChannel{ID: "1", Variable: "temperature", Encoding: types.EncodingInt32}
+	dataNode := types.NewDataNode(testDataNode, testOwner)
+	dataNode.Channels = []types.NodeChannel{channel}
+	legacyNode := legacyDataNode{ID: dataNode.ID, Owner: dataNode.Owner, Name: dataNode.Name, Channels: dataNode.Channels}
+	minus := int32(-5)
+	dataRecord := legacyDataRecord{DataNode: testDataNode, NodeChannel: channel, TimeFrame: types.GetTimeFrame(ctx.BlockTime().Unix(), types.DefaultFrameSize)}
+	dataRecord.Records = []legacyRecord{{TimeStamp: uint32(ctx.BlockTime().Unix()), Value: uint32(minus)}}
+	hash := types.GetDataRecordHash(testDataNode, &channel, dataRecord.TimeFrame)
+
+	// the datanode holds the hashes of the two previous days too, the repeated hashes field of the
+	// legacy layout can't be read as a datanode
+	var hashes []types.DataRecordHash
+	for days := int64(2); days > 0; days-- {
+		previous := legacyDataRecord{DataNode: testDataNode, NodeChannel: channel, TimeFrame: dataRecord.TimeFrame - days}
+		previous.Records = []legacyRecord{{TimeStamp: uint32(ctx.BlockTime().Unix() - days*types.DefaultFrameSize), Value: uint32(days)}}
+		previousHash := types.GetDataRecordHash(testDataNode, &channel, previous.TimeFrame)
+		store.Set(previousHash[:], k.cdc.MustMarshalBinaryBare(previous))
+		hashes = append(hashes, previousHash)
+	}
+	legacyNode.Records = append(hashes, hash)
+
+	// legacy layout, raw address and raw hash keys
+	store.Set(testDataNode, k.cdc.MustMarshalBinaryBare(legacyNode))
+	store.Set(hash[:], k.cdc.MustMarshalBinaryBare(dataRecord))
+
+	k.MigrateKeyPrefixes(ctx)
+
+	require.False(t, store.Has(testDataNode))
+	require.False(t, store.Has(hash[:]))
+	require.True(t, store.Has(types.DataRecordKey(hash)))
+
+	k.MigrateRecordsLayout(ctx)
+	require.False(t, store.Has(types.DataRecordKey(hash)))
+
+	k.MigrateTimeFrameIndex(ctx)
+	require.True(t, store.Has(types.TimeFrameKey(testDataNode, "1", uint64(dataRecord.TimeFrame))))
+
+	k.MigrateRecordsV2(ctx)
+
+	migratedNode, err := k.GetDataNode(ctx, testDataNode)
+	require.NoError(t, err)
+	require.Equal(t, dataNode, *migratedNode)
+
+	// records v2 are in milliseconds with the signed value sign extended
+	migrated := types.Record{TimeStamp: ctx.BlockTime().Unix() * 1000, Value: -5}
+	records, err := k.GetLastRecords(ctx, testDataNode, "1")
+	require.NoError(t, err)
+	require.Equal(t, []types.Record{migrated}, *records)
+
+	stats, err := k.GetDataNodeStats(ctx, testDataNode)
+	require.NoError(t, err)
+	first := (ctx.BlockTime().Unix() - 2*types.DefaultFrameSize) * 1000
+	require.Equal(t, types.DataNodeStats{FirstTimeStamp: first, LastTimeStamp: migrated.TimeStamp, TimeFrames: 3}, stats)
+}
+
+func TestMigrateFleetKeys(t *testing.T) {
+	ctx, k := CreateTestInput(t, time.Date(2020, 5, 20, 12, 0, 0, 0, time.UTC))
+	store := ctx.KVStore(k.storeKey)
+
+	// a 19 characters id takes as many bytes as an address on the legacy key
+	fleet := types.Fleet{ID: "fleet-of-19-chars-x", Admins: []sdk.AccAddress{testOwner}}
+	legacyKey := append(append([]byte{}, types.FleetPrefix...), fleet.ID...)
+	require.Len(t, legacyKey, sdk.AddrLen)
+	store.Set(legacyKey, k.cdc.MustMarshalBinaryBare(fleet))
+
+	k.MigrateFleetKeys(ctx)
+	// running it again must be a no-op
+	k.MigrateFleetKeys(ctx)
+
+	require.False(t, store.Has(legacyKey))
+	migrated, err := k.GetFleet(ctx, fleet.ID)
+	require.NoError(t, err)
+	require.Equal(t, fleet, *migrated)
+}
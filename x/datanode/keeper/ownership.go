@@ -0,0 +1,76 @@
+package keeper
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Ownership offer methods
+
+// GetOwnershipOffer - get the pending ownership offer of the datanode
+func (k DataNodeKeeper) GetOwnershipOffer(ctx sdk.Context, address sdk.AccAddress) (*types.OwnershipOffer, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.OwnershipOfferKey(address))
+	if bz == nil {
+		return nil, types.ErrNoOwnershipOffer
+	}
+	var offer types.OwnershipOffer
+	k.cdc.MustUnmarshalBinaryBare(bz, &offer)
+	return &offer, nil
+}
+
+// SetOwnershipOffer - sets the ownership offer of the datanode, replacing the pending one if any
+func (k DataNodeKeeper) SetOwnershipOffer(ctx sdk.Context, offer types.OwnershipOffer) {
+	k.DeleteOwnershipOffer(ctx, offer.DataNode)
+
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.OwnershipOfferKey(offer.DataNode), k.cdc.MustMarshalBinaryBare(offer))
+	store.Set(types.OwnershipOfferQueueKey(offer.Expiry, offer.DataNode), []byte{})
+}
+
+// DeleteOwnershipOffer - removes the pending ownership offer of the datanode if any
+func (k DataNodeKeeper) DeleteOwnershipOffer(ctx sdk.Context, address sdk.AccAddress) {
+	offer, err := k.GetOwnershipOffer(ctx, address)
+	if err != nil {
+		return
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.OwnershipOfferQueueKey(offer.Expiry, address))
+	store.Delete(types.OwnershipOfferKey(address))
+}
+
+// IterateOwnershipOffers - iterate over all the pending ownership offers
+func (k DataNodeKeeper) IterateOwnershipOffers(ctx sdk.Context, cb func(offer types.OwnershipOffer) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.OwnershipOfferPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var offer types.OwnershipOffer
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &offer)
+		if cb(offer) {
+			break
+		}
+	}
+}
+
+// GetExpiredOwnershipOffers - get the pending ownership offers expiring at or before the time,
+// sorted by expiry time
+func (k DataNodeKeeper) GetExpiredOwnershipOffers(ctx sdk.Context, now time.Time) []types.OwnershipOffer {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.OwnershipOfferQueuePrefix, sdk.PrefixEndBytes(types.OwnershipOfferQueueTimePrefix(now)))
+	defer iterator.Close()
+
+	offers := []types.OwnershipOffer{}
+	for ; iterator.Valid(); iterator.Next() {
+		offer, err := k.GetOwnershipOffer(ctx, types.SplitOwnershipOfferQueueKey(iterator.Key()))
+		if err != nil {
+			continue
+		}
+		offers = append(offers, *offer)
+	}
+	return offers
+}
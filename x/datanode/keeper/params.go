@@ -1,22 +1,89 @@
 package keeper
-/*
-// TODO: Define if your module needs Parameters, if not this can be deleted
 
 import (
-	"time"
+	"encoding/binary"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/qonico/cosmos-iot/x/datanode/types"
 )
 
 // GetParams returns the total set of datanode parameters.
-func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
+func (k DataNodeKeeper) GetParams(ctx sdk.Context) (params types.Params) {
 	k.paramspace.GetParamSet(ctx, &params)
 	return params
 }
 
-// SetParams sets the datanode parameters to the param space.
-func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+// SetParams sets the datanode parameters to the param space. The time frame index takes the frame size
+// on the first call, a later change rebuilds it at the end of the block
+func (k DataNodeKeeper) SetParams(ctx sdk.Context, params types.Params) {
 	k.paramspace.SetParamSet(ctx, &params)
+
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.FrameSizeKey) {
+		store.Set(types.FrameSizeKey, sdk.Uint64ToBigEndian(uint64(params.FrameSize)))
+	}
+}
+
+// FrameSize returns the seconds of the time frames indexing the records, which can differ from the
+// frame size parameter until the end of the block it changes on. Stores migrated before the index
+// kept it use the one on the param store
+func (k DataNodeKeeper) FrameSize(ctx sdk.Context) int64 {
+	store := ctx.KVStore(k.storeKey)
+	if bz := store.Get(types.FrameSizeKey); bz != nil {
+		return int64(binary.BigEndian.Uint64(bz))
+	}
+	if bz := k.paramspace.GetRaw(ctx, types.KeyFrameSize); bz != nil {
+		var frameSize int64
+		k.cdc.MustUnmarshalJSON(bz, &frameSize)
+		return frameSize
+	}
+	return types.DefaultFrameSize
+}
+
+// OwnershipOfferDuration returns the seconds an ownership offer can be accepted
+func (k DataNodeKeeper) OwnershipOfferDuration(ctx sdk.Context) (res int64) {
+	k.paramspace.Get(ctx, types.KeyOwnershipOfferDuration, &res)
+	return
+}
+
+// BandwidthBondPerRecord returns the coins bonded for each record of the bandwidth quota
+func (k DataNodeKeeper) BandwidthBondPerRecord(ctx sdk.Context) (res sdk.Coin) {
+	k.paramspace.Get(ctx, types.KeyBandwidthBondPerRecord, &res)
+	return
+}
+
+// BandwidthPeriod returns the seconds of the periods the bandwidth quota is renewed on
+func (k DataNodeKeeper) BandwidthPeriod(ctx sdk.Context) (res int64) {
+	k.paramspace.Get(ctx, types.KeyBandwidthPeriod, &res)
+	return
+}
+
+// BandwidthUnbondingTime returns the seconds the unbonded coins are held
+func (k DataNodeKeeper) BandwidthUnbondingTime(ctx sdk.Context) (res int64) {
+	k.paramspace.Get(ctx, types.KeyBandwidthUnbondingTime, &res)
+	return
+}
+
+// BandwidthGasPerRecord returns the gas a tx written on the bandwidth quota can take for each record
+func (k DataNodeKeeper) BandwidthGasPerRecord(ctx sdk.Context) (res uint64) {
+	k.paramspace.Get(ctx, types.KeyBandwidthGasPerRecord, &res)
+	return
+}
+
+// StorageDepositPerByte returns the coins deposited for each byte of the records stored
+func (k DataNodeKeeper) StorageDepositPerByte(ctx sdk.Context) (res sdk.Coin) {
+	k.paramspace.Get(ctx, types.KeyStorageDepositPerByte, &res)
+	return
+}
+
+// MaxRetention returns the maximum seconds the records are kept after their time frame ends, no maximum when 0
+func (k DataNodeKeeper) MaxRetention(ctx sdk.Context) (res int64) {
+	k.paramspace.Get(ctx, types.KeyMaxRetention, &res)
+	return
+}
+
+// MaxPrunedPerBlock returns the maximum records pruned at the end of a block
+func (k DataNodeKeeper) MaxPrunedPerBlock(ctx sdk.Context) (res uint32) {
+	k.paramspace.Get(ctx, types.KeyMaxPrunedPerBlock, &res)
+	return
 }
-*/
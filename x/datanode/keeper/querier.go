@@ -19,12 +19,53 @@ func NewQuerier(k DataNodeKeeper) sdk.Querier {
 			return queryDataNode(ctx, path[1:], req, k)
 		case types.QueryRecords:
 			return queryRecords(ctx, path[1:], req, k)
+		case types.QueryRecordsRange:
+			return queryRecordsRange(ctx, path[1:], req, k)
+		case types.QueryParams:
+			return queryParams(ctx, k)
+		case types.QueryDataNodes:
+			return queryDataNodes(ctx, path[1:], req, k)
+		case types.QueryOwnerNodes:
+			return queryDataNodesByOwner(ctx, path[1:], req, k)
+		case types.QueryOwnershipOffer:
+			return queryOwnershipOffer(ctx, path[1:], req, k)
+		case types.QueryRoles:
+			return queryRoles(ctx, path[1:], req, k)
+		case types.QueryFleet:
+			return queryFleet(ctx, path[1:], req, k)
+		case types.QueryFleetMembers:
+			return queryFleetMembers(ctx, path[1:], req, k)
+		case types.QueryDeviceType:
+			return queryDeviceType(ctx, path[1:], req, k)
+		case types.QueryDeviceTypes:
+			return queryDeviceTypes(ctx, path[1:], req, k)
+		case types.QueryDeviceTypeNodes:
+			return queryDeviceTypeNodes(ctx, path[1:], req, k)
+		case types.QueryFeeAllowance:
+			return queryFeeAllowance(ctx, path[1:], req, k)
+		case types.QueryBandwidthQuota:
+			return queryBandwidthQuota(ctx, path[1:], req, k)
+		case types.QueryBandwidthUnbonding:
+			return queryBandwidthUnbonding(ctx, path[1:], req, k)
+		case types.QueryStorageDeposit:
+			return queryStorageDeposit(ctx, path[1:], req, k)
+		case types.QueryRollups:
+			return queryRollups(ctx, path[1:], req, k)
 		default:
 			return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "unknown datanode query endpoint")
 		}
 	}
 }
 
+func queryParams(ctx sdk.Context, k DataNodeKeeper) ([]byte, error) {
+	res, err := codec.MarshalJSONIndent(k.cdc, k.GetParams(ctx))
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
 func queryDataNode(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
 	address, err := sdk.AccAddressFromBech32(path[0])
 	if err != nil {
@@ -35,7 +76,12 @@ func queryDataNode(ctx sdk.Context, path []string, req abci.RequestQuery, k Data
 		return nil, err
 	}
 
-	res, err := codec.MarshalJSONIndent(k.cdc, datanode)
+	stats, err := k.GetDataNodeStats(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDataNode{DataNode: *datanode, Stats: stats})
 	if err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
 	}
@@ -43,6 +89,342 @@ func queryDataNode(ctx sdk.Context, path []string, req abci.RequestQuery, k Data
 	return res, nil
 }
 
+func queryDataNodes(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	limit, start, err := parseDataNodesPage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dataNodes, next := k.GetDataNodes(ctx, start, limit)
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDataNodes{DataNodes: dataNodes, Next: next})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryDataNodesByOwner(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected owner")
+	}
+
+	owner, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	limit, start, err := parseDataNodesPage(path[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	dataNodes, next := k.GetDataNodesByOwner(ctx, owner, start, limit)
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDataNodes{DataNodes: dataNodes, Next: next})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryOwnershipOffer(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected datanode")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	offer, err := k.GetOwnershipOffer(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, offer)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryFeeAllowance(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected datanode")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	allowance, err := k.GetFeeAllowance(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	total, period := allowance.Remaining(ctx.BlockTime())
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResFeeAllowance{FeeAllowance: *allowance, RemainingTotal: total, RemainingPeriod: period})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryBandwidthQuota(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected owner")
+	}
+
+	owner, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	bond, quota := k.BandwidthQuota(ctx, owner)
+	remaining := quota - bond.Used
+	if remaining < 0 {
+		remaining = 0
+	}
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResBandwidthQuota{
+		Owner:     owner,
+		Bonded:    bond.Amount,
+		Quota:     quota,
+		Used:      bond.Used,
+		Remaining: remaining,
+		PeriodEnd: bond.PeriodEnd,
+	})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryBandwidthUnbonding(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected owner")
+	}
+
+	owner, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	unbonding := []types.BandwidthUnbonding{}
+	if bond, err := k.GetBandwidthBond(ctx, owner); err == nil {
+		unbonding = bond.Unbonding
+	}
+	res, err := codec.MarshalJSONIndent(k.cdc, unbonding)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryStorageDeposit(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected datanode")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, k.GetStorageDeposit(ctx, address))
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryRollups(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) < 2 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected datanode and channel")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, k.GetRollups(ctx, address, path[1]))
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryRoles(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected datanode")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, k.GetRoleGrants(ctx, address))
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryFleet(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected fleet")
+	}
+
+	fleet, err := k.GetFleet(ctx, path[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, fleet)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryFleetMembers(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected fleet")
+	}
+
+	if !k.IsFleetPresent(ctx, path[0]) {
+		return nil, types.ErrInvalidFleet
+	}
+
+	limit, start, err := parseDataNodesPage(path[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	dataNodes, next := k.GetFleetMembers(ctx, path[0], start, limit)
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDataNodes{DataNodes: dataNodes, Next: next})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryDeviceType(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected device type")
+	}
+
+	var version uint64
+	if len(path) > 1 {
+		var err error
+		version, err = strconv.ParseUint(path[1], 10, 32)
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+		}
+	}
+
+	deviceType, err := k.GetDeviceType(ctx, path[0], uint32(version))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, deviceType)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryDeviceTypes(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	limit, err := parsePageLimit(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var start string
+	if len(path) > 1 {
+		start = path[1]
+	}
+
+	deviceTypes, next := k.GetDeviceTypes(ctx, start, limit)
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDeviceTypes{DeviceTypes: deviceTypes, Next: next})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryDeviceTypeNodes(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected device type")
+	}
+
+	limit, start, err := parseDataNodesPage(path[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	dataNodes, next := k.GetDeviceTypeNodes(ctx, path[0], start, limit)
+
+	res, err := codec.MarshalJSONIndent(k.cdc, types.QueryResDataNodes{DataNodes: dataNodes, Next: next})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+// parsePageLimit - parses the optional limit of the listing queries, the first element of the path
+func parsePageLimit(path []string) (int, error) {
+	if len(path) == 0 {
+		return types.DefaultDataNodesLimit, nil
+	}
+	limit, err := strconv.Atoi(path[0])
+	if err != nil {
+		return 0, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if limit <= 0 || limit > types.MaxDataNodesLimit {
+		return 0, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "limit must be between 1 and %d", types.MaxDataNodesLimit)
+	}
+	return limit, nil
+}
+
+// parseDataNodesPage - parses the optional [limit]/[start] path of the datanodes listing queries
+func parseDataNodesPage(path []string) (int, sdk.AccAddress, error) {
+	limit, err := parsePageLimit(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var start sdk.AccAddress
+	if len(path) > 1 {
+		start, err = sdk.AccAddressFromBech32(path[1])
+		if err != nil {
+			return 0, nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+		}
+	}
+	return limit, start, nil
+}
+
 func queryRecords(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
 	address, err := sdk.AccAddressFromBech32(path[0])
 	if err != nil {
@@ -54,19 +436,25 @@ func queryRecords(ctx sdk.Context, path []string, req abci.RequestQuery, k DataN
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
 
-	records, err := k.GetRecords(ctx, address, path[1], date)
+	unit, err := parseTimeUnit(path, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := k.GetRecords(ctx, address, path[1], date, unit)
 	if err != nil {
 		return nil, sdkerrors.Wrap(types.ErrInvalidDataRecord, err.Error())
 	}
 
+	channel, err := k.GetChannel(ctx, address, path[1])
+	if err != nil {
+		return nil, err
+	}
+
 	var resRecords (types.QueryResRecordsList)
 
 	for _, re := range *records {
-		resRecords = append(resRecords, types.QueryResRecords{
-			TimeStamp: re.TimeStamp,
-			Value:     re.Value,
-			Misc:      re.Misc,
-		})
+		resRecords = append(resRecords, newQueryResRecords(*channel, re))
 	}
 	res, err := codec.MarshalJSONIndent(k.cdc, resRecords)
 	if err != nil {
@@ -75,3 +463,98 @@ func queryRecords(ctx sdk.Context, path []string, req abci.RequestQuery, k DataN
 
 	return res, nil
 }
+
+func queryRecordsRange(ctx sdk.Context, path []string, req abci.RequestQuery, k DataNodeKeeper) ([]byte, error) {
+	if len(path) < 4 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "expected address, channel, from and to")
+	}
+
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	from, err := strconv.ParseInt(path[2], 10, 64)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	to, err := strconv.ParseInt(path[3], 10, 64)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	limit := types.DefaultRecordsRangeLimit
+	if len(path) > 4 {
+		limit, err = strconv.Atoi(path[4])
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+		}
+		if limit <= 0 || limit > types.MaxRecordsRangeLimit {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "limit must be between 1 and %d", types.MaxRecordsRangeLimit)
+		}
+	}
+
+	unit, err := parseTimeUnit(path, 5)
+	if err != nil {
+		return nil, err
+	}
+	if unit == types.TimeUnitFrame {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "records ranges take timestamps in s or ms")
+	}
+	from, _, _ = unit.Millis(from)
+	_, to, _ = unit.Millis(to)
+	if from > to {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "from must not be after to")
+	}
+
+	records, next, err := k.GetRecordsRange(ctx, address, path[1], from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resRange := types.QueryResRecordsRange{
+		Records: types.QueryResRecordsList{},
+		Next:    next,
+	}
+	channel, err := k.GetChannel(ctx, address, path[1])
+	if err != nil {
+		return nil, err
+	}
+	for _, re := range records {
+		resRange.Records = append(resRange.Records, newQueryResRecords(*channel, re))
+	}
+	res, err := codec.MarshalJSONIndent(k.cdc, resRange)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+// parseTimeUnit - parses the optional time unit of the records queries at the index of the path,
+// timestamps are in seconds when not set
+func parseTimeUnit(path []string, index int) (types.TimeUnit, error) {
+	if len(path) <= index {
+		return types.TimeUnitSecond, nil
+	}
+	unit := types.TimeUnit(path[index])
+	switch unit {
+	case types.TimeUnitFrame, types.TimeUnitSecond, types.TimeUnitMilli:
+		return unit, nil
+	}
+	return "", sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid time unit %s", unit)
+}
+
+// newQueryResRecords - returns the query result of the record with its value decoded, records written
+// before a change of the channel encoding may not be decoded
+func newQueryResRecords(channel types.NodeChannel, re types.Record) types.QueryResRecords {
+	decoded, _ := channel.DecodeValue(re)
+	return types.QueryResRecords{
+		TimeStamp: re.TimeStamp,
+		Value:     re.Value,
+		Misc:      re.Misc,
+		Decoded:   decoded,
+		Unit:      channel.Unit,
+	}
+}
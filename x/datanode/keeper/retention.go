@@ -0,0 +1,321 @@
+package keeper
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Retention and rollup methods
+
+// ChannelRetention - seconds the records of the datanode channel are kept after their time frame ends,
+// zero when they are kept forever
+func (k DataNodeKeeper) ChannelRetention(ctx sdk.Context, dataNode types.DataNode, channelID string) int64 {
+	if channel, found := dataNode.GetChannel(channelID); found {
+		return types.ChannelRetention(&channel, k.MaxRetention(ctx))
+	}
+	return types.ChannelRetention(nil, k.MaxRetention(ctx))
+}
+
+// enqueueTimeFrame - queues the time frame of the datanode channel at its expiry, nothing is queued
+// when the records are kept forever
+func (k DataNodeKeeper) enqueueTimeFrame(ctx sdk.Context, address sdk.AccAddress, channelID string, timeFrame int64, retention int64) {
+	if retention == 0 {
+		return
+	}
+	store := ctx.KVStore(k.storeKey)
+	expiry := types.TimeFrameExpiry(timeFrame, k.FrameSize(ctx), retention)
+	store.Set(types.RetentionQueueKey(expiry, address, channelID, uint64(timeFrame)), []byte{})
+}
+
+// requeueChannels - queues the time frames of the channels whose retention changed at their new expiry,
+// the entries left at a former expiry are dropped or moved once reached
+func (k DataNodeKeeper) requeueChannels(ctx sdk.Context, previous types.DataNode, dataNode types.DataNode) {
+	requeued := make(map[string]bool)
+	for _, channels := range [][]types.NodeChannel{previous.Channels, dataNode.Channels} {
+		for _, channel := range channels {
+			if requeued[channel.ID] {
+				continue
+			}
+			requeued[channel.ID] = true
+
+			retention := k.ChannelRetention(ctx, dataNode, channel.ID)
+			if retention == 0 || retention == k.ChannelRetention(ctx, previous, channel.ID) {
+				continue
+			}
+			var timeFrames []int64
+			k.IterateChannelTimeFrames(ctx, dataNode.ID, channel.ID, func(timeFrame int64) bool {
+				timeFrames = append(timeFrames, timeFrame)
+				return false
+			})
+			for _, timeFrame := range timeFrames {
+				k.enqueueTimeFrame(ctx, dataNode.ID, channel.ID, timeFrame, retention)
+			}
+		}
+	}
+}
+
+// queueTimeFrames - queues every time frame indexed at its expiry, skipping the ones of the channels
+// keeping their records forever. It returns the time frames queued
+func (k DataNodeKeeper) queueTimeFrames(ctx sdk.Context) int {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.TimeFramePrefix)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+	}
+	iterator.Close()
+
+	var dataNode *types.DataNode
+	queued := 0
+	for _, key := range keys {
+		address, channelID, timeFrame := types.SplitTimeFrameKey(key)
+		if dataNode == nil || !dataNode.ID.Equals(address) {
+			var err error
+			if dataNode, err = k.GetDataNode(ctx, address); err != nil {
+				continue
+			}
+		}
+		if retention := k.ChannelRetention(ctx, *dataNode, channelID); retention > 0 {
+			k.enqueueTimeFrame(ctx, address, channelID, int64(timeFrame), retention)
+			queued++
+		}
+	}
+	return queued
+}
+
+// ReindexTimeFrames - rebuilds the time frame index, the retention queue and the rollups with the frame
+// size parameter once governance changes it. The rollup of a former time frame is merged into the new
+// time frame holding its start. It returns the time frames indexed, nothing is done while the index
+// uses the frame size parameter
+func (k DataNodeKeeper) ReindexTimeFrames(ctx sdk.Context) int {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.FrameSizeKey) {
+		return 0
+	}
+	var frameSize int64
+	k.paramspace.Get(ctx, types.KeyFrameSize, &frameSize)
+	previous := k.FrameSize(ctx)
+	if frameSize == previous {
+		return 0
+	}
+
+	var keys [][]byte
+	for _, prefix := range [][]byte{types.TimeFramePrefix, types.RetentionQueuePrefix, types.RollupPrefix} {
+		iterator := sdk.KVStorePrefixIterator(store, prefix)
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, append([]byte{}, iterator.Key()...))
+		}
+		iterator.Close()
+	}
+	rollups := k.GetAllRollups(ctx)
+	for _, key := range keys {
+		store.Delete(key)
+	}
+	store.Set(types.FrameSizeKey, sdk.Uint64ToBigEndian(uint64(frameSize)))
+
+	timeFrames := 0
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		address, channelID, timeStamp := types.SplitRecordKey(iterator.Key())
+		timeFrameKey := types.TimeFrameKey(address, channelID, uint64(types.GetTimeFrame(int64(timeStamp)/types.MillisPerSecond, frameSize)))
+		if !store.Has(timeFrameKey) {
+			store.Set(timeFrameKey, []byte{})
+			timeFrames++
+		}
+	}
+	iterator.Close()
+	k.queueTimeFrames(ctx)
+
+	for _, rollup := range rollups {
+		timeFrame := types.GetTimeFrame(rollup.TimeFrame*previous, frameSize)
+		k.SetRollup(ctx, k.GetRollup(ctx, rollup.DataNode, rollup.Channel, timeFrame).Merge(rollup))
+	}
+	ctx.Logger().Info("reindexed datanode time frames", "seconds", frameSize, "timeframes", timeFrames)
+	return timeFrames
+}
+
+// IterateChannelTimeFrames - iterates over the time frames holding records of the datanode channel, sorted
+// by time frame, until cb returns true
+func (k DataNodeKeeper) IterateChannelTimeFrames(ctx sdk.Context, address sdk.AccAddress, channelID string, cb func(timeFrame int64) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.TimeFrameChannelPrefix(address, channelID))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		_, _, timeFrame := types.SplitTimeFrameKey(iterator.Key())
+		if cb(int64(timeFrame)) {
+			break
+		}
+	}
+}
+
+// PruneExpiredRecords - deletes the records of the time frames expired up to the time on the retention
+// queue, up to MaxPrunedPerBlock records. Every queue entry reached takes at least one record from the
+// budget, the time frames partially pruned are kept on the queue to be continued on the next call
+func (k DataNodeKeeper) PruneExpiredRecords(ctx sdk.Context, now time.Time) []types.RecordsPruned {
+	store := ctx.KVStore(k.storeKey)
+	budget := int(k.MaxPrunedPerBlock(ctx))
+
+	var keys [][]byte
+	iterator := store.Iterator(types.RetentionQueuePrefix, sdk.PrefixEndBytes(types.RetentionQueueTimePrefix(now)))
+	for ; iterator.Valid() && len(keys) < budget; iterator.Next() {
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+	}
+	iterator.Close()
+
+	// the retention queue is rebuilt with the time frame index when the frame size changes
+	frameSize := k.FrameSize(ctx)
+	var pruned []types.RecordsPruned
+	for _, key := range keys {
+		if budget <= 0 {
+			break
+		}
+		_, address, channelID, timeFrame := types.SplitRetentionQueueKey(key)
+		dataNode, err := k.GetDataNode(ctx, address)
+		if err != nil || !store.Has(types.TimeFrameKey(address, channelID, timeFrame)) {
+			store.Delete(key)
+			budget--
+			continue
+		}
+
+		// the retention may have changed since the time frame was queued
+		retention := k.ChannelRetention(ctx, *dataNode, channelID)
+		if retention == 0 || types.TimeFrameExpiry(int64(timeFrame), frameSize, retention).After(now) {
+			store.Delete(key)
+			k.enqueueTimeFrame(ctx, address, channelID, int64(timeFrame), retention)
+			budget--
+			continue
+		}
+
+		from := int64(timeFrame) * frameSize * types.MillisPerSecond
+		count, done, refund := k.pruneRecords(ctx, *dataNode, channelID, from, from+frameSize*types.MillisPerSecond, budget)
+		if done {
+			store.Delete(key)
+		}
+		if count > 0 {
+			pruned = append(pruned, types.RecordsPruned{
+				DataNode:  address,
+				Channel:   channelID,
+				TimeFrame: int64(timeFrame),
+				Count:     count,
+				Refund:    refund,
+			})
+			budget -= count
+		} else {
+			budget--
+		}
+	}
+	return pruned
+}
+
+// pruneRecords - deletes up to limit records of the datanode channel with timestamps from the first one
+// (inclusive) to the second one in milliseconds, all of them when the limit is 0, and their time frame
+// index entries once empty. The records deleted are summarized on the rollups of their time frames when
+// the channel keeps them. It returns the records deleted, true when none is left in the range and the
+// storage deposit refunded
+func (k DataNodeKeeper) pruneRecords(ctx sdk.Context, dataNode types.DataNode, channelID string, from int64, before int64, limit int) (int, bool, types.StorageRefund) {
+	if before <= from {
+		return 0, true, k.releaseStorage(ctx, dataNode, 0)
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(types.RecordKey(dataNode.ID, channelID, uint64(from)), types.RecordKey(dataNode.ID, channelID, uint64(before)))
+	var keys, values [][]byte
+	var bytes int64
+	done := true
+	for ; iterator.Valid(); iterator.Next() {
+		if limit > 0 && len(keys) == limit {
+			done = false
+			break
+		}
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		values = append(values, append([]byte{}, iterator.Value()...))
+		bytes += recordBytes(iterator.Key(), iterator.Value())
+	}
+	iterator.Close()
+
+	channel, found := dataNode.GetChannel(channelID)
+	rollup := found && channel.Rollup
+	rollups := make(map[uint64]types.Rollup)
+
+	// the keys are sorted by timestamp, so are their time frames
+	frameSize := k.FrameSize(ctx)
+	var frames []uint64
+	for i, key := range keys {
+		_, _, timeStamp := types.SplitRecordKey(key)
+		frame := uint64(types.GetTimeFrame(int64(timeStamp)/types.MillisPerSecond, frameSize))
+		if len(frames) == 0 || frames[len(frames)-1] != frame {
+			frames = append(frames, frame)
+			if rollup {
+				rollups[frame] = k.GetRollup(ctx, dataNode.ID, channelID, int64(frame))
+			}
+		}
+		if rollup {
+			var record types.Record
+			k.cdc.MustUnmarshalBinaryBare(values[i], &record)
+			rollups[frame] = rollups[frame].Add(record)
+		}
+		store.Delete(key)
+	}
+	for _, frame := range frames {
+		if rollup {
+			k.SetRollup(ctx, rollups[frame])
+		}
+		from := int64(frame) * frameSize * types.MillisPerSecond
+		empty := true
+		k.IterateRecords(ctx, dataNode.ID, channelID, from, from+frameSize*types.MillisPerSecond-1, func(types.Record) bool {
+			empty = false
+			return true
+		})
+		if empty {
+			store.Delete(types.TimeFrameKey(dataNode.ID, channelID, frame))
+		}
+	}
+
+	return len(keys), done, k.releaseStorage(ctx, dataNode, bytes)
+}
+
+// GetRollup - get the rollup of the datanode channel time frame, one without records if it has none
+func (k DataNodeKeeper) GetRollup(ctx sdk.Context, address sdk.AccAddress, channelID string, timeFrame int64) types.Rollup {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.RollupKey(address, channelID, uint64(timeFrame)))
+	if bz == nil {
+		return types.NewRollup(address, channelID, timeFrame)
+	}
+	var rollup types.Rollup
+	k.cdc.MustUnmarshalBinaryBare(bz, &rollup)
+	return rollup
+}
+
+// SetRollup - sets the rollup of the datanode channel time frame
+func (k DataNodeKeeper) SetRollup(ctx sdk.Context, rollup types.Rollup) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.RollupKey(rollup.DataNode, rollup.Channel, uint64(rollup.TimeFrame)), k.cdc.MustMarshalBinaryBare(rollup))
+}
+
+// GetRollups - get the rollups of the datanode channel sorted by time frame
+func (k DataNodeKeeper) GetRollups(ctx sdk.Context, address sdk.AccAddress, channelID string) []types.Rollup {
+	return k.iterateRollups(ctx, types.RollupChannelPrefix(address, channelID))
+}
+
+// GetAllRollups - get the rollups of every datanode
+func (k DataNodeKeeper) GetAllRollups(ctx sdk.Context) []types.Rollup {
+	return k.iterateRollups(ctx, types.RollupPrefix)
+}
+
+// iterateRollups - get the rollups under the store key prefix
+func (k DataNodeKeeper) iterateRollups(ctx sdk.Context, prefix []byte) []types.Rollup {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	rollups := []types.Rollup{}
+	for ; iterator.Valid(); iterator.Next() {
+		var rollup types.Rollup
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &rollup)
+		rollups = append(rollups, rollup)
+	}
+	return rollups
+}
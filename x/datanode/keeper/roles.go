@@ -0,0 +1,110 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Role methods
+
+// GetRoleGrant - get the role grant of the account on the datanode
+func (k DataNodeKeeper) GetRoleGrant(ctx sdk.Context, address sdk.AccAddress, account sdk.AccAddress) (*types.RoleGrant, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.RoleGrantKey(address, account))
+	if bz == nil {
+		return nil, types.ErrNoRoleGrant
+	}
+	var grant types.RoleGrant
+	k.cdc.MustUnmarshalBinaryBare(bz, &grant)
+	return &grant, nil
+}
+
+// SetRoleGrant - sets the role grant, replacing the previous one of the account on the datanode
+func (k DataNodeKeeper) SetRoleGrant(ctx sdk.Context, grant types.RoleGrant) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.RoleGrantKey(grant.DataNode, grant.Address), k.cdc.MustMarshalBinaryBare(grant))
+}
+
+// DeleteRoleGrant - removes the role grant of the account on the datanode
+func (k DataNodeKeeper) DeleteRoleGrant(ctx sdk.Context, address sdk.AccAddress, account sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.RoleGrantKey(address, account))
+}
+
+// DeleteRoleGrants - removes all the role grants on the datanode
+func (k DataNodeKeeper) DeleteRoleGrants(ctx sdk.Context, address sdk.AccAddress) {
+	var accounts []sdk.AccAddress
+	k.IterateRoleGrants(ctx, address, func(grant types.RoleGrant) bool {
+		accounts = append(accounts, grant.Address)
+		return false
+	})
+	for _, account := range accounts {
+		k.DeleteRoleGrant(ctx, address, account)
+	}
+}
+
+// GetRoleGrants - get all the role grants on the datanode
+func (k DataNodeKeeper) GetRoleGrants(ctx sdk.Context, address sdk.AccAddress) []types.RoleGrant {
+	grants := []types.RoleGrant{}
+	k.IterateRoleGrants(ctx, address, func(grant types.RoleGrant) bool {
+		grants = append(grants, grant)
+		return false
+	})
+	return grants
+}
+
+// IterateRoleGrants - iterate over the role grants on the datanode, all of them when address is nil
+func (k DataNodeKeeper) IterateRoleGrants(ctx sdk.Context, address sdk.AccAddress, cb func(grant types.RoleGrant) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.RoleGrantDataNodePrefix(address))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var grant types.RoleGrant
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &grant)
+		if cb(grant) {
+			break
+		}
+	}
+}
+
+// HasPermission - check if the account holds the permission on the datanode at the block time, the
+// owner holds all of them and the other accounts the ones of their active role
+func (k DataNodeKeeper) HasPermission(ctx sdk.Context, address sdk.AccAddress, account sdk.AccAddress, permission types.Permission) bool {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return false
+	}
+	if dataNode.Owner.Equals(account) {
+		return true
+	}
+	grant, err := k.GetRoleGrant(ctx, address, account)
+	if err != nil {
+		return false
+	}
+	return grant.IsActive(ctx.BlockTime()) && grant.Role.Allows(permission)
+}
+
+// CanWriteChannel - check if the account can write the channel of the datanode at the block time
+func (k DataNodeKeeper) CanWriteChannel(ctx sdk.Context, address sdk.AccAddress, account sdk.AccAddress, channelID string) bool {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return false
+	}
+	return k.ChannelWriter(ctx, *dataNode, account)(channelID)
+}
+
+// ChannelWriter - returns the check of the channels of the datanode the account can write at the block
+// time, reading the grant of the account once for the records of a batch
+func (k DataNodeKeeper) ChannelWriter(ctx sdk.Context, dataNode types.DataNode, account sdk.AccAddress) func(channelID string) bool {
+	if dataNode.Owner.Equals(account) {
+		return func(string) bool { return true }
+	}
+	grant, err := k.GetRoleGrant(ctx, dataNode.ID, account)
+	if err != nil {
+		return func(string) bool { return false }
+	}
+	return func(channelID string) bool {
+		return grant.CanWriteChannel(channelID, ctx.BlockTime())
+	}
+}
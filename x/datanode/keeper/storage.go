@@ -0,0 +1,156 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// Storage deposit methods
+
+// GetStorageDeposit - get the storage deposit of the datanode, one without bytes or coins if it has none
+func (k DataNodeKeeper) GetStorageDeposit(ctx sdk.Context, address sdk.AccAddress) types.StorageDeposit {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.StorageDepositKey(address))
+	if bz == nil {
+		return types.NewStorageDeposit(address)
+	}
+	var deposit types.StorageDeposit
+	k.cdc.MustUnmarshalBinaryBare(bz, &deposit)
+	return deposit
+}
+
+// SetStorageDeposit - sets the storage deposit of the datanode, an empty deposit is removed
+func (k DataNodeKeeper) SetStorageDeposit(ctx sdk.Context, deposit types.StorageDeposit) {
+	store := ctx.KVStore(k.storeKey)
+	if deposit.IsEmpty() {
+		store.Delete(types.StorageDepositKey(deposit.DataNode))
+		return
+	}
+	store.Set(types.StorageDepositKey(deposit.DataNode), k.cdc.MustMarshalBinaryBare(deposit))
+}
+
+// IterateStorageDeposits - iterate over all the storage deposits
+func (k DataNodeKeeper) IterateStorageDeposits(ctx sdk.Context, cb func(deposit types.StorageDeposit) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.StorageDepositPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var deposit types.StorageDeposit
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &deposit)
+		if cb(deposit) {
+			break
+		}
+	}
+}
+
+// DepositStorage - charges the storage deposit of the bytes stored by the datanode to the first payer with
+// enough coins, moving them to the storage deposit pool and recording them on its share of the deposit. The payers other than the signer of the records
+// spend the fee allowance of the datanode too, as they do for the tx fees, and are skipped over its caps.
+// It returns the payer, the coins deposited and the allowance caps fully spent, no payer and no coins when
+// the deposits are disabled
+func (k DataNodeKeeper) DepositStorage(ctx sdk.Context, address sdk.AccAddress, payers []sdk.AccAddress, signer sdk.AccAddress, bytes int64) (sdk.AccAddress, sdk.Coins, []string, error) {
+	amount := types.StorageCost(bytes, k.StorageDepositPerByte(ctx))
+	if amount.Empty() {
+		return nil, amount, nil, nil
+	}
+
+	pool := k.supplyKeeper.GetModuleAccount(ctx, types.StorageDepositPoolName).GetAddress()
+	for _, payer := range payers {
+		// a failed transfer leaves no trace, its events and allowance spending included
+		cacheCtx, write := ctx.CacheContext()
+		var caps []string
+		if !payer.Equals(signer) {
+			var err error
+			if caps, err = k.SpendFeeAllowance(cacheCtx, address, amount); err != nil {
+				continue
+			}
+		}
+		if err := k.bankKeeper.SendCoins(cacheCtx, payer, pool, amount); err != nil {
+			continue
+		}
+		write()
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+
+		k.SetStorageDeposit(ctx, k.GetStorageDeposit(ctx, address).Deposit(payer, amount))
+		return payer, amount, caps, nil
+	}
+	return nil, nil, nil, sdkerrors.Wrapf(types.ErrStorageDepositUnpaid, "%s for %d bytes of %s", amount, bytes, address)
+}
+
+// addStoredBytes - adds the bytes written to the store by the records of the datanode to its deposit,
+// deleted bytes are released with releaseStorage
+func (k DataNodeKeeper) addStoredBytes(ctx sdk.Context, address sdk.AccAddress, bytes int64) {
+	if bytes == 0 {
+		return
+	}
+	deposit := k.GetStorageDeposit(ctx, address)
+	deposit.Bytes += bytes
+	k.SetStorageDeposit(ctx, deposit)
+}
+
+// releaseStorage - takes the bytes of the records deleted out of the deposit of the datanode, refunding
+// their coins from the storage deposit pool to the depositors in proportion to their shares. The coins
+// deposited before the depositors were recorded are refunded to the fee payer of the datanode
+func (k DataNodeKeeper) releaseStorage(ctx sdk.Context, dataNode types.DataNode, bytes int64) types.StorageRefund {
+	refund := types.StorageRefund{DataNode: dataNode.ID, Bytes: bytes, Amount: sdk.Coins{}}
+	if bytes == 0 {
+		return refund
+	}
+
+	deposit := k.GetStorageDeposit(ctx, dataNode.ID)
+	refund.Amount, refund.Refunds, deposit = deposit.Release(bytes)
+	k.SetStorageDeposit(ctx, deposit)
+	if refund.Amount.Empty() {
+		return refund
+	}
+
+	// the pool holds every coin deposited
+	pool := k.supplyKeeper.GetModuleAddress(types.StorageDepositPoolName)
+	for i, share := range refund.Refunds {
+		if share.Address.Empty() {
+			refund.Refunds[i].Address = k.GetFeePayer(ctx, dataNode)
+		}
+		if err := k.bankKeeper.SendCoins(ctx, pool, refund.Refunds[i].Address, share.Amount); err != nil {
+			panic(err)
+		}
+	}
+	return refund
+}
+
+// recordBytes - bytes taken on the store by a record entry
+func recordBytes(key []byte, value []byte) int64 {
+	return int64(len(key) + len(value))
+}
+
+// storedBytes - counts the bytes taken by the records of every datanode storing any, sorted by address.
+// The deposits returned have no coins
+func (k DataNodeKeeper) storedBytes(ctx sdk.Context) []types.StorageDeposit {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordPrefix)
+	defer iterator.Close()
+
+	var stored []types.StorageDeposit
+	for ; iterator.Valid(); iterator.Next() {
+		address, _, _ := types.SplitRecordKey(iterator.Key())
+		if len(stored) == 0 || !stored[len(stored)-1].DataNode.Equals(address) {
+			stored = append(stored, types.NewStorageDeposit(address))
+		}
+		stored[len(stored)-1].Bytes += recordBytes(iterator.Key(), iterator.Value())
+	}
+	return stored
+}
+
+// PruneRecords - deletes the records of the datanode channel with timestamps before the given one in
+// milliseconds, and their time frame index entries once empty. The storage deposit of the records is
+// refunded to its depositors. It returns the records deleted and the refund
+func (k DataNodeKeeper) PruneRecords(ctx sdk.Context, address sdk.AccAddress, channelID string, before int64) (int, types.StorageRefund, error) {
+	dataNode, err := k.GetDataNode(ctx, address)
+	if err != nil {
+		return 0, types.StorageRefund{}, err
+	}
+
+	count, _, refund := k.pruneRecords(ctx, *dataNode, channelID, 0, before, 0)
+	return count, refund, nil
+}
@@ -0,0 +1,43 @@
+package keeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/qonico/cosmos-iot/x/datanode/types"
+)
+
+// CreateTestInput returns a context over an in memory store with the given block time and a keeper bound to
+// it with the default params, shared by the keeper and the handler tests
+func CreateTestInput(t testing.TB, blockTime time.Time) (sdk.Context, DataNodeKeeper) {
+	keyDataNode := sdk.NewKVStoreKey(types.StoreKey)
+	keyParams := sdk.NewKVStoreKey(params.StoreKey)
+	tkeyParams := sdk.NewTransientStoreKey(params.TStoreKey)
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyDataNode, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	cdc := codec.New()
+	types.RegisterCodec(cdc)
+	codec.RegisterCrypto(cdc)
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "qonico-test", Time: blockTime}, false, log.NewNopLogger())
+
+	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams)
+	k := NewKeeper(cdc, keyDataNode, paramsKeeper.Subspace(types.DefaultParamspace), nil, nil)
+	k.SetParams(ctx, types.DefaultParams())
+	return ctx, k
+}
@@ -0,0 +1,142 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Fee allowance caps, named on the errors and events when a fee goes over them
+const (
+	FeeCapTx     = "tx"
+	FeeCapTotal  = "total"
+	FeeCapPeriod = "period"
+)
+
+// FeeAllowance - limits of the fees charged to the fee payer of a datanode for the txs it signs,
+// set by the owner. An empty limit doesn't cap the fees
+type FeeAllowance struct {
+	DataNode    sdk.AccAddress `json:"datanode"`     // datanode the allowance applies to
+	TotalLimit  sdk.Coins      `json:"total_limit"`  // maximum fees charged overall
+	PeriodLimit sdk.Coins      `json:"period_limit"` // maximum fees charged on a period
+	Period      int64          `json:"period"`       // seconds of the period, required with a period limit
+	TxLimit     sdk.Coins      `json:"tx_limit"`     // maximum fee of a single tx
+	TotalSpent  sdk.Coins      `json:"total_spent"`  // fees charged since the allowance was set
+	PeriodSpent sdk.Coins      `json:"period_spent"` // fees charged on the current period
+	PeriodEnd   time.Time      `json:"period_end"`   // the current period ends and its spending resets at this time
+}
+
+// NewFeeAllowance creates an allowance of the datanode without spending
+func NewFeeAllowance(dataNode sdk.AccAddress, totalLimit sdk.Coins, periodLimit sdk.Coins, period int64, txLimit sdk.Coins) FeeAllowance {
+	return FeeAllowance{
+		DataNode:    dataNode,
+		TotalLimit:  totalLimit,
+		PeriodLimit: periodLimit,
+		Period:      period,
+		TxLimit:     txLimit,
+	}
+}
+
+// implement fmt.Stringer
+func (a FeeAllowance) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+		DataNode: %s
+		TotalLimit: %s
+		PeriodLimit: %s
+		Period: %d
+		TxLimit: %s
+		TotalSpent: %s
+		PeriodSpent: %s
+		PeriodEnd: %s
+	`, a.DataNode, a.TotalLimit, a.PeriodLimit, a.Period, a.TxLimit, a.TotalSpent, a.PeriodSpent, a.PeriodEnd))
+}
+
+// ValidateFeeAllowance checks the limits of the allowance
+func ValidateFeeAllowance(totalLimit sdk.Coins, periodLimit sdk.Coins, period int64, txLimit sdk.Coins) error {
+	for _, limit := range []sdk.Coins{totalLimit, periodLimit, txLimit} {
+		if !limit.IsValid() && !limit.Empty() {
+			return fmt.Errorf("invalid limit %s", limit)
+		}
+	}
+	if periodLimit.Empty() != (period == 0) {
+		return fmt.Errorf("period and period limit must be set together")
+	}
+	if period < 0 {
+		return fmt.Errorf("negative period %d", period)
+	}
+	return nil
+}
+
+// current returns the allowance with the spending of the period reset when it has ended at the time
+func (a FeeAllowance) current(now time.Time) FeeAllowance {
+	if a.Period > 0 && !now.Before(a.PeriodEnd) {
+		a.PeriodSpent = nil
+		a.PeriodEnd = now.Add(time.Duration(a.Period) * time.Second)
+	}
+	return a
+}
+
+// ExceededCap returns the name of the first cap the fee goes over at the time, empty if it fits
+func (a FeeAllowance) ExceededCap(fee sdk.Coins, now time.Time) string {
+	a = a.current(now)
+	if !a.TxLimit.Empty() && !fee.IsAllLTE(a.TxLimit) {
+		return FeeCapTx
+	}
+	if !a.TotalLimit.Empty() && !a.TotalSpent.Add(fee...).IsAllLTE(a.TotalLimit) {
+		return FeeCapTotal
+	}
+	if !a.PeriodLimit.Empty() && !a.PeriodSpent.Add(fee...).IsAllLTE(a.PeriodLimit) {
+		return FeeCapPeriod
+	}
+	return ""
+}
+
+// Spend returns the allowance after charging the fee at the time, caps are not checked
+func (a FeeAllowance) Spend(fee sdk.Coins, now time.Time) FeeAllowance {
+	a = a.current(now)
+	a.TotalSpent = a.TotalSpent.Add(fee...)
+	if a.Period > 0 {
+		a.PeriodSpent = a.PeriodSpent.Add(fee...)
+	}
+	return a
+}
+
+// ReachedCaps returns the names of the caps fully spent, no more fees of their denoms are accepted
+func (a FeeAllowance) ReachedCaps() []string {
+	var caps []string
+	if !a.TotalLimit.Empty() && a.TotalSpent.IsAnyGTE(a.TotalLimit) {
+		caps = append(caps, FeeCapTotal)
+	}
+	if !a.PeriodLimit.Empty() && a.PeriodSpent.IsAnyGTE(a.PeriodLimit) {
+		caps = append(caps, FeeCapPeriod)
+	}
+	return caps
+}
+
+// Remaining returns the fees that can still be charged overall and on the period at the time, nil
+// when not capped
+func (a FeeAllowance) Remaining(now time.Time) (sdk.Coins, sdk.Coins) {
+	a = a.current(now)
+	var total, period sdk.Coins
+	if !a.TotalLimit.Empty() {
+		total = remaining(a.TotalLimit, a.TotalSpent)
+	}
+	if !a.PeriodLimit.Empty() {
+		period = remaining(a.PeriodLimit, a.PeriodSpent)
+	}
+	return total, period
+}
+
+// remaining returns the coins of the limit not spent
+func remaining(limit sdk.Coins, spent sdk.Coins) sdk.Coins {
+	coins := sdk.Coins{}
+	for _, coin := range limit {
+		left := coin.Amount.Sub(spent.AmountOf(coin.Denom))
+		if left.IsPositive() {
+			coins = append(coins, sdk.NewCoin(coin.Denom, left))
+		}
+	}
+	return coins
+}
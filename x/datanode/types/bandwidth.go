@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// BandwidthBond - coins bonded by an owner, granting its datanodes a quota of records written without
+// fees on every bandwidth period
+type BandwidthBond struct {
+	Owner     sdk.AccAddress       `json:"owner"`      // account bonding the coins
+	Amount    sdk.Coins            `json:"amount"`     // coins bonded, granting the quota
+	Unbonding []BandwidthUnbonding `json:"unbonding"`  // coins unbonded, returned to the owner at their completion time
+	Used      int64                `json:"used"`       // records written without fees on the current period
+	PeriodEnd time.Time            `json:"period_end"` // the current period ends and its usage resets at this time
+}
+
+// BandwidthUnbonding - coins unbonded, they don't grant quota and are held until the completion time
+type BandwidthUnbonding struct {
+	Amount         sdk.Coins `json:"amount"`          // coins unbonded
+	CompletionTime time.Time `json:"completion_time"` // the coins are returned to the owner at this time
+}
+
+// NewBandwidthBond creates a bond of the owner without coins
+func NewBandwidthBond(owner sdk.AccAddress) BandwidthBond {
+	return BandwidthBond{
+		Owner:     owner,
+		Amount:    sdk.Coins{},
+		Unbonding: []BandwidthUnbonding{},
+	}
+}
+
+// implement fmt.Stringer
+func (b BandwidthBond) String() string {
+	var unbonding []string
+	for _, u := range b.Unbonding {
+		unbonding = append(unbonding, fmt.Sprintf("%s at %s", u.Amount, u.CompletionTime))
+	}
+	return strings.TrimSpace(fmt.Sprintf(`
+		Owner: %s
+		Amount: %s
+		Unbonding: %s
+		Used: %d
+		PeriodEnd: %s
+	`, b.Owner, b.Amount, strings.Join(unbonding, ","), b.Used, b.PeriodEnd))
+}
+
+// IsEmpty returns true if the bond holds no coins
+func (b BandwidthBond) IsEmpty() bool {
+	return b.Amount.Empty() && len(b.Unbonding) == 0
+}
+
+// Total returns the coins bonded and unbonding
+func (b BandwidthBond) Total() sdk.Coins {
+	total := b.Amount
+	for _, u := range b.Unbonding {
+		total = total.Add(u.Amount...)
+	}
+	return total
+}
+
+// Quota returns the records that can be written without fees on a period, a record for each bond per
+// record of the bonded coins. A zero bond per record disables the quota
+func (b BandwidthBond) Quota(bondPerRecord sdk.Coin) int64 {
+	if !bondPerRecord.IsPositive() {
+		return 0
+	}
+	quota := b.Amount.AmountOf(bondPerRecord.Denom).Quo(bondPerRecord.Amount)
+	if !quota.IsInt64() {
+		return math.MaxInt64
+	}
+	return quota.Int64()
+}
+
+// Current returns the bond with the usage of the period reset when it has ended at the time, a new
+// period of the given seconds starts then
+func (b BandwidthBond) Current(now time.Time, period int64) BandwidthBond {
+	if !now.Before(b.PeriodEnd) {
+		b.Used = 0
+		b.PeriodEnd = now.Add(time.Duration(period) * time.Second)
+	}
+	return b
+}
+
+// Matured returns the coins of the unbondings completed at the time and the bond without them
+func (b BandwidthBond) Matured(now time.Time) (sdk.Coins, BandwidthBond) {
+	matured := sdk.Coins{}
+	pending := []BandwidthUnbonding{}
+	for _, u := range b.Unbonding {
+		if now.Before(u.CompletionTime) {
+			pending = append(pending, u)
+		} else {
+			matured = matured.Add(u.Amount...)
+		}
+	}
+	b.Unbonding = pending
+	return matured, b
+}
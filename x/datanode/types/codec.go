@@ -7,8 +7,32 @@ import (
 // RegisterCodec registers concrete types on codec
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgSetOwner{}, "datanode/SetOwner", nil)
+	cdc.RegisterConcrete(MsgOfferOwnership{}, "datanode/OfferOwnership", nil)
+	cdc.RegisterConcrete(MsgAcceptOwnership{}, "datanode/AcceptOwnership", nil)
+	cdc.RegisterConcrete(MsgCancelOwnershipOffer{}, "datanode/CancelOwnershipOffer", nil)
+	cdc.RegisterConcrete(MsgUpdateDataNode{}, "datanode/UpdateDataNode", nil)
+	cdc.RegisterConcrete(MsgDeleteDataNode{}, "datanode/DeleteDataNode", nil)
+	cdc.RegisterConcrete(MsgSetBackfill{}, "datanode/SetBackfill", nil)
+	cdc.RegisterConcrete(MsgSetFeeAllowance{}, "datanode/SetFeeAllowance", nil)
+	cdc.RegisterConcrete(MsgSetFeePayer{}, "datanode/SetFeePayer", nil)
+	cdc.RegisterConcrete(MsgBondBandwidth{}, "datanode/BondBandwidth", nil)
+	cdc.RegisterConcrete(MsgUnbondBandwidth{}, "datanode/UnbondBandwidth", nil)
 	cdc.RegisterConcrete(MsgUpdateChannels{}, "datanode/UpdateChannels", nil)
 	cdc.RegisterConcrete(MsgAddRecords{}, "datanode/AddRecords", nil)
+	cdc.RegisterConcrete(MsgPruneRecords{}, "datanode/PruneRecords", nil)
+	cdc.RegisterConcrete(MsgGrantRole{}, "datanode/GrantRole", nil)
+	cdc.RegisterConcrete(MsgRevokeRole{}, "datanode/RevokeRole", nil)
+	cdc.RegisterConcrete(MsgGatewayAddRecords{}, "datanode/GatewayAddRecords", nil)
+	cdc.RegisterConcrete(MsgCreateFleet{}, "datanode/CreateFleet", nil)
+	cdc.RegisterConcrete(MsgSetFleetAdmins{}, "datanode/SetFleetAdmins", nil)
+	cdc.RegisterConcrete(MsgSetFleetFeePayer{}, "datanode/SetFleetFeePayer", nil)
+	cdc.RegisterConcrete(MsgAddFleetMember{}, "datanode/AddFleetMember", nil)
+	cdc.RegisterConcrete(MsgRemoveFleetMember{}, "datanode/RemoveFleetMember", nil)
+	cdc.RegisterConcrete(MsgSetFleetTemplate{}, "datanode/SetFleetTemplate", nil)
+	cdc.RegisterConcrete(MsgPublishDeviceType{}, "datanode/PublishDeviceType", nil)
+	cdc.RegisterConcrete(MsgTransferDeviceType{}, "datanode/TransferDeviceType", nil)
+	cdc.RegisterConcrete(MsgLinkDeviceType{}, "datanode/LinkDeviceType", nil)
+	cdc.RegisterConcrete(MsgUpgradeDataNodes{}, "datanode/UpgradeDataNodes", nil)
 }
 
 // ModuleCdc defines the module codec
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// DeviceType is a version of the channel set shared by the datanodes of a device model
+type DeviceType struct {
+	ID          string         `json:"id"`          // unique id of the device type
+	Version     uint32         `json:"version"`     // version of the device type, starting at 1
+	Owner       sdk.AccAddress `json:"owner"`       // account publishing the versions of the device type
+	Description string         `json:"description"` // free text description of the device type
+	Channels    []NodeChannel  `json:"channels"`    // channels of the datanodes of the device type
+}
+
+// implement fmt.Stringer
+func (t DeviceType) String() string {
+	channels := make([]string, len(t.Channels))
+	for i, ch := range t.Channels {
+		channels[i] = ch.ID + ":" + ch.Variable
+	}
+	return strings.TrimSpace(fmt.Sprintf(`
+		ID: %s
+		Version: %d
+		Owner: %s
+		Description: %s
+		Channels: %s
+	`, t.ID, t.Version, t.Owner, t.Description, strings.Join(channels, ",")))
+}
+
+// HasChannelID returns true if the device type has a channel with the id
+func (t DeviceType) HasChannelID(channelID string) bool {
+	for _, c := range t.Channels {
+		if c.ID == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// Device type limits, enforced on messages and genesis
+const (
+	MaxDeviceTypeIDLength = 64
+)
+
+// ValidateDeviceTypeID checks the device type id is not empty, not longer than MaxDeviceTypeIDLength
+// and only has letters, digits, '-', '_' and '.'
+func ValidateDeviceTypeID(id string) error {
+	return validateIdentifier("device type", id, MaxDeviceTypeIDLength)
+}
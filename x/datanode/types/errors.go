@@ -11,4 +11,36 @@ var (
 	ErrInvalidDataNodeChannel = sdkerrors.Register(ModuleName, 2, "no channel present with the given id on the datanode")
 	// ErrInvalidDataRecord no datarecord present with the given address
 	ErrInvalidDataRecord = sdkerrors.Register(ModuleName, 3, "no datarecord present with the given hash")
+	// ErrTooManyRecords the message has more records than allowed
+	ErrTooManyRecords = sdkerrors.Register(ModuleName, 4, "too many records")
+	// ErrMiscTooLong the miscellaneous data of a record is longer than allowed
+	ErrMiscTooLong = sdkerrors.Register(ModuleName, 5, "misc data too long")
+	// ErrTooManyChannels the datanode has more channels than allowed
+	ErrTooManyChannels = sdkerrors.Register(ModuleName, 6, "too many channels")
+	// ErrInvalidTimestamp the record timestamp is not acceptable
+	ErrInvalidTimestamp = sdkerrors.Register(ModuleName, 7, "invalid record timestamp")
+	// ErrDuplicateRecord a record with the same timestamp is already present on the channel
+	ErrDuplicateRecord = sdkerrors.Register(ModuleName, 8, "duplicate record")
+	// ErrDataNodeArchived the datanode is archived and doesn't accept changes
+	ErrDataNodeArchived = sdkerrors.Register(ModuleName, 9, "datanode is archived")
+	// ErrNoOwnershipOffer no pending ownership offer present for the datanode
+	ErrNoOwnershipOffer = sdkerrors.Register(ModuleName, 10, "no pending ownership offer for the datanode")
+	// ErrNoRoleGrant no role granted to the account on the datanode
+	ErrNoRoleGrant = sdkerrors.Register(ModuleName, 11, "no role granted to the account on the datanode")
+	// ErrInvalidFleet no fleet present with the given id
+	ErrInvalidFleet = sdkerrors.Register(ModuleName, 12, "no fleet present with the given id")
+	// ErrInvalidDeviceType no device type version present with the given id
+	ErrInvalidDeviceType = sdkerrors.Register(ModuleName, 13, "no device type present with the given id and version")
+	// ErrInvalidRecordValue record value doesn't match the channel schema
+	ErrInvalidRecordValue = sdkerrors.Register(ModuleName, 14, "record value doesn't match the channel schema")
+	// ErrNoFeeAllowance no fee allowance set on the datanode
+	ErrNoFeeAllowance = sdkerrors.Register(ModuleName, 15, "no fee allowance set on the datanode")
+	// ErrFeeAllowanceExceeded the tx fee goes over a cap of the datanode fee allowance
+	ErrFeeAllowanceExceeded = sdkerrors.Register(ModuleName, 16, "fee allowance exceeded")
+	// ErrNoBandwidthBond no coins bonded for bandwidth by the owner
+	ErrNoBandwidthBond = sdkerrors.Register(ModuleName, 17, "no bandwidth bond present for the owner")
+	// ErrBandwidthExceeded the records go over the bandwidth quota left on the period
+	ErrBandwidthExceeded = sdkerrors.Register(ModuleName, 18, "bandwidth quota exceeded")
+	// ErrStorageDepositUnpaid none of the fee payers of the datanode can pay the storage deposit of the records
+	ErrStorageDepositUnpaid = sdkerrors.Register(ModuleName, 19, "storage deposit unpaid")
 )
@@ -2,14 +2,80 @@ package types
 
 // datanode module event types
 const (
-	// TODO: Create your event types
-	// EventType<Action>    		= "action"
+	EventTypeDataNodeCreated  = "datanode_created"
+	EventTypeOwnerChanged     = "owner_changed"
+	EventTypeDataNodeUpdated  = "datanode_updated"
+	EventTypeDataNodeDeleted  = "datanode_deleted"
+	EventTypeDataNodeArchived = "datanode_archived"
+	EventTypeBackfillSet      = "backfill_set"
+	EventTypeFeePayerSet      = "fee_payer_set"
 
-	// TODO: Create keys fo your events, the values will be derivided from the msg
-	// AttributeKeyAddress  		= "address"
+	EventTypeFeeAllowanceSet     = "fee_allowance_set"
+	EventTypeFeeAllowanceRemoved = "fee_allowance_removed"
+	EventTypeFeeCapReached       = "fee_cap_reached"
 
-	// TODO: Some events may not have values for that reason you want to emit that something happened.
-	// AttributeValueDoubleSign = "double_sign"
+	EventTypeBandwidthBonded    = "bandwidth_bonded"
+	EventTypeBandwidthUnbonding = "bandwidth_unbonding"
+	EventTypeBandwidthUnbonded  = "bandwidth_unbonded"
 
-	AttributeValueCategory = ModuleName
+	EventTypeStorageDeposited = "storage_deposited"
+	EventTypeStorageRefunded  = "storage_refunded"
+
+	EventTypeOwnershipOffered        = "ownership_offered"
+	EventTypeOwnershipOfferCancelled = "ownership_offer_cancelled"
+	EventTypeOwnershipOfferExpired   = "ownership_offer_expired"
+
+	EventTypeRoleGranted        = "role_granted"
+	EventTypeRoleRevoked        = "role_revoked"
+	EventTypeFleetCreated       = "fleet_created"
+	EventTypeFleetAdminsChanged = "fleet_admins_changed"
+	EventTypeFleetFeePayerSet   = "fleet_fee_payer_set"
+	EventTypeFleetMemberAdded   = "fleet_member_added"
+	EventTypeFleetMemberRemoved = "fleet_member_removed"
+	EventTypeFleetTemplateSet   = "fleet_template_set"
+
+	EventTypeDeviceTypePublished   = "device_type_published"
+	EventTypeDeviceTypeTransferred = "device_type_transferred"
+	EventTypeDeviceTypeLinked      = "device_type_linked"
+	EventTypeDeviceTypeUnlinked    = "device_type_unlinked"
+	EventTypeDeviceTypeUpgraded    = "device_type_upgraded"
+
+	EventTypeChannelSet      = "channel_set"
+	EventTypeChannelDeleted  = "channel_deleted"
+	EventTypeRecordsAdded    = "records_added"
+	EventTypeRecordDuplicate = "record_duplicate"
+	EventTypeRecordRejected  = "record_rejected"
+	EventTypeRecordsPruned   = "records_pruned"
+
+	AttributeKeyDataNode      = "datanode"
+	AttributeKeyOwner         = "owner"
+	AttributeKeyPreviousOwner = "previous_owner"
+	AttributeKeyNewOwner      = "new_owner"
+	AttributeKeyExpiry        = "expiry"
+	AttributeKeyAddress       = "address"
+	AttributeKeyRole          = "role"
+	AttributeKeyFleet         = "fleet"
+	AttributeKeyAdmin         = "admin"
+	AttributeKeyFeePayer      = "fee_payer"
+	AttributeKeyDepositor     = "depositor"
+	AttributeKeyDeviceType    = "device_type"
+	AttributeKeyVersion       = "version"
+	AttributeKeyPrevVersion   = "previous_version"
+	AttributeKeyChannel       = "channel"
+	AttributeKeyVariable      = "variable"
+	AttributeKeyName          = "name"
+	AttributeKeyCount         = "count"
+	AttributeKeyFrom          = "from"
+	AttributeKeyTo            = "to"
+	AttributeKeyEnabled       = "enabled"
+	AttributeKeyIndex         = "index"
+	AttributeKeyTime          = "time"
+	AttributeKeyReason        = "reason"
+	AttributeKeyCap           = "cap"
+	AttributeKeyAmount        = "amount"
+	AttributeKeyCompletion    = "completion_time"
+	AttributeKeyBytes         = "bytes"
+
+	AttributeValueCategory  = ModuleName
+	AttributeValueRetention = "retention"
 )
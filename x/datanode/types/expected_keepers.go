@@ -3,18 +3,33 @@ package types
 import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params"
+	supplyexported "github.com/cosmos/cosmos-sdk/x/supply/exported"
 )
 
 // ParamSubspace defines the expected Subspace interfacace
 type ParamSubspace interface {
 	WithKeyTable(table params.KeyTable) params.Subspace
 	Get(ctx sdk.Context, key []byte, ptr interface{})
+	GetRaw(ctx sdk.Context, key []byte) []byte
+	Has(ctx sdk.Context, key []byte) bool
+	Set(ctx sdk.Context, key []byte, value interface{})
 	GetParamSet(ctx sdk.Context, ps params.ParamSet)
 	SetParamSet(ctx sdk.Context, ps params.ParamSet)
 }
 
-// BankKeeper we expect to be able to substract and send coins for DataNode transfers and DataRecord append
+// BankKeeper we expect to be able to substract and send coins for DataNode transfers and DataRecord append,
+// the storage deposits of the records are sent to and from the storage deposit pool
 type BankKeeper interface {
+	GetCoins(ctx sdk.Context, addr sdk.AccAddress) sdk.Coins
 	SubtractCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) (sdk.Coins, error)
 	SendCoins(ctx sdk.Context, fromAddr sdk.AccAddress, toAddr sdk.AccAddress, amt sdk.Coins) error
 }
+
+// SupplyKeeper we expect to be able to hold the coins bonded by the owners on the module account, and
+// to provide the storage deposit pool account
+type SupplyKeeper interface {
+	GetModuleAddress(moduleName string) sdk.AccAddress
+	GetModuleAccount(ctx sdk.Context, moduleName string) supplyexported.ModuleAccountI
+	SendCoinsFromAccountToModule(ctx sdk.Context, senderAddr sdk.AccAddress, recipientModule string, amt sdk.Coins) error
+	SendCoinsFromModuleToAccount(ctx sdk.Context, senderModule string, recipientAddr sdk.AccAddress, amt sdk.Coins) error
+}
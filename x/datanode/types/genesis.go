@@ -1,31 +1,111 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
 
 // GenesisState - all datanode state that must be provided at genesis
 type GenesisState struct {
-	DataNodes   []DataNode   `json:"datanodes"`
-	DataRecords []DataRecord `json:"datarecords"`
+	Params          Params           `json:"params"`
+	DataNodes       []DataNode       `json:"datanodes"`
+	DataRecords     []DataRecord     `json:"datarecords"`
+	OwnershipOffers []OwnershipOffer `json:"ownership_offers"`
+	RoleGrants      []RoleGrant      `json:"role_grants"`
+	Fleets          []Fleet          `json:"fleets"`
+	DeviceTypes     []DeviceType     `json:"device_types"`
+	FeeAllowances   []FeeAllowance   `json:"fee_allowances"`
+	BandwidthBonds  []BandwidthBond  `json:"bandwidth_bonds"`
+	StorageDeposits []StorageDeposit `json:"storage_deposits"`
+	Rollups         []Rollup         `json:"rollups"`
 }
 
 // NewGenesisState creates a new GenesisState object
-func NewGenesisState() GenesisState {
+func NewGenesisState(params Params, dataNodes []DataNode, dataRecords []DataRecord, ownershipOffers []OwnershipOffer, roleGrants []RoleGrant, fleets []Fleet, deviceTypes []DeviceType, feeAllowances []FeeAllowance, bandwidthBonds []BandwidthBond, storageDeposits []StorageDeposit, rollups []Rollup) GenesisState {
 	return GenesisState{
-		DataNodes:   nil,
-		DataRecords: nil,
+		Params:          params,
+		DataNodes:       dataNodes,
+		DataRecords:     dataRecords,
+		OwnershipOffers: ownershipOffers,
+		RoleGrants:      roleGrants,
+		Fleets:          fleets,
+		DeviceTypes:     deviceTypes,
+		FeeAllowances:   feeAllowances,
+		BandwidthBonds:  bandwidthBonds,
+		StorageDeposits: storageDeposits,
+		Rollups:         rollups,
 	}
 }
 
 // DefaultGenesisState - default GenesisState used by Cosmos Hub
 func DefaultGenesisState() GenesisState {
 	return GenesisState{
-		DataNodes:   []DataNode{},
-		DataRecords: []DataRecord{},
+		Params:          DefaultParams(),
+		DataNodes:       []DataNode{},
+		DataRecords:     []DataRecord{},
+		OwnershipOffers: []OwnershipOffer{},
+		RoleGrants:      []RoleGrant{},
+		Fleets:          []Fleet{},
+		DeviceTypes:     []DeviceType{},
+		FeeAllowances:   []FeeAllowance{},
+		BandwidthBonds:  []BandwidthBond{},
+		StorageDeposits: []StorageDeposit{},
+		Rollups:         []Rollup{},
 	}
 }
 
 // ValidateGenesis validates the datanode genesis parameters
 func ValidateGenesis(data GenesisState) error {
+	if err := data.Params.Validate(); err != nil {
+		return err
+	}
+
+	fleets := make(map[string]bool)
+	for _, f := range data.Fleets {
+		if err := ValidateFleetID(f.ID); err != nil {
+			return fmt.Errorf("invalid Fleet: ID: %s. Error: %s", f.ID, err)
+		}
+		if fleets[f.ID] {
+			return fmt.Errorf("invalid Fleet: ID: %s. Error: Duplicated ID", f.ID)
+		}
+		if err := ValidateFleetAdmins(f.Admins); err != nil {
+			return fmt.Errorf("invalid Fleet: ID: %s. Error: %s", f.ID, err)
+		}
+		if err := ValidateChannels(f.ChannelTemplate); err != nil {
+			return fmt.Errorf("invalid Fleet: ID: %s. Error: %s", f.ID, err)
+		}
+		fleets[f.ID] = true
+	}
+
+	// owner of every device type id and its versions
+	deviceTypeOwners := make(map[string]string)
+	deviceTypes := make(map[string]bool)
+	for _, t := range data.DeviceTypes {
+		if err := ValidateDeviceTypeID(t.ID); err != nil {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: %s", t.ID, err)
+		}
+		if t.Version == 0 {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: Missing Version", t.ID)
+		}
+		key := fmt.Sprintf("%s/%d", t.ID, t.Version)
+		if deviceTypes[key] {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: Duplicated Version %d", t.ID, t.Version)
+		}
+		if t.Owner.Empty() {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: Missing Owner", t.ID)
+		}
+		if owner, ok := deviceTypeOwners[t.ID]; ok && owner != t.Owner.String() {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: Versions with different Owners", t.ID)
+		}
+		if err := ValidateChannels(t.Channels); err != nil {
+			return fmt.Errorf("invalid DeviceType: ID: %s. Error: %s", t.ID, err)
+		}
+		deviceTypeOwners[t.ID] = t.Owner.String()
+		deviceTypes[key] = true
+	}
+
+	dataNodes := make(map[string]DataNode)
 	for _, dn := range data.DataNodes {
 		if dn.ID == nil {
 			return fmt.Errorf("invalid DataNode: Owner: %s. Error: Missing ID", dn.Owner)
@@ -33,8 +113,32 @@ func ValidateGenesis(data GenesisState) error {
 		if dn.Owner == nil {
 			return fmt.Errorf("invalid DataNode: ID: %s. Error: Missing Owner", dn.ID)
 		}
+		if _, ok := dataNodes[dn.ID.String()]; ok {
+			return fmt.Errorf("invalid DataNode: ID: %s. Error: Duplicated ID", dn.ID)
+		}
+		if err := ValidateMetadata(dn.Name, dn.Description, dn.Tags, dn.Location, dn.FirmwareVersion); err != nil {
+			return fmt.Errorf("invalid DataNode: ID: %s. Error: %s", dn.ID, err)
+		}
+		channels := make(map[string]bool)
+		for _, ch := range dn.Channels {
+			if err := ValidateChannel(ch); err != nil {
+				return fmt.Errorf("invalid DataNode: ID: %s. Error: %s", dn.ID, err)
+			}
+			if channels[ch.ID] {
+				return fmt.Errorf("invalid DataNode: ID: %s. Error: Duplicated ChannelID %s", dn.ID, ch.ID)
+			}
+			channels[ch.ID] = true
+		}
+		if dn.Fleet != "" && !fleets[dn.Fleet] {
+			return fmt.Errorf("invalid DataNode: ID: %s. Error: Unknown Fleet %s", dn.ID, dn.Fleet)
+		}
+		if dn.DeviceType != "" && !deviceTypes[fmt.Sprintf("%s/%d", dn.DeviceType, dn.TypeVersion)] {
+			return fmt.Errorf("invalid DataNode: ID: %s. Error: Unknown DeviceType %s version %d", dn.ID, dn.DeviceType, dn.TypeVersion)
+		}
+		dataNodes[dn.ID.String()] = dn
 	}
 
+	dataRecords := make(map[string]bool)
 	for _, dr := range data.DataRecords {
 		if dr.DataNode == nil {
 			return fmt.Errorf("invalid DataRecord: ChannelID: %s:%s. Error: Missing DataNode", dr.NodeChannel.ID, dr.NodeChannel.Variable)
@@ -45,6 +149,156 @@ func ValidateGenesis(data GenesisState) error {
 		if len(dr.Records) == 0 {
 			return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: No Records", dr.DataNode)
 		}
+
+		dn, ok := dataNodes[dr.DataNode.String()]
+		if !ok {
+			return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: Unknown DataNode", dr.DataNode)
+		}
+		// the records of a deleted channel keep only its id, without variable
+		if dn.HasChannelID(dr.NodeChannel.ID) && !dn.HasChannel(dr.NodeChannel) ||
+			!dn.HasChannelID(dr.NodeChannel.ID) && dr.NodeChannel.Variable != "" {
+			return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: Unknown Channel %s:%s", dr.DataNode, dr.NodeChannel.ID, dr.NodeChannel.Variable)
+		}
+
+		key := fmt.Sprintf("%s/%s/%d", dr.DataNode, dr.NodeChannel.ID, dr.TimeFrame)
+		if dataRecords[key] {
+			return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: Duplicated TimeFrame %d on Channel %s", dr.DataNode, dr.TimeFrame, dr.NodeChannel.ID)
+		}
+		dataRecords[key] = true
+
+		for i, r := range dr.Records {
+			if i > 0 && r.TimeStamp <= dr.Records[i-1].TimeStamp {
+				return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: Unsorted TimeStamp %d", dr.DataNode, r.TimeStamp)
+			}
+			if GetTimeFrame(r.Unix(), data.Params.FrameSize) != dr.TimeFrame {
+				return fmt.Errorf("invalid DataRecord: DataNode: %s. Error: TimeStamp %d out of TimeFrame %d", dr.DataNode, r.TimeStamp, dr.TimeFrame)
+			}
+		}
+	}
+
+	offers := make(map[string]bool)
+	for _, o := range data.OwnershipOffers {
+		dn, ok := dataNodes[o.DataNode.String()]
+		if !ok {
+			return fmt.Errorf("invalid OwnershipOffer: DataNode: %s. Error: Unknown DataNode", o.DataNode)
+		}
+		if !dn.Owner.Equals(o.Owner) {
+			return fmt.Errorf("invalid OwnershipOffer: DataNode: %s. Error: Owner %s doesn't own the DataNode", o.DataNode, o.Owner)
+		}
+		if o.NewOwner.Empty() || o.NewOwner.Equals(o.Owner) {
+			return fmt.Errorf("invalid OwnershipOffer: DataNode: %s. Error: Invalid NewOwner %s", o.DataNode, o.NewOwner)
+		}
+		if offers[o.DataNode.String()] {
+			return fmt.Errorf("invalid OwnershipOffer: DataNode: %s. Error: Duplicated Offer", o.DataNode)
+		}
+		offers[o.DataNode.String()] = true
+	}
+
+	grants := make(map[string]bool)
+	for _, g := range data.RoleGrants {
+		if _, ok := dataNodes[g.DataNode.String()]; !ok {
+			return fmt.Errorf("invalid RoleGrant: DataNode: %s. Error: Unknown DataNode", g.DataNode)
+		}
+		if g.Address.Empty() {
+			return fmt.Errorf("invalid RoleGrant: DataNode: %s. Error: Missing Address", g.DataNode)
+		}
+		if !g.Role.IsValid() {
+			return fmt.Errorf("invalid RoleGrant: DataNode: %s. Error: Invalid Role %s", g.DataNode, g.Role)
+		}
+		if len(g.Channels) > 0 && g.Role != RoleWriter {
+			return fmt.Errorf("invalid RoleGrant: DataNode: %s. Error: Channels on Role %s", g.DataNode, g.Role)
+		}
+		key := g.DataNode.String() + g.Address.String()
+		if grants[key] {
+			return fmt.Errorf("invalid RoleGrant: DataNode: %s. Error: Duplicated Address %s", g.DataNode, g.Address)
+		}
+		grants[key] = true
+	}
+
+	allowances := make(map[string]bool)
+	for _, a := range data.FeeAllowances {
+		if _, ok := dataNodes[a.DataNode.String()]; !ok {
+			return fmt.Errorf("invalid FeeAllowance: DataNode: %s. Error: Unknown DataNode", a.DataNode)
+		}
+		if err := ValidateFeeAllowance(a.TotalLimit, a.PeriodLimit, a.Period, a.TxLimit); err != nil {
+			return fmt.Errorf("invalid FeeAllowance: DataNode: %s. Error: %s", a.DataNode, err)
+		}
+		if allowances[a.DataNode.String()] {
+			return fmt.Errorf("invalid FeeAllowance: DataNode: %s. Error: Duplicated Allowance", a.DataNode)
+		}
+		allowances[a.DataNode.String()] = true
+	}
+
+	bonds := make(map[string]bool)
+	for _, b := range data.BandwidthBonds {
+		if b.Owner.Empty() {
+			return fmt.Errorf("invalid BandwidthBond: Error: Missing Owner")
+		}
+		if !b.Amount.IsValid() && !b.Amount.Empty() {
+			return fmt.Errorf("invalid BandwidthBond: Owner: %s. Error: Invalid Amount %s", b.Owner, b.Amount)
+		}
+		for _, u := range b.Unbonding {
+			if !u.Amount.IsValid() || u.Amount.Empty() {
+				return fmt.Errorf("invalid BandwidthBond: Owner: %s. Error: Invalid Unbonding Amount %s", b.Owner, u.Amount)
+			}
+		}
+		if b.Used < 0 {
+			return fmt.Errorf("invalid BandwidthBond: Owner: %s. Error: Negative Used %d", b.Owner, b.Used)
+		}
+		if bonds[b.Owner.String()] {
+			return fmt.Errorf("invalid BandwidthBond: Owner: %s. Error: Duplicated Bond", b.Owner)
+		}
+		bonds[b.Owner.String()] = true
+	}
+
+	deposits := make(map[string]bool)
+	for _, d := range data.StorageDeposits {
+		if d.DataNode.Empty() {
+			return fmt.Errorf("invalid StorageDeposit: Error: Missing DataNode")
+		}
+		if _, ok := dataNodes[d.DataNode.String()]; !ok {
+			return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Unknown DataNode", d.DataNode)
+		}
+		if !d.Amount.IsValid() && !d.Amount.Empty() {
+			return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Invalid Amount %s", d.DataNode, d.Amount)
+		}
+		if d.Bytes < 0 {
+			return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Negative Bytes %d", d.DataNode, d.Bytes)
+		}
+		depositors := make(map[string]bool)
+		deposited := sdk.Coins{}
+		for _, depositor := range d.Depositors {
+			if depositor.Address.Empty() || depositors[depositor.Address.String()] {
+				return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Missing or Duplicated Depositor %s", d.DataNode, depositor.Address)
+			}
+			if !depositor.Amount.IsValid() || depositor.Amount.Empty() {
+				return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Invalid Depositor Amount %s", d.DataNode, depositor.Amount)
+			}
+			depositors[depositor.Address.String()] = true
+			deposited = deposited.Add(depositor.Amount...)
+		}
+		if !d.Amount.IsAllGTE(deposited) {
+			return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Depositors Amount %s over %s", d.DataNode, deposited, d.Amount)
+		}
+		if deposits[d.DataNode.String()] {
+			return fmt.Errorf("invalid StorageDeposit: DataNode: %s. Error: Duplicated Deposit", d.DataNode)
+		}
+		deposits[d.DataNode.String()] = true
+	}
+
+	rollups := make(map[string]bool)
+	for _, r := range data.Rollups {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid Rollup: Error: %s", err)
+		}
+		if _, ok := dataNodes[r.DataNode.String()]; !ok {
+			return fmt.Errorf("invalid Rollup: DataNode: %s. Error: Unknown DataNode", r.DataNode)
+		}
+		key := fmt.Sprintf("%s/%s/%d", r.DataNode, r.Channel, r.TimeFrame)
+		if rollups[key] {
+			return fmt.Errorf("invalid Rollup: DataNode: %s. Error: Duplicated TimeFrame %d on Channel %s", r.DataNode, r.TimeFrame, r.Channel)
+		}
+		rollups[key] = true
 	}
 	return nil
 }
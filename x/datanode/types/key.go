@@ -1,5 +1,12 @@
 package types
 
+import (
+	"encoding/binary"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
 const (
 	// ModuleName is the name of the module
 	ModuleName = "datanode"
@@ -7,9 +14,296 @@ const (
 	// StoreKey to be used when creating the KVStore
 	StoreKey = ModuleName
 
-  // RouterKey to be used for routing msgs
-  RouterKey = ModuleName
+	// RouterKey to be used for routing msgs
+	RouterKey = ModuleName
 
-  // QuerierRoute to be used for querierer msgs
+	// QuerierRoute to be used for querierer msgs
 	QuerierRoute = ModuleName
 )
+
+// KVStore key prefixes, every entry of the datanode store starts with one of them
+//
+// - 0x01<address>: DataNode
+// - 0x02<hash>: DataRecord, legacy daily records, moved to single records by UpgradeRecordsLayout
+// - 0x03<address><len(channel)><channel><timestamp>: Record
+// - 0x04<address><len(channel)><channel><timeframe>: time frame index, present when the frame has records
+// - 0x05<owner><address>: owner index, present when the owner owns the datanode
+// - 0x06<address>: OwnershipOffer, pending ownership offer of the datanode
+// - 0x07<expiry><address>: ownership offers queue, sorted by expiry time
+// - 0x08<address><account>: RoleGrant of the account on the datanode
+// - 0x09<len(id)><id>: Fleet
+// - 0x0A<len(id)><id><address>: fleet members index, present when the datanode is member of the fleet
+// - 0x0B<len(id)><id><version>: DeviceType version
+// - 0x0C<len(id)><id><address>: device type index, present when the datanode is linked to the device type
+// - 0x0D<address>: FeeAllowance of the datanode
+// - 0x0E<owner>: BandwidthBond of the owner
+// - 0x0F<completion><owner>: bandwidth unbonding queue, present when the owner has coins unbonding up to the time
+// - 0x10<address>: StorageDeposit of the datanode
+// - 0x11<expiry><address><len(channel)><channel><timeframe>: retention queue, present when the time frame of the channel expires at the time
+// - 0x12<address><len(channel)><channel><timeframe>: Rollup of a time frame pruned
+// - 0x13: frame size the time frame index is built with
+var (
+	DataNodePrefix   = []byte{0x01}
+	DataRecordPrefix = []byte{0x02}
+	RecordPrefix     = []byte{0x03}
+	TimeFramePrefix  = []byte{0x04}
+	OwnerPrefix      = []byte{0x05}
+
+	OwnershipOfferPrefix      = []byte{0x06}
+	OwnershipOfferQueuePrefix = []byte{0x07}
+	RoleGrantPrefix           = []byte{0x08}
+	FleetPrefix               = []byte{0x09}
+	FleetMemberPrefix         = []byte{0x0A}
+	DeviceTypePrefix          = []byte{0x0B}
+	DeviceTypeNodePrefix      = []byte{0x0C}
+	FeeAllowancePrefix        = []byte{0x0D}
+	BandwidthBondPrefix       = []byte{0x0E}
+	BandwidthUnbondingPrefix  = []byte{0x0F}
+	StorageDepositPrefix      = []byte{0x10}
+	RetentionQueuePrefix      = []byte{0x11}
+	RollupPrefix              = []byte{0x12}
+	FrameSizeKey              = []byte{0x13}
+)
+
+// DataNodeKey returns the store key of the datanode with the given address
+func DataNodeKey(address sdk.AccAddress) []byte {
+	return prefixKey(DataNodePrefix, address.Bytes())
+}
+
+// DataRecordKey returns the store key of the datarecord with the given hash
+func DataRecordKey(hash DataRecordHash) []byte {
+	return prefixKey(DataRecordPrefix, hash[:])
+}
+
+// RecordDataNodePrefix returns the store key prefix of all the records of the datanode
+func RecordDataNodePrefix(address sdk.AccAddress) []byte {
+	return prefixKey(RecordPrefix, address.Bytes())
+}
+
+// RecordChannelPrefix returns the store key prefix of all the records of the datanode channel
+func RecordChannelPrefix(address sdk.AccAddress, channelID string) []byte {
+	return channelKey(RecordPrefix, address, channelID)
+}
+
+// RecordKey returns the store key of the record of the datanode channel at the timestamp,
+// records of a channel are sorted by timestamp to iterate time ranges
+func RecordKey(address sdk.AccAddress, channelID string, timeStamp uint64) []byte {
+	return append(RecordChannelPrefix(address, channelID), sdk.Uint64ToBigEndian(timeStamp)...)
+}
+
+// SplitRecordKey returns the datanode address, the channel id and the timestamp of a record key
+func SplitRecordKey(key []byte) (sdk.AccAddress, string, uint64) {
+	return splitChannelKey(RecordPrefix, key)
+}
+
+// TimeFrameDataNodePrefix returns the store key prefix of the time frame index of the datanode
+func TimeFrameDataNodePrefix(address sdk.AccAddress) []byte {
+	return prefixKey(TimeFramePrefix, address.Bytes())
+}
+
+// TimeFrameChannelPrefix returns the store key prefix of the time frame index of the datanode channel
+func TimeFrameChannelPrefix(address sdk.AccAddress, channelID string) []byte {
+	return channelKey(TimeFramePrefix, address, channelID)
+}
+
+// TimeFrameKey returns the store key of the time frame index entry of the datanode channel
+func TimeFrameKey(address sdk.AccAddress, channelID string, timeFrame uint64) []byte {
+	return append(channelKey(TimeFramePrefix, address, channelID), sdk.Uint64ToBigEndian(timeFrame)...)
+}
+
+// SplitTimeFrameKey returns the datanode address, the channel id and the time frame of a time frame index key
+func SplitTimeFrameKey(key []byte) (sdk.AccAddress, string, uint64) {
+	return splitChannelKey(TimeFramePrefix, key)
+}
+
+// OwnerDataNodesPrefix returns the store key prefix of the owner index entries of the owner
+func OwnerDataNodesPrefix(owner sdk.AccAddress) []byte {
+	return prefixKey(OwnerPrefix, owner.Bytes())
+}
+
+// OwnerDataNodeKey returns the store key of the owner index entry of the datanode
+func OwnerDataNodeKey(owner sdk.AccAddress, address sdk.AccAddress) []byte {
+	return prefixKey(OwnerDataNodesPrefix(owner), address.Bytes())
+}
+
+// SplitOwnerDataNodeKey returns the owner and the datanode address of an owner index key
+func SplitOwnerDataNodeKey(key []byte) (sdk.AccAddress, sdk.AccAddress) {
+	return sdk.AccAddress(key[len(OwnerPrefix) : len(OwnerPrefix)+sdk.AddrLen]), sdk.AccAddress(key[len(OwnerPrefix)+sdk.AddrLen:])
+}
+
+// OwnershipOfferKey returns the store key of the pending ownership offer of the datanode
+func OwnershipOfferKey(address sdk.AccAddress) []byte {
+	return prefixKey(OwnershipOfferPrefix, address.Bytes())
+}
+
+// OwnershipOfferQueueTimePrefix returns the store key prefix of the ownership offers expiring at the time
+func OwnershipOfferQueueTimePrefix(expiry time.Time) []byte {
+	return prefixKey(OwnershipOfferQueuePrefix, sdk.FormatTimeBytes(expiry))
+}
+
+// OwnershipOfferQueueKey returns the store key of the ownership offer of the datanode on the expiry queue
+func OwnershipOfferQueueKey(expiry time.Time, address sdk.AccAddress) []byte {
+	return prefixKey(OwnershipOfferQueueTimePrefix(expiry), address.Bytes())
+}
+
+// SplitOwnershipOfferQueueKey returns the datanode address of an ownership offers queue key
+func SplitOwnershipOfferQueueKey(key []byte) sdk.AccAddress {
+	return sdk.AccAddress(key[len(OwnershipOfferQueueTimePrefix(time.Time{})):])
+}
+
+// RoleGrantDataNodePrefix returns the store key prefix of the role grants of the datanode
+func RoleGrantDataNodePrefix(address sdk.AccAddress) []byte {
+	return prefixKey(RoleGrantPrefix, address.Bytes())
+}
+
+// RoleGrantKey returns the store key of the role grant of the account on the datanode
+func RoleGrantKey(address sdk.AccAddress, account sdk.AccAddress) []byte {
+	return prefixKey(RoleGrantDataNodePrefix(address), account.Bytes())
+}
+
+// FleetKey returns the store key of the fleet with the given id
+func FleetKey(id string) []byte {
+	return identifierKey(FleetPrefix, id)
+}
+
+// FleetMembersPrefix returns the store key prefix of the members index entries of the fleet
+func FleetMembersPrefix(id string) []byte {
+	return identifierKey(FleetMemberPrefix, id)
+}
+
+// FleetMemberKey returns the store key of the members index entry of the datanode on the fleet
+func FleetMemberKey(id string, address sdk.AccAddress) []byte {
+	return prefixKey(FleetMembersPrefix(id), address.Bytes())
+}
+
+// SplitFleetMemberKey returns the fleet id and the datanode address of a fleet members index key
+func SplitFleetMemberKey(key []byte) (string, sdk.AccAddress) {
+	id, address := splitIdentifierKey(FleetMemberPrefix, key)
+	return id, sdk.AccAddress(address)
+}
+
+// DeviceTypeVersionsPrefix returns the store key prefix of the versions of the device type
+func DeviceTypeVersionsPrefix(id string) []byte {
+	return identifierKey(DeviceTypePrefix, id)
+}
+
+// DeviceTypeKey returns the store key of the version of the device type, versions of a device type
+// are sorted to find the latest one
+func DeviceTypeKey(id string, version uint32) []byte {
+	return append(DeviceTypeVersionsPrefix(id), sdk.Uint64ToBigEndian(uint64(version))...)
+}
+
+// DeviceTypeNodesPrefix returns the store key prefix of the index entries of the device type
+func DeviceTypeNodesPrefix(id string) []byte {
+	return identifierKey(DeviceTypeNodePrefix, id)
+}
+
+// DeviceTypeNodeKey returns the store key of the index entry of the datanode on the device type
+func DeviceTypeNodeKey(id string, address sdk.AccAddress) []byte {
+	return prefixKey(DeviceTypeNodesPrefix(id), address.Bytes())
+}
+
+// SplitDeviceTypeNodeKey returns the device type id and the datanode address of a device type index key
+func SplitDeviceTypeNodeKey(key []byte) (string, sdk.AccAddress) {
+	id, address := splitIdentifierKey(DeviceTypeNodePrefix, key)
+	return id, sdk.AccAddress(address)
+}
+
+// FeeAllowanceKey returns the store key of the fee allowance of the datanode
+func FeeAllowanceKey(address sdk.AccAddress) []byte {
+	return prefixKey(FeeAllowancePrefix, address.Bytes())
+}
+
+// BandwidthBondKey returns the store key of the bandwidth bond of the owner
+func BandwidthBondKey(owner sdk.AccAddress) []byte {
+	return prefixKey(BandwidthBondPrefix, owner.Bytes())
+}
+
+// BandwidthUnbondingTimePrefix returns the store key prefix of the bandwidth unbondings completing at the time
+func BandwidthUnbondingTimePrefix(completion time.Time) []byte {
+	return prefixKey(BandwidthUnbondingPrefix, sdk.FormatTimeBytes(completion))
+}
+
+// BandwidthUnbondingKey returns the store key of an unbonding of the owner on the bandwidth unbonding queue
+func BandwidthUnbondingKey(completion time.Time, owner sdk.AccAddress) []byte {
+	return prefixKey(BandwidthUnbondingTimePrefix(completion), owner.Bytes())
+}
+
+// SplitBandwidthUnbondingKey returns the owner address of a bandwidth unbonding queue key
+func SplitBandwidthUnbondingKey(key []byte) sdk.AccAddress {
+	return sdk.AccAddress(key[len(BandwidthUnbondingTimePrefix(time.Time{})):])
+}
+
+// StorageDepositKey returns the store key of the storage deposit of the datanode
+func StorageDepositKey(address sdk.AccAddress) []byte {
+	return prefixKey(StorageDepositPrefix, address.Bytes())
+}
+
+// RetentionQueueTimePrefix returns the store key prefix of the time frames expiring at the time
+func RetentionQueueTimePrefix(expiry time.Time) []byte {
+	return prefixKey(RetentionQueuePrefix, sdk.FormatTimeBytes(expiry))
+}
+
+// RetentionQueueKey returns the store key of the time frame of the datanode channel expiring at the time on the retention queue
+func RetentionQueueKey(expiry time.Time, address sdk.AccAddress, channelID string, timeFrame uint64) []byte {
+	return append(channelKey(RetentionQueueTimePrefix(expiry), address, channelID), sdk.Uint64ToBigEndian(timeFrame)...)
+}
+
+// SplitRetentionQueueKey returns the expiry, the datanode address, the channel id and the time frame of a retention queue key
+func SplitRetentionQueueKey(key []byte) (time.Time, sdk.AccAddress, string, uint64) {
+	prefix := RetentionQueueTimePrefix(time.Time{})
+	expiry, err := sdk.ParseTimeBytes(key[1:len(prefix)])
+	if err != nil {
+		panic(err)
+	}
+	address, channelID, timeFrame := splitChannelKey(prefix, key)
+	return expiry, address, channelID, timeFrame
+}
+
+// RollupDataNodePrefix returns the store key prefix of the rollups of the datanode
+func RollupDataNodePrefix(address sdk.AccAddress) []byte {
+	return prefixKey(RollupPrefix, address.Bytes())
+}
+
+// RollupChannelPrefix returns the store key prefix of the rollups of the datanode channel
+func RollupChannelPrefix(address sdk.AccAddress, channelID string) []byte {
+	return channelKey(RollupPrefix, address, channelID)
+}
+
+// RollupKey returns the store key of the rollup of the datanode channel time frame
+func RollupKey(address sdk.AccAddress, channelID string, timeFrame uint64) []byte {
+	return append(RollupChannelPrefix(address, channelID), sdk.Uint64ToBigEndian(timeFrame)...)
+}
+
+// identifierKey returns <prefix><len(id)><id>
+func identifierKey(prefix []byte, id string) []byte {
+	key := prefixKey(prefix, []byte{byte(len(id))})
+	return append(key, id...)
+}
+
+// splitIdentifierKey splits <prefix><len(id)><id><rest> keys
+func splitIdentifierKey(prefix []byte, key []byte) (string, []byte) {
+	idStart := len(prefix) + 1
+	idEnd := idStart + int(key[idStart-1])
+	return string(key[idStart:idEnd]), key[idEnd:]
+}
+
+// channelKey returns <prefix><address><len(channel)><channel>
+func channelKey(prefix []byte, address sdk.AccAddress, channelID string) []byte {
+	key := prefixKey(prefix, address.Bytes())
+	key = append(key, byte(len(channelID)))
+	return append(key, channelID...)
+}
+
+// splitChannelKey splits <prefix><address><len(channel)><channel><uint64> keys
+func splitChannelKey(prefix []byte, key []byte) (sdk.AccAddress, string, uint64) {
+	channelStart := len(prefix) + sdk.AddrLen + 1
+	channelEnd := channelStart + int(key[channelStart-1])
+	return sdk.AccAddress(key[len(prefix) : channelStart-1]), string(key[channelStart:channelEnd]), binary.BigEndian.Uint64(key[channelEnd:])
+}
+
+func prefixKey(prefix []byte, key []byte) []byte {
+	res := make([]byte, 0, len(prefix)+len(key))
+	res = append(res, prefix...)
+	return append(res, key...)
+}
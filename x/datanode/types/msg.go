@@ -1,25 +1,30 @@
 package types
 
 import (
+	"time"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
-// MsgSetOwner change the owner of a DataNode or creates a new one if doesn't exist
+// MsgSetOwner change the owner of a DataNode or creates a new one if doesn't exist, new datanodes
+// can be created from the latest version of a device type
 type MsgSetOwner struct {
-	DataNode sdk.AccAddress `json:"datanode"`
-	Owner    sdk.AccAddress `json:"owner"`
-	NewOwner sdk.AccAddress `json:"newowner"`
-	Name     string         `json:"name"`
+	DataNode   sdk.AccAddress `json:"datanode"`
+	Owner      sdk.AccAddress `json:"owner"`
+	NewOwner   sdk.AccAddress `json:"newowner"`
+	Name       string         `json:"name"`
+	DeviceType string         `json:"device_type,omitempty"`
 }
 
 // NewMsgSetOwner is a constructor function for MsgSetOwner
-func NewMsgSetOwner(dataNode sdk.AccAddress, owner sdk.AccAddress, newOwner sdk.AccAddress, name string) MsgSetOwner {
+func NewMsgSetOwner(dataNode sdk.AccAddress, owner sdk.AccAddress, newOwner sdk.AccAddress, name string, deviceType string) MsgSetOwner {
 	return MsgSetOwner{
-		DataNode: dataNode,
-		Owner:    owner,
-		NewOwner: newOwner,
-		Name:     name,
+		DataNode:   dataNode,
+		Owner:      owner,
+		NewOwner:   newOwner,
+		Name:       name,
+		DeviceType: deviceType,
 	}
 }
 
@@ -40,6 +45,14 @@ func (msg MsgSetOwner) ValidateBasic() error {
 	if msg.NewOwner.Empty() {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.NewOwner.String())
 	}
+	if len(msg.Name) > MaxNameLength {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "name can't have more than %d characters", MaxNameLength)
+	}
+	if msg.DeviceType != "" {
+		if err := ValidateDeviceTypeID(msg.DeviceType); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+		}
+	}
 	return nil
 }
 
@@ -48,112 +61,1399 @@ func (msg MsgSetOwner) GetSignBytes() []byte {
 	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
 }
 
-// GetSigners defines whose signature is required
+// GetSigners defines whose signature is required, a transfer to another account needs the
+// new owner to co-sign, otherwise use MsgOfferOwnership and MsgAcceptOwnership
 func (msg MsgSetOwner) GetSigners() []sdk.AccAddress {
 	if msg.DataNode.Equals(msg.Owner) {
 		return []sdk.AccAddress{msg.NewOwner, msg.DataNode}
 	}
+	if !msg.NewOwner.Equals(msg.Owner) {
+		return []sdk.AccAddress{msg.Owner, msg.NewOwner}
+	}
 	return []sdk.AccAddress{msg.Owner}
 }
 
-// ChannelUpdate - channel update action definition
-type ChannelUpdate struct {
-	Action   string `json:"action"`   // set, delete
-	ID       string `json:"id"`       // channel within the datanode
-	Variable string `json:"variable"` // variable of the channel (ex. temperature, humidity)
+// MsgOfferOwnership - offers the ownership of a datanode, the new owner must accept it before it expires
+type MsgOfferOwnership struct {
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode offered
+	NewOwner sdk.AccAddress `json:"newowner"` // account that can accept the offer
 }
 
-// MsgUpdateChannels - changes a channel on a datanode
-type MsgUpdateChannels struct {
-	Owner    sdk.AccAddress  `json:"owner"`    // owner of the datanode
-	DataNode sdk.AccAddress  `json:"datanode"` // datanode to update
-	Updates  []ChannelUpdate `json:"channels"` // channels updates
+// NewMsgOfferOwnership is a constructor function for MsgOfferOwnership
+func NewMsgOfferOwnership(owner sdk.AccAddress, dataNode sdk.AccAddress, newOwner sdk.AccAddress) MsgOfferOwnership {
+	return MsgOfferOwnership{
+		Owner:    owner,
+		DataNode: dataNode,
+		NewOwner: newOwner,
+	}
 }
 
-// NewMsgUpdateChannels is a constructor function for MsgUpdateChannels
-func NewMsgUpdateChannels(owner sdk.AccAddress, dataNode sdk.AccAddress, updates []ChannelUpdate) MsgUpdateChannels {
-	return MsgUpdateChannels{
+// Route should return the name of the module
+func (msg MsgOfferOwnership) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgOfferOwnership) Type() string { return "offer_ownership" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgOfferOwnership) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if msg.NewOwner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.NewOwner.String())
+	}
+	if msg.NewOwner.Equals(msg.Owner) {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "new owner is already the owner")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgOfferOwnership) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgOfferOwnership) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgAcceptOwnership - accepts a pending ownership offer of a datanode
+type MsgAcceptOwnership struct {
+	NewOwner sdk.AccAddress `json:"newowner"` // account the datanode was offered to
+	DataNode sdk.AccAddress `json:"datanode"` // datanode offered
+}
+
+// NewMsgAcceptOwnership is a constructor function for MsgAcceptOwnership
+func NewMsgAcceptOwnership(newOwner sdk.AccAddress, dataNode sdk.AccAddress) MsgAcceptOwnership {
+	return MsgAcceptOwnership{
+		NewOwner: newOwner,
 		DataNode: dataNode,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgAcceptOwnership) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgAcceptOwnership) Type() string { return "accept_ownership" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgAcceptOwnership) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.NewOwner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.NewOwner.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgAcceptOwnership) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgAcceptOwnership) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.NewOwner}
+}
+
+// MsgCancelOwnershipOffer - cancels the pending ownership offer of a datanode
+type MsgCancelOwnershipOffer struct {
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode offered
+}
+
+// NewMsgCancelOwnershipOffer is a constructor function for MsgCancelOwnershipOffer
+func NewMsgCancelOwnershipOffer(owner sdk.AccAddress, dataNode sdk.AccAddress) MsgCancelOwnershipOffer {
+	return MsgCancelOwnershipOffer{
 		Owner:    owner,
-		Updates:  updates,
+		DataNode: dataNode,
 	}
 }
 
 // Route should return the name of the module
-func (msg MsgUpdateChannels) Route() string { return RouterKey }
+func (msg MsgCancelOwnershipOffer) Route() string { return RouterKey }
 
 // Type should return the action
-func (msg MsgUpdateChannels) Type() string { return "set_owner" }
+func (msg MsgCancelOwnershipOffer) Type() string { return "cancel_ownership_offer" }
 
 // ValidateBasic runs stateless checks on the message
-func (msg MsgUpdateChannels) ValidateBasic() error {
+func (msg MsgCancelOwnershipOffer) ValidateBasic() error {
 	if msg.DataNode.Empty() {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
 	}
 	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgCancelOwnershipOffer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgCancelOwnershipOffer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgUpdateDataNode - replaces the descriptive metadata of a datanode
+type MsgUpdateDataNode struct {
+	Owner           sdk.AccAddress `json:"owner"`            // owner of the datanode
+	DataNode        sdk.AccAddress `json:"datanode"`         // datanode to update
+	Name            string         `json:"name"`             // name of the datanode, empty to use the address
+	Description     string         `json:"description"`      // free text description of the datanode
+	Tags            []string       `json:"tags"`             // tags to classify the datanode
+	Location        string         `json:"location"`         // location of the datanode device
+	FirmwareVersion string         `json:"firmware_version"` // firmware version running on the device
+}
+
+// NewMsgUpdateDataNode is a constructor function for MsgUpdateDataNode
+func NewMsgUpdateDataNode(owner sdk.AccAddress, dataNode sdk.AccAddress, name string, description string, tags []string, location string, firmwareVersion string) MsgUpdateDataNode {
+	return MsgUpdateDataNode{
+		Owner:           owner,
+		DataNode:        dataNode,
+		Name:            name,
+		Description:     description,
+		Tags:            tags,
+		Location:        location,
+		FirmwareVersion: firmwareVersion,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgUpdateDataNode) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgUpdateDataNode) Type() string { return "update_datanode" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgUpdateDataNode) ValidateBasic() error {
+	if msg.DataNode.Empty() {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
 	}
-	if len(msg.Updates) == 0 {
-		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no channel updates")
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if err := ValidateMetadata(msg.Name, msg.Description, msg.Tags, msg.Location, msg.FirmwareVersion); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
 	return nil
 }
 
 // GetSignBytes encodes the message for signing
-func (msg MsgUpdateChannels) GetSignBytes() []byte {
+func (msg MsgUpdateDataNode) GetSignBytes() []byte {
 	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
 }
 
 // GetSigners defines whose signature is required
-func (msg MsgUpdateChannels) GetSigners() []sdk.AccAddress {
+func (msg MsgUpdateDataNode) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Owner}
 }
 
-// NewRecord - record to be added to the DataRecord time frame
-type NewRecord struct {
-	NodeChannelID string `json:"channel"`   // channel within the datanode
-	TimeStamp     uint32 `json:"timestamp"` // timestamp in seconds since epoch
-	Value         uint32 `json:"value"`     // numeric value of the record
-	Misc          string `json:"misc"`      // miscellaneous data for other non numeric records
+// MsgDeleteDataNode - decommissions a datanode, removing it with all its records or archiving it
+type MsgDeleteDataNode struct {
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode to delete
+	Archive  bool           `json:"archive"`  // keep the datanode and its records but reject new ones
 }
 
-// MsgAddRecords - adds new records to the datarecord time frame
-type MsgAddRecords struct {
-	DataNode sdk.AccAddress `json:"datanode"`
-	Records  []NewRecord    `json:"records"`
+// NewMsgDeleteDataNode is a constructor function for MsgDeleteDataNode
+func NewMsgDeleteDataNode(owner sdk.AccAddress, dataNode sdk.AccAddress, archive bool) MsgDeleteDataNode {
+	return MsgDeleteDataNode{
+		Owner:    owner,
+		DataNode: dataNode,
+		Archive:  archive,
+	}
 }
 
-// NewMsgAddRecords is a constructor function for MsgAddRecords
-func NewMsgAddRecords(dataNode sdk.AccAddress, records []NewRecord) MsgAddRecords {
-	return MsgAddRecords{
+// Route should return the name of the module
+func (msg MsgDeleteDataNode) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgDeleteDataNode) Type() string { return "delete_datanode" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgDeleteDataNode) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgDeleteDataNode) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgDeleteDataNode) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgSetBackfill - enables or disables the backfill mode of a datanode, to add records older than the max backfill age
+type MsgSetBackfill struct {
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode to change
+	Enabled  bool           `json:"enabled"`  // accept records of any age
+}
+
+// NewMsgSetBackfill is a constructor function for MsgSetBackfill
+func NewMsgSetBackfill(owner sdk.AccAddress, dataNode sdk.AccAddress, enabled bool) MsgSetBackfill {
+	return MsgSetBackfill{
+		Owner:    owner,
 		DataNode: dataNode,
-		Records:  records,
+		Enabled:  enabled,
 	}
 }
 
 // Route should return the name of the module
-func (msg MsgAddRecords) Route() string { return RouterKey }
+func (msg MsgSetBackfill) Route() string { return RouterKey }
 
 // Type should return the action
-func (msg MsgAddRecords) Type() string { return "set_owner" }
+func (msg MsgSetBackfill) Type() string { return "set_backfill" }
 
 // ValidateBasic runs stateless checks on the message
-func (msg MsgAddRecords) ValidateBasic() error {
+func (msg MsgSetBackfill) ValidateBasic() error {
 	if msg.DataNode.Empty() {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
 	}
-	if len(msg.Records) == 0 {
-		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no new records")
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
 	}
 	return nil
 }
 
 // GetSignBytes encodes the message for signing
-func (msg MsgAddRecords) GetSignBytes() []byte {
+func (msg MsgSetBackfill) GetSignBytes() []byte {
 	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
 }
 
 // GetSigners defines whose signature is required
-func (msg MsgAddRecords) GetSigners() []sdk.AccAddress {
-	return []sdk.AccAddress{msg.DataNode}
+func (msg MsgSetBackfill) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgSetFeeAllowance - caps the fees charged for the txs signed by the datanode, replacing the previous
+// limits and keeping the spending. Without limits it removes the allowance
+type MsgSetFeeAllowance struct {
+	Owner       sdk.AccAddress `json:"owner"`        // owner of the datanode
+	DataNode    sdk.AccAddress `json:"datanode"`     // datanode signing the txs
+	TotalLimit  sdk.Coins      `json:"total_limit"`  // maximum fees charged overall
+	PeriodLimit sdk.Coins      `json:"period_limit"` // maximum fees charged on a period
+	Period      int64          `json:"period"`       // seconds of the period
+	TxLimit     sdk.Coins      `json:"tx_limit"`     // maximum fee of a single tx
+}
+
+// NewMsgSetFeeAllowance is a constructor function for MsgSetFeeAllowance
+func NewMsgSetFeeAllowance(owner sdk.AccAddress, dataNode sdk.AccAddress, totalLimit sdk.Coins, periodLimit sdk.Coins, period int64, txLimit sdk.Coins) MsgSetFeeAllowance {
+	return MsgSetFeeAllowance{
+		Owner:       owner,
+		DataNode:    dataNode,
+		TotalLimit:  totalLimit,
+		PeriodLimit: periodLimit,
+		Period:      period,
+		TxLimit:     txLimit,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgSetFeeAllowance) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgSetFeeAllowance) Type() string { return "set_fee_allowance" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgSetFeeAllowance) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if err := ValidateFeeAllowance(msg.TotalLimit, msg.PeriodLimit, msg.Period, msg.TxLimit); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// IsRemoval returns true if the message sets no limits
+func (msg MsgSetFeeAllowance) IsRemoval() bool {
+	return msg.TotalLimit.Empty() && msg.PeriodLimit.Empty() && msg.TxLimit.Empty()
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgSetFeeAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgSetFeeAllowance) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgSetFeePayer - sets the account paying the fees of a datanode instead of its fleet fee payer or owner,
+// an empty fee payer gives the fees back to them
+type MsgSetFeePayer struct {
+	Owner    sdk.AccAddress `json:"owner"`     // owner of the datanode
+	DataNode sdk.AccAddress `json:"datanode"`  // datanode to change
+	FeePayer sdk.AccAddress `json:"fee_payer"` // account paying the fees, empty to remove it
+}
+
+// NewMsgSetFeePayer is a constructor function for MsgSetFeePayer
+func NewMsgSetFeePayer(owner sdk.AccAddress, dataNode sdk.AccAddress, feePayer sdk.AccAddress) MsgSetFeePayer {
+	return MsgSetFeePayer{
+		Owner:    owner,
+		DataNode: dataNode,
+		FeePayer: feePayer,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgSetFeePayer) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgSetFeePayer) Type() string { return "set_fee_payer" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgSetFeePayer) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgSetFeePayer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required, the fee payer must co-sign to agree on paying
+// the fees of the datanode
+func (msg MsgSetFeePayer) GetSigners() []sdk.AccAddress {
+	if msg.FeePayer.Empty() || msg.FeePayer.Equals(msg.Owner) {
+		return []sdk.AccAddress{msg.Owner}
+	}
+	return []sdk.AccAddress{msg.Owner, msg.FeePayer}
+}
+
+// MsgBondBandwidth - bonds coins of an owner, granting its datanodes a quota of records written without
+// fees on every bandwidth period
+type MsgBondBandwidth struct {
+	Owner  sdk.AccAddress `json:"owner"`  // account bonding the coins
+	Amount sdk.Coins      `json:"amount"` // coins to bond, on the denom of the bandwidth bond per record
+}
+
+// NewMsgBondBandwidth is a constructor function for MsgBondBandwidth
+func NewMsgBondBandwidth(owner sdk.AccAddress, amount sdk.Coins) MsgBondBandwidth {
+	return MsgBondBandwidth{
+		Owner:  owner,
+		Amount: amount,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgBondBandwidth) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgBondBandwidth) Type() string { return "bond_bandwidth" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgBondBandwidth) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if !msg.Amount.IsValid() || msg.Amount.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, msg.Amount.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgBondBandwidth) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgBondBandwidth) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgUnbondBandwidth - unbonds coins of an owner, they stop granting quota right away and are returned
+// to the owner after the bandwidth unbonding time
+type MsgUnbondBandwidth struct {
+	Owner  sdk.AccAddress `json:"owner"`  // account that bonded the coins
+	Amount sdk.Coins      `json:"amount"` // coins to unbond
+}
+
+// NewMsgUnbondBandwidth is a constructor function for MsgUnbondBandwidth
+func NewMsgUnbondBandwidth(owner sdk.AccAddress, amount sdk.Coins) MsgUnbondBandwidth {
+	return MsgUnbondBandwidth{
+		Owner:  owner,
+		Amount: amount,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgUnbondBandwidth) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgUnbondBandwidth) Type() string { return "unbond_bandwidth" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgUnbondBandwidth) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if !msg.Amount.IsValid() || msg.Amount.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, msg.Amount.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgUnbondBandwidth) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgUnbondBandwidth) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MaxChannelIDLength - maximum length of a channel id, it's part of the record store keys
+const MaxChannelIDLength = 64
+
+// ChannelUpdate - channel update action definition
+type ChannelUpdate struct {
+	Action    string        `json:"action"`              // set, delete
+	ID        string        `json:"id"`                  // channel within the datanode
+	Variable  string        `json:"variable"`            // variable of the channel (ex. temperature, humidity)
+	Unit      string        `json:"unit,omitempty"`      // UCUM code of the unit of the values (ex. Cel, %, kPa)
+	Encoding  ValueEncoding `json:"encoding,omitempty"`  // encoding of the record values, uint32 when empty
+	Scale     uint32        `json:"scale,omitempty"`     // decimal digits of the decimal encoding values
+	Enum      []string      `json:"enum,omitempty"`      // labels of the enum encoding values
+	Min       string        `json:"min,omitempty"`       // minimum decoded value accepted
+	Max       string        `json:"max,omitempty"`       // maximum decoded value accepted
+	Retention int64         `json:"retention,omitempty"` // seconds the records are kept after their time frame ends
+	Rollup    bool          `json:"rollup,omitempty"`    // keep a summary of each time frame pruned
+}
+
+// Channel returns the channel set by the update
+func (u ChannelUpdate) Channel() NodeChannel {
+	return NodeChannel{
+		ID:        u.ID,
+		Variable:  u.Variable,
+		Unit:      u.Unit,
+		Encoding:  u.Encoding,
+		Scale:     u.Scale,
+		Enum:      u.Enum,
+		Min:       u.Min,
+		Max:       u.Max,
+		Retention: u.Retention,
+		Rollup:    u.Rollup,
+	}
+}
+
+// MsgUpdateChannels - changes a channel on a datanode
+type MsgUpdateChannels struct {
+	Owner    sdk.AccAddress  `json:"owner"`    // owner of the datanode
+	DataNode sdk.AccAddress  `json:"datanode"` // datanode to update
+	Updates  []ChannelUpdate `json:"channels"` // channels updates
+}
+
+// NewMsgUpdateChannels is a constructor function for MsgUpdateChannels
+func NewMsgUpdateChannels(owner sdk.AccAddress, dataNode sdk.AccAddress, updates []ChannelUpdate) MsgUpdateChannels {
+	return MsgUpdateChannels{
+		DataNode: dataNode,
+		Owner:    owner,
+		Updates:  updates,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgUpdateChannels) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgUpdateChannels) Type() string { return "set_owner" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgUpdateChannels) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if len(msg.Updates) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no channel updates")
+	}
+	for _, ch := range msg.Updates {
+		if err := ValidateChannel(ch.Channel()); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+		}
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgUpdateChannels) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgUpdateChannels) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// NewRecord - record to be added to the DataRecord time frame. Legacy records set the timestamp in
+// seconds and a 32-bit value, records v2 set the time in milliseconds and a signed 64-bit value
+type NewRecord struct {
+	NodeChannelID string `json:"channel"`             // channel within the datanode
+	TimeStamp     uint32 `json:"timestamp"`           // legacy timestamp in seconds since epoch
+	Value         uint32 `json:"value"`               // legacy numeric value of the record
+	Misc          string `json:"misc"`                // miscellaneous data for other non numeric records
+	Time          int64  `json:"time,omitempty"`      // timestamp in milliseconds since epoch of records v2
+	IntValue      int64  `json:"int_value,omitempty"` // numeric value of records v2, read with the channel encoding
+}
+
+// IsLegacy returns true if the record has a timestamp in seconds and a 32-bit value
+func (r NewRecord) IsLegacy() bool {
+	return r.Time == 0
+}
+
+// Millis returns the timestamp of the record in milliseconds
+func (r NewRecord) Millis() int64 {
+	if r.IsLegacy() {
+		return int64(r.TimeStamp) * MillisPerSecond
+	}
+	return r.Time
+}
+
+// Record returns the record to store, legacy records are converted with the channel encoding
+func (r NewRecord) Record(channel NodeChannel) (Record, error) {
+	if !r.IsLegacy() {
+		return Record{TimeStamp: r.Time, Value: r.IntValue, Misc: r.Misc}, nil
+	}
+	value, misc, err := channel.LegacyValue(r.Value, r.Misc)
+	if err != nil {
+		return Record{}, err
+	}
+	return Record{TimeStamp: r.Millis(), Value: value, Misc: misc}, nil
+}
+
+// validateNewRecords checks there are records and each one is either a legacy record or a record v2
+func validateNewRecords(records []NewRecord) error {
+	if len(records) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no new records")
+	}
+	for _, re := range records {
+		if re.IsLegacy() && re.IntValue != 0 {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "int_value is only allowed on records with time")
+		}
+		if !re.IsLegacy() && (re.TimeStamp != 0 || re.Value != 0) {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "records with time can't set the legacy timestamp and value")
+		}
+		if re.Time < 0 {
+			return sdkerrors.Wrapf(ErrInvalidTimestamp, "%d is before epoch", re.Time)
+		}
+	}
+	return nil
+}
+
+// MsgAddRecords - adds new records to the datarecord time frame. Invalid records are rejected one by one
+// and reported on the message result, unless strict is set and any of them fails the whole message
+type MsgAddRecords struct {
+	DataNode sdk.AccAddress `json:"datanode"`
+	Records  []NewRecord    `json:"records"`
+	Strict   bool           `json:"strict,omitempty"`
+}
+
+// NewMsgAddRecords is a constructor function for MsgAddRecords
+func NewMsgAddRecords(dataNode sdk.AccAddress, records []NewRecord, strict bool) MsgAddRecords {
+	return MsgAddRecords{
+		DataNode: dataNode,
+		Records:  records,
+		Strict:   strict,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgAddRecords) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgAddRecords) Type() string { return "set_owner" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgAddRecords) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	return validateNewRecords(msg.Records)
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgAddRecords) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgAddRecords) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.DataNode}
+}
+
+// MsgPruneRecords - deletes the records of a datanode channel older than a timestamp, the storage deposit
+// of the records is refunded to its depositors
+type MsgPruneRecords struct {
+	Owner    sdk.AccAddress `json:"owner"`    // owner or admin of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode storing the records
+	Channel  string         `json:"channel"`  // channel of the records
+	Before   int64          `json:"before"`   // records with timestamps before this one in milliseconds are deleted
+}
+
+// NewMsgPruneRecords is a constructor function for MsgPruneRecords
+func NewMsgPruneRecords(owner sdk.AccAddress, dataNode sdk.AccAddress, channel string, before int64) MsgPruneRecords {
+	return MsgPruneRecords{
+		Owner:    owner,
+		DataNode: dataNode,
+		Channel:  channel,
+		Before:   before,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgPruneRecords) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgPruneRecords) Type() string { return "prune_records" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgPruneRecords) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if len(msg.Channel) == 0 || len(msg.Channel) > MaxChannelIDLength {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "channel id must have between 1 and %d characters", MaxChannelIDLength)
+	}
+	if msg.Before <= 0 {
+		return sdkerrors.Wrapf(ErrInvalidTimestamp, "%d is not after epoch", msg.Before)
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgPruneRecords) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgPruneRecords) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgGrantRole - grants a role on the datanode to an account, replacing its previous role. Admin roles
+// are granted by the owner, the other roles by the owner or an admin
+type MsgGrantRole struct {
+	Granter  sdk.AccAddress `json:"granter"`  // owner or admin of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode the role is granted on
+	Address  sdk.AccAddress `json:"address"`  // account the role is granted to
+	Role     Role           `json:"role"`     // role of the account
+	Channels []string       `json:"channels"` // channels a writer can write, all of them when empty
+	Expiry   time.Time      `json:"expiry"`   // the grant is not valid from this time on, zero if it doesn't expire
+}
+
+// NewMsgGrantRole is a constructor function for MsgGrantRole
+func NewMsgGrantRole(granter sdk.AccAddress, dataNode sdk.AccAddress, address sdk.AccAddress, role Role, channels []string, expiry time.Time) MsgGrantRole {
+	return MsgGrantRole{
+		Granter:  granter,
+		DataNode: dataNode,
+		Address:  address,
+		Role:     role,
+		Channels: channels,
+		Expiry:   expiry,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgGrantRole) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgGrantRole) Type() string { return "grant_role" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgGrantRole) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Granter.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Granter.String())
+	}
+	if msg.Address.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Address.String())
+	}
+	if !msg.Role.IsValid() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid role %s", msg.Role)
+	}
+	if len(msg.Channels) > 0 && msg.Role != RoleWriter {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "channels can only be limited on the writer role")
+	}
+	for _, ch := range msg.Channels {
+		if len(ch) == 0 || len(ch) > MaxChannelIDLength {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "channel id must have between 1 and %d characters", MaxChannelIDLength)
+		}
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgGrantRole) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgGrantRole) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Granter}
+}
+
+// MsgRevokeRole - revokes the role of an account on the datanode
+type MsgRevokeRole struct {
+	Granter  sdk.AccAddress `json:"granter"`  // owner or admin of the datanode
+	DataNode sdk.AccAddress `json:"datanode"` // datanode the role was granted on
+	Address  sdk.AccAddress `json:"address"`  // account to revoke
+}
+
+// NewMsgRevokeRole is a constructor function for MsgRevokeRole
+func NewMsgRevokeRole(granter sdk.AccAddress, dataNode sdk.AccAddress, address sdk.AccAddress) MsgRevokeRole {
+	return MsgRevokeRole{
+		Granter:  granter,
+		DataNode: dataNode,
+		Address:  address,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgRevokeRole) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgRevokeRole) Type() string { return "revoke_role" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgRevokeRole) ValidateBasic() error {
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Granter.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Granter.String())
+	}
+	if msg.Address.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Address.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgRevokeRole) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgRevokeRole) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Granter}
+}
+
+// DataNodeRecords - new records of a datanode written by a gateway
+type DataNodeRecords struct {
+	DataNode sdk.AccAddress `json:"datanode"`
+	Records  []NewRecord    `json:"records"`
+}
+
+// MsgGatewayAddRecords - adds new records of several datanodes, signed by an account with the writer
+// role on them. Fees are paid by the datanodes owners. Invalid records and the ones of the channels the
+// gateway can't write are handled as the invalid records of MsgAddRecords
+type MsgGatewayAddRecords struct {
+	Gateway   sdk.AccAddress    `json:"gateway"`
+	DataNodes []DataNodeRecords `json:"datanodes"`
+	Strict    bool              `json:"strict,omitempty"`
+}
+
+// NewMsgGatewayAddRecords is a constructor function for MsgGatewayAddRecords
+func NewMsgGatewayAddRecords(gateway sdk.AccAddress, dataNodes []DataNodeRecords, strict bool) MsgGatewayAddRecords {
+	return MsgGatewayAddRecords{
+		Gateway:   gateway,
+		DataNodes: dataNodes,
+		Strict:    strict,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgGatewayAddRecords) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgGatewayAddRecords) Type() string { return "gateway_add_records" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgGatewayAddRecords) ValidateBasic() error {
+	if msg.Gateway.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Gateway.String())
+	}
+	if len(msg.DataNodes) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no datanodes")
+	}
+	dataNodes := make(map[string]bool)
+	for _, batch := range msg.DataNodes {
+		if batch.DataNode.Empty() {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, batch.DataNode.String())
+		}
+		if dataNodes[batch.DataNode.String()] {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "duplicated datanode %s", batch.DataNode)
+		}
+		dataNodes[batch.DataNode.String()] = true
+		if len(batch.Records) == 0 {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "no new records for %s", batch.DataNode)
+		}
+		if err := validateNewRecords(batch.Records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgGatewayAddRecords) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgGatewayAddRecords) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Gateway}
+}
+
+// RecordsCount returns the number of records of all the datanodes
+func (msg MsgGatewayAddRecords) RecordsCount() int {
+	count := 0
+	for _, batch := range msg.DataNodes {
+		count += len(batch.Records)
+	}
+	return count
+}
+
+// MsgCreateFleet - creates a new fleet with the admin as its only admin
+type MsgCreateFleet struct {
+	Admin sdk.AccAddress `json:"admin"` // first admin of the fleet
+	Fleet string         `json:"fleet"` // id of the new fleet
+}
+
+// NewMsgCreateFleet is a constructor function for MsgCreateFleet
+func NewMsgCreateFleet(admin sdk.AccAddress, fleet string) MsgCreateFleet {
+	return MsgCreateFleet{
+		Admin: admin,
+		Fleet: fleet,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgCreateFleet) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgCreateFleet) Type() string { return "create_fleet" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgCreateFleet) ValidateBasic() error {
+	if msg.Admin.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Admin.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgCreateFleet) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgCreateFleet) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Admin}
+}
+
+// MsgSetFleetAdmins - replaces the admins of a fleet
+type MsgSetFleetAdmins struct {
+	Admin  sdk.AccAddress   `json:"admin"`  // admin of the fleet
+	Fleet  string           `json:"fleet"`  // fleet to update
+	Admins []sdk.AccAddress `json:"admins"` // new admins of the fleet
+}
+
+// NewMsgSetFleetAdmins is a constructor function for MsgSetFleetAdmins
+func NewMsgSetFleetAdmins(admin sdk.AccAddress, fleet string, admins []sdk.AccAddress) MsgSetFleetAdmins {
+	return MsgSetFleetAdmins{
+		Admin:  admin,
+		Fleet:  fleet,
+		Admins: admins,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgSetFleetAdmins) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgSetFleetAdmins) Type() string { return "set_fleet_admins" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgSetFleetAdmins) ValidateBasic() error {
+	if msg.Admin.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Admin.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if err := ValidateFleetAdmins(msg.Admins); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgSetFleetAdmins) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgSetFleetAdmins) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Admin}
+}
+
+// MsgSetFleetFeePayer - sets the account paying the fees of the fleet members instead of their owners,
+// an empty fee payer gives the fees back to the owners
+type MsgSetFleetFeePayer struct {
+	Admin    sdk.AccAddress `json:"admin"`     // admin of the fleet
+	Fleet    string         `json:"fleet"`     // fleet to update
+	FeePayer sdk.AccAddress `json:"fee_payer"` // account paying the fees, empty to let the owners pay
+}
+
+// NewMsgSetFleetFeePayer is a constructor function for MsgSetFleetFeePayer
+func NewMsgSetFleetFeePayer(admin sdk.AccAddress, fleet string, feePayer sdk.AccAddress) MsgSetFleetFeePayer {
+	return MsgSetFleetFeePayer{
+		Admin:    admin,
+		Fleet:    fleet,
+		FeePayer: feePayer,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgSetFleetFeePayer) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgSetFleetFeePayer) Type() string { return "set_fleet_fee_payer" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgSetFleetFeePayer) ValidateBasic() error {
+	if msg.Admin.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Admin.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgSetFleetFeePayer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required, the fee payer must co-sign to agree on paying
+// the fees of the fleet
+func (msg MsgSetFleetFeePayer) GetSigners() []sdk.AccAddress {
+	if msg.FeePayer.Empty() || msg.FeePayer.Equals(msg.Admin) {
+		return []sdk.AccAddress{msg.Admin}
+	}
+	return []sdk.AccAddress{msg.Admin, msg.FeePayer}
+}
+
+// MsgAddFleetMember - adds a datanode to a fleet, signed by the fleet admin and the datanode owner.
+// The fleet channel template is applied to the datanode
+type MsgAddFleetMember struct {
+	Admin    sdk.AccAddress `json:"admin"`    // admin of the fleet
+	Fleet    string         `json:"fleet"`    // fleet to join
+	DataNode sdk.AccAddress `json:"datanode"` // datanode joining the fleet
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode
+}
+
+// NewMsgAddFleetMember is a constructor function for MsgAddFleetMember
+func NewMsgAddFleetMember(admin sdk.AccAddress, fleet string, dataNode sdk.AccAddress, owner sdk.AccAddress) MsgAddFleetMember {
+	return MsgAddFleetMember{
+		Admin:    admin,
+		Fleet:    fleet,
+		DataNode: dataNode,
+		Owner:    owner,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgAddFleetMember) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgAddFleetMember) Type() string { return "add_fleet_member" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgAddFleetMember) ValidateBasic() error {
+	if msg.Admin.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Admin.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgAddFleetMember) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required, both the fleet admin and the datanode owner
+func (msg MsgAddFleetMember) GetSigners() []sdk.AccAddress {
+	if msg.Owner.Equals(msg.Admin) {
+		return []sdk.AccAddress{msg.Admin}
+	}
+	return []sdk.AccAddress{msg.Admin, msg.Owner}
+}
+
+// MsgRemoveFleetMember - removes a datanode from a fleet, signed by a fleet admin or the datanode owner
+type MsgRemoveFleetMember struct {
+	Sender   sdk.AccAddress `json:"sender"`   // admin of the fleet or owner of the datanode
+	Fleet    string         `json:"fleet"`    // fleet to leave
+	DataNode sdk.AccAddress `json:"datanode"` // datanode leaving the fleet
+}
+
+// NewMsgRemoveFleetMember is a constructor function for MsgRemoveFleetMember
+func NewMsgRemoveFleetMember(sender sdk.AccAddress, fleet string, dataNode sdk.AccAddress) MsgRemoveFleetMember {
+	return MsgRemoveFleetMember{
+		Sender:   sender,
+		Fleet:    fleet,
+		DataNode: dataNode,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgRemoveFleetMember) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgRemoveFleetMember) Type() string { return "remove_fleet_member" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgRemoveFleetMember) ValidateBasic() error {
+	if msg.Sender.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Sender.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgRemoveFleetMember) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgRemoveFleetMember) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+// MsgSetFleetTemplate - replaces the channel template of a fleet and sets its channels on every
+// member datanode, channels not on the template are kept
+type MsgSetFleetTemplate struct {
+	Admin    sdk.AccAddress `json:"admin"`    // admin of the fleet
+	Fleet    string         `json:"fleet"`    // fleet to update
+	Channels []NodeChannel  `json:"channels"` // channels of the template
+}
+
+// NewMsgSetFleetTemplate is a constructor function for MsgSetFleetTemplate
+func NewMsgSetFleetTemplate(admin sdk.AccAddress, fleet string, channels []NodeChannel) MsgSetFleetTemplate {
+	return MsgSetFleetTemplate{
+		Admin:    admin,
+		Fleet:    fleet,
+		Channels: channels,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgSetFleetTemplate) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgSetFleetTemplate) Type() string { return "set_fleet_template" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgSetFleetTemplate) ValidateBasic() error {
+	if msg.Admin.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Admin.String())
+	}
+	if err := ValidateFleetID(msg.Fleet); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if err := ValidateChannels(msg.Channels); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgSetFleetTemplate) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgSetFleetTemplate) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Admin}
+}
+
+// MsgPublishDeviceType - publishes a new version of a device type, the first version registers the
+// device type and the next ones can only be published by its owner, see MsgTransferDeviceType
+type MsgPublishDeviceType struct {
+	Owner       sdk.AccAddress `json:"owner"`       // owner of the device type
+	DeviceType  string         `json:"device_type"` // id of the device type
+	Description string         `json:"description"` // free text description of the device type
+	Channels    []NodeChannel  `json:"channels"`    // channels of the new version
+}
+
+// NewMsgPublishDeviceType is a constructor function for MsgPublishDeviceType
+func NewMsgPublishDeviceType(owner sdk.AccAddress, deviceType string, description string, channels []NodeChannel) MsgPublishDeviceType {
+	return MsgPublishDeviceType{
+		Owner:       owner,
+		DeviceType:  deviceType,
+		Description: description,
+		Channels:    channels,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgPublishDeviceType) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgPublishDeviceType) Type() string { return "publish_device_type" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgPublishDeviceType) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if err := ValidateDeviceTypeID(msg.DeviceType); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if len(msg.Description) > MaxDescriptionLength {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "description can't have more than %d characters", MaxDescriptionLength)
+	}
+	if len(msg.Channels) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no channels")
+	}
+	if err := ValidateChannels(msg.Channels); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgPublishDeviceType) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgPublishDeviceType) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgTransferDeviceType - transfers a device type to a new owner, who publishes its next versions
+type MsgTransferDeviceType struct {
+	Owner      sdk.AccAddress `json:"owner"`       // owner of the device type
+	DeviceType string         `json:"device_type"` // id of the device type
+	NewOwner   sdk.AccAddress `json:"new_owner"`   // new owner of the device type
+}
+
+// NewMsgTransferDeviceType is a constructor function for MsgTransferDeviceType
+func NewMsgTransferDeviceType(owner sdk.AccAddress, deviceType string, newOwner sdk.AccAddress) MsgTransferDeviceType {
+	return MsgTransferDeviceType{
+		Owner:      owner,
+		DeviceType: deviceType,
+		NewOwner:   newOwner,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgTransferDeviceType) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgTransferDeviceType) Type() string { return "transfer_device_type" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgTransferDeviceType) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if msg.NewOwner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.NewOwner.String())
+	}
+	if msg.Owner.Equals(msg.NewOwner) {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "the new owner is the owner")
+	}
+	if err := ValidateDeviceTypeID(msg.DeviceType); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgTransferDeviceType) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgTransferDeviceType) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgLinkDeviceType - links a datanode to a device type version setting its channels, the channels
+// of the previous device type not in the new one are removed. An empty device type unlinks the
+// datanode keeping its channels
+type MsgLinkDeviceType struct {
+	Owner      sdk.AccAddress `json:"owner"`       // owner or operator of the datanode
+	DataNode   sdk.AccAddress `json:"datanode"`    // datanode to link
+	DeviceType string         `json:"device_type"` // id of the device type, empty to unlink
+	Version    uint32         `json:"version"`     // version of the device type, the latest one when 0
+}
+
+// NewMsgLinkDeviceType is a constructor function for MsgLinkDeviceType
+func NewMsgLinkDeviceType(owner sdk.AccAddress, dataNode sdk.AccAddress, deviceType string, version uint32) MsgLinkDeviceType {
+	return MsgLinkDeviceType{
+		Owner:      owner,
+		DataNode:   dataNode,
+		DeviceType: deviceType,
+		Version:    version,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgLinkDeviceType) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgLinkDeviceType) Type() string { return "link_device_type" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgLinkDeviceType) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if msg.DataNode.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.DataNode.String())
+	}
+	if msg.DeviceType == "" {
+		if msg.Version != 0 {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "version without device type")
+		}
+		return nil
+	}
+	if err := ValidateDeviceTypeID(msg.DeviceType); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgLinkDeviceType) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgLinkDeviceType) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MaxUpgradeDataNodes - maximum number of datanodes upgraded by a message
+const MaxUpgradeDataNodes = 100
+
+// MsgUpgradeDataNodes - upgrades datanodes linked to a device type to a newer version, migrating
+// their channels
+type MsgUpgradeDataNodes struct {
+	Owner      sdk.AccAddress   `json:"owner"`       // owner or operator of the datanodes
+	DeviceType string           `json:"device_type"` // id of the device type the datanodes are linked to
+	Version    uint32           `json:"version"`     // version to upgrade to, the latest one when 0
+	DataNodes  []sdk.AccAddress `json:"datanodes"`   // datanodes to upgrade
+}
+
+// NewMsgUpgradeDataNodes is a constructor function for MsgUpgradeDataNodes
+func NewMsgUpgradeDataNodes(owner sdk.AccAddress, deviceType string, version uint32, dataNodes []sdk.AccAddress) MsgUpgradeDataNodes {
+	return MsgUpgradeDataNodes{
+		Owner:      owner,
+		DeviceType: deviceType,
+		Version:    version,
+		DataNodes:  dataNodes,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgUpgradeDataNodes) Route() string { return RouterKey }
+
+// Type should return the action
+func (msg MsgUpgradeDataNodes) Type() string { return "upgrade_datanodes" }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgUpgradeDataNodes) ValidateBasic() error {
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Owner.String())
+	}
+	if err := ValidateDeviceTypeID(msg.DeviceType); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	if len(msg.DataNodes) == 0 || len(msg.DataNodes) > MaxUpgradeDataNodes {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "must upgrade between 1 and %d datanodes", MaxUpgradeDataNodes)
+	}
+	dataNodes := make(map[string]bool)
+	for _, dataNode := range msg.DataNodes {
+		if dataNode.Empty() {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, dataNode.String())
+		}
+		if dataNodes[dataNode.String()] {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "duplicated datanode %s", dataNode)
+		}
+		dataNodes[dataNode.String()] = true
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgUpgradeDataNodes) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgUpgradeDataNodes) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
 }
@@ -2,20 +2,65 @@ package types
 
 import (
 	"fmt"
+	"strings"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params"
 )
 
 // Default parameter namespace
 const (
 	DefaultParamspace = ModuleName
-	// TODO: Define your default parameters
+
+	DefaultMaxRecordsPerMsg uint32 = 500
+	DefaultMaxMiscLength    uint32 = 256
+	DefaultMaxChannels      uint32 = 32
+	DefaultMaxTimestampSkew int64  = 300
+	DefaultMaxBackfillAge   int64  = 7 * 24 * 3600
+	DefaultFrameSize        int64  = 24 * 3600
+
+	DefaultOwnershipOfferDuration int64 = 7 * 24 * 3600
+
+	DefaultLegacyRecords bool = true
+
+	DefaultBandwidthPeriod        int64  = 24 * 3600
+	DefaultBandwidthUnbondingTime int64  = 21 * 24 * 3600
+	DefaultBandwidthGasPerRecord  uint64 = 50000
+
+	DefaultMaxRetention      int64  = 0
+	DefaultMaxPrunedPerBlock uint32 = 1000
+
+	// MinFrameSize - minimum seconds of a time frame
+	MinFrameSize int64 = 60
 )
 
+// DefaultBandwidthBondPerRecord - coins bonded by default for each record of the bandwidth quota
+var DefaultBandwidthBondPerRecord = sdk.NewInt64Coin(sdk.DefaultBondDenom, 1000)
+
+// DefaultStorageDepositPerByte - coins deposited by default for each byte of the stored records
+var DefaultStorageDepositPerByte = sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)
+
 // Parameter store keys
 var (
-	// TODO: Define your keys for the parameter store
-	// KeyParamName          = []byte("ParamName")
+	KeyMaxRecordsPerMsg = []byte("MaxRecordsPerMsg")
+	KeyMaxMiscLength    = []byte("MaxMiscLength")
+	KeyMaxChannels      = []byte("MaxChannels")
+	KeyMaxTimestampSkew = []byte("MaxTimestampSkew")
+	KeyMaxBackfillAge   = []byte("MaxBackfillAge")
+	KeyFrameSize        = []byte("FrameSize")
+
+	KeyOwnershipOfferDuration = []byte("OwnershipOfferDuration")
+	KeyLegacyRecords          = []byte("LegacyRecords")
+
+	KeyBandwidthBondPerRecord = []byte("BandwidthBondPerRecord")
+	KeyBandwidthPeriod        = []byte("BandwidthPeriod")
+	KeyBandwidthUnbondingTime = []byte("BandwidthUnbondingTime")
+	KeyBandwidthGasPerRecord  = []byte("BandwidthGasPerRecord")
+
+	KeyStorageDepositPerByte = []byte("StorageDepositPerByte")
+
+	KeyMaxRetention      = []byte("MaxRetention")
+	KeyMaxPrunedPerBlock = []byte("MaxPrunedPerBlock")
 )
 
 // ParamKeyTable for datanode module
@@ -25,33 +70,283 @@ func ParamKeyTable() params.KeyTable {
 
 // Params - used for initializing default parameter for datanode at genesis
 type Params struct {
-	// TODO: Add your Paramaters to the Paramter struct
-	// KeyParamName string `json:"key_param_name"`
+	MaxRecordsPerMsg uint32 `json:"max_records_per_msg" yaml:"max_records_per_msg"` // maximum records on a single MsgAddRecords
+	MaxMiscLength    uint32 `json:"max_misc_length" yaml:"max_misc_length"`         // maximum bytes of the misc data of a record
+	MaxChannels      uint32 `json:"max_channels" yaml:"max_channels"`               // maximum channels defined on a datanode
+	MaxTimestampSkew int64  `json:"max_timestamp_skew" yaml:"max_timestamp_skew"`   // seconds a record can be ahead of the block time
+	MaxBackfillAge   int64  `json:"max_backfill_age" yaml:"max_backfill_age"`       // seconds a record can be behind the block time, unless the datanode is on backfill mode
+	FrameSize        int64  `json:"frame_size" yaml:"frame_size"`                   // seconds of the time frames indexing the records, the index is rebuilt at the end of the block it changes on
+
+	OwnershipOfferDuration int64 `json:"ownership_offer_duration" yaml:"ownership_offer_duration"` // seconds an ownership offer can be accepted
+	LegacyRecords          bool  `json:"legacy_records" yaml:"legacy_records"`                     // accept new records with timestamps in seconds and 32-bit values
+
+	BandwidthBondPerRecord sdk.Coin `json:"bandwidth_bond_per_record" yaml:"bandwidth_bond_per_record"` // coins bonded by an owner for each record of the quota written without fees on a period, zero disables the quota
+	BandwidthPeriod        int64    `json:"bandwidth_period" yaml:"bandwidth_period"`                   // seconds of the periods the bandwidth quota is renewed on
+	BandwidthUnbondingTime int64    `json:"bandwidth_unbonding_time" yaml:"bandwidth_unbonding_time"`   // seconds the unbonded coins are held before returning them to the owner
+	BandwidthGasPerRecord  uint64   `json:"bandwidth_gas_per_record" yaml:"bandwidth_gas_per_record"`   // gas a tx written on the bandwidth quota can take for each of its records
+
+	StorageDepositPerByte sdk.Coin `json:"storage_deposit_per_byte" yaml:"storage_deposit_per_byte"` // coins deposited by the fee payer for each byte of the records stored, zero disables the deposits
+
+	MaxRetention      int64  `json:"max_retention" yaml:"max_retention"`               // seconds the records are kept after their time frame ends, zero keeps them until their channel retention
+	MaxPrunedPerBlock uint32 `json:"max_pruned_per_block" yaml:"max_pruned_per_block"` // maximum records pruned at the end of a block
 }
 
 // NewParams creates a new Params object
-func NewParams(/* TODO: Pass in the paramters*/) Params {
+func NewParams(maxRecordsPerMsg uint32, maxMiscLength uint32, maxChannels uint32, maxTimestampSkew int64, maxBackfillAge int64, frameSize int64, ownershipOfferDuration int64, legacyRecords bool, bandwidthBondPerRecord sdk.Coin, bandwidthPeriod int64, bandwidthUnbondingTime int64, bandwidthGasPerRecord uint64, storageDepositPerByte sdk.Coin, maxRetention int64, maxPrunedPerBlock uint32) Params {
 	return Params{
-		// TODO: Create your Params Type
+		MaxRecordsPerMsg:       maxRecordsPerMsg,
+		MaxMiscLength:          maxMiscLength,
+		MaxChannels:            maxChannels,
+		MaxTimestampSkew:       maxTimestampSkew,
+		MaxBackfillAge:         maxBackfillAge,
+		FrameSize:              frameSize,
+		OwnershipOfferDuration: ownershipOfferDuration,
+		LegacyRecords:          legacyRecords,
+		BandwidthBondPerRecord: bandwidthBondPerRecord,
+		BandwidthPeriod:        bandwidthPeriod,
+		BandwidthUnbondingTime: bandwidthUnbondingTime,
+		BandwidthGasPerRecord:  bandwidthGasPerRecord,
+		StorageDepositPerByte:  storageDepositPerByte,
+		MaxRetention:           maxRetention,
+		MaxPrunedPerBlock:      maxPrunedPerBlock,
 	}
 }
 
 // String implements the stringer interface for Params
 func (p Params) String() string {
-	return fmt.Sprintf(`
-	// TODO: Return all the params as a string
-	`, )
+	return strings.TrimSpace(fmt.Sprintf(`Params:
+  MaxRecordsPerMsg: %d
+  MaxMiscLength:    %d
+  MaxChannels:      %d
+  MaxTimestampSkew: %d
+  MaxBackfillAge:   %d
+  FrameSize:        %d
+  OwnershipOfferDuration: %d
+  LegacyRecords: %t
+  BandwidthBondPerRecord: %s
+  BandwidthPeriod:        %d
+  BandwidthUnbondingTime: %d
+  BandwidthGasPerRecord:  %d
+  StorageDepositPerByte: %s
+  MaxRetention:      %d
+  MaxPrunedPerBlock: %d
+`, p.MaxRecordsPerMsg, p.MaxMiscLength, p.MaxChannels, p.MaxTimestampSkew, p.MaxBackfillAge, p.FrameSize, p.OwnershipOfferDuration, p.LegacyRecords,
+		p.BandwidthBondPerRecord, p.BandwidthPeriod, p.BandwidthUnbondingTime, p.BandwidthGasPerRecord, p.StorageDepositPerByte,
+		p.MaxRetention, p.MaxPrunedPerBlock))
 }
 
 // ParamSetPairs - Implements params.ParamSet
 func (p *Params) ParamSetPairs() params.ParamSetPairs {
 	return params.ParamSetPairs{
-		// TODO: Pair your key with the param
-		// params.NewParamSetPair(KeyParamName, &p.ParamName),
+		params.NewParamSetPair(KeyMaxRecordsPerMsg, &p.MaxRecordsPerMsg, validatePositiveUint32),
+		params.NewParamSetPair(KeyMaxMiscLength, &p.MaxMiscLength, validateUint32),
+		params.NewParamSetPair(KeyMaxChannels, &p.MaxChannels, validatePositiveUint32),
+		params.NewParamSetPair(KeyMaxTimestampSkew, &p.MaxTimestampSkew, validateMaxTimestampSkew),
+		params.NewParamSetPair(KeyMaxBackfillAge, &p.MaxBackfillAge, validateMaxBackfillAge),
+		params.NewParamSetPair(KeyFrameSize, &p.FrameSize, validateFrameSize),
+		params.NewParamSetPair(KeyOwnershipOfferDuration, &p.OwnershipOfferDuration, validateOwnershipOfferDuration),
+		params.NewParamSetPair(KeyLegacyRecords, &p.LegacyRecords, validateBool),
+		params.NewParamSetPair(KeyBandwidthBondPerRecord, &p.BandwidthBondPerRecord, validateBandwidthBondPerRecord),
+		params.NewParamSetPair(KeyBandwidthPeriod, &p.BandwidthPeriod, validateBandwidthPeriod),
+		params.NewParamSetPair(KeyBandwidthUnbondingTime, &p.BandwidthUnbondingTime, validateBandwidthUnbondingTime),
+		params.NewParamSetPair(KeyBandwidthGasPerRecord, &p.BandwidthGasPerRecord, validateBandwidthGasPerRecord),
+		params.NewParamSetPair(KeyStorageDepositPerByte, &p.StorageDepositPerByte, validateStorageDepositPerByte),
+		params.NewParamSetPair(KeyMaxRetention, &p.MaxRetention, validateMaxRetention),
+		params.NewParamSetPair(KeyMaxPrunedPerBlock, &p.MaxPrunedPerBlock, validatePositiveUint32),
+	}
+}
+
+// Validate checks that the parameters have valid values
+func (p Params) Validate() error {
+	if err := validatePositiveUint32(p.MaxRecordsPerMsg); err != nil {
+		return err
+	}
+	if err := validateUint32(p.MaxMiscLength); err != nil {
+		return err
+	}
+	if err := validatePositiveUint32(p.MaxChannels); err != nil {
+		return err
 	}
+	if err := validateMaxTimestampSkew(p.MaxTimestampSkew); err != nil {
+		return err
+	}
+	if err := validateMaxBackfillAge(p.MaxBackfillAge); err != nil {
+		return err
+	}
+	if err := validateFrameSize(p.FrameSize); err != nil {
+		return err
+	}
+	if err := validateOwnershipOfferDuration(p.OwnershipOfferDuration); err != nil {
+		return err
+	}
+	if err := validateBool(p.LegacyRecords); err != nil {
+		return err
+	}
+	if err := validateBandwidthBondPerRecord(p.BandwidthBondPerRecord); err != nil {
+		return err
+	}
+	if err := validateBandwidthPeriod(p.BandwidthPeriod); err != nil {
+		return err
+	}
+	if err := validateBandwidthUnbondingTime(p.BandwidthUnbondingTime); err != nil {
+		return err
+	}
+	if err := validateBandwidthGasPerRecord(p.BandwidthGasPerRecord); err != nil {
+		return err
+	}
+	if err := validateStorageDepositPerByte(p.StorageDepositPerByte); err != nil {
+		return err
+	}
+	if err := validateMaxRetention(p.MaxRetention); err != nil {
+		return err
+	}
+	return validatePositiveUint32(p.MaxPrunedPerBlock)
 }
 
 // DefaultParams defines the parameters for this module
 func DefaultParams() Params {
-	return NewParams( /* TODO: Pass in your default Params */ )
+	return NewParams(DefaultMaxRecordsPerMsg, DefaultMaxMiscLength, DefaultMaxChannels, DefaultMaxTimestampSkew, DefaultMaxBackfillAge, DefaultFrameSize, DefaultOwnershipOfferDuration, DefaultLegacyRecords,
+		DefaultBandwidthBondPerRecord, DefaultBandwidthPeriod, DefaultBandwidthUnbondingTime, DefaultBandwidthGasPerRecord, DefaultStorageDepositPerByte,
+		DefaultMaxRetention, DefaultMaxPrunedPerBlock)
+}
+
+func validateUint32(i interface{}) error {
+	if _, ok := i.(uint32); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validatePositiveUint32(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v == 0 {
+		return fmt.Errorf("parameter must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateMaxTimestampSkew(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v < 0 {
+		return fmt.Errorf("max timestamp skew must not be negative: %d", v)
+	}
+	return nil
+}
+
+func validateMaxBackfillAge(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v <= 0 {
+		return fmt.Errorf("max backfill age must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateFrameSize(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v < MinFrameSize {
+		return fmt.Errorf("frame size must be at least %d seconds: %d", MinFrameSize, v)
+	}
+	return nil
+}
+
+func validateOwnershipOfferDuration(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v <= 0 {
+		return fmt.Errorf("ownership offer duration must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateBool(i interface{}) error {
+	if _, ok := i.(bool); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validateBandwidthBondPerRecord(i interface{}) error {
+	v, ok := i.(sdk.Coin)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if err := sdk.ValidateDenom(v.Denom); err != nil {
+		return fmt.Errorf("invalid bandwidth bond denom: %s", err)
+	}
+	if v.IsNegative() {
+		return fmt.Errorf("bandwidth bond per record must not be negative: %s", v)
+	}
+	return nil
+}
+
+func validateBandwidthPeriod(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v <= 0 {
+		return fmt.Errorf("bandwidth period must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateBandwidthUnbondingTime(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v <= 0 {
+		return fmt.Errorf("bandwidth unbonding time must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateBandwidthGasPerRecord(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v == 0 {
+		return fmt.Errorf("bandwidth gas per record must be positive: %d", v)
+	}
+	return nil
+}
+
+func validateStorageDepositPerByte(i interface{}) error {
+	v, ok := i.(sdk.Coin)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if err := sdk.ValidateDenom(v.Denom); err != nil {
+		return fmt.Errorf("invalid storage deposit denom: %s", err)
+	}
+	if v.IsNegative() {
+		return fmt.Errorf("storage deposit per byte must not be negative: %s", v)
+	}
+	return nil
+}
+
+func validateMaxRetention(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if v < 0 {
+		return fmt.Errorf("max retention must not be negative: %d", v)
+	}
+	return nil
 }
@@ -2,19 +2,142 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 // Query endpoints supported by the datanode querier
 const (
-	QueryDataNode = "datanode"
-	QueryRecords  = "records"
+	QueryDataNode     = "datanode"
+	QueryRecords      = "records"
+	QueryRecordsRange = "records-range"
+	QueryParams       = "params"
+	QueryDataNodes    = "datanodes"
+	QueryOwnerNodes   = "datanodes-by-owner"
+
+	QueryOwnershipOffer = "ownership-offer"
+	QueryRoles          = "roles"
+	QueryFleet          = "fleet"
+	QueryFleetMembers   = "fleet-members"
+
+	QueryDeviceType      = "device-type"
+	QueryDeviceTypes     = "device-types"
+	QueryDeviceTypeNodes = "device-type-nodes"
+
+	QueryFeeAllowance = "fee-allowance"
+
+	QueryBandwidthQuota     = "bandwidth-quota"
+	QueryBandwidthUnbonding = "bandwidth-unbonding"
+
+	QueryStorageDeposit = "storage-deposit"
+
+	QueryRollups = "rollups"
 )
 
+// Page limits for the records-range query
+const (
+	DefaultRecordsRangeLimit = 1000
+	MaxRecordsRangeLimit     = 10000
+)
+
+// Page limits for the datanodes listing queries
+const (
+	DefaultDataNodesLimit = 100
+	MaxDataNodesLimit     = 1000
+)
+
+// QueryResDataNode - queries result payload for a datanode
+type QueryResDataNode struct {
+	DataNode DataNode      `json:"datanode"` // datanode metadata
+	Stats    DataNodeStats `json:"stats"`    // summary of the stored records
+}
+
+// implement fmt.Stringer
+func (r QueryResDataNode) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`%s
+		FirstTimeStamp: %d
+		LastTimeStamp: %d
+		TimeFrames: %d
+	`, r.DataNode, r.Stats.FirstTimeStamp, r.Stats.LastTimeStamp, r.Stats.TimeFrames))
+}
+
+// QueryResDataNodes - queries result payload for a page of datanodes
+type QueryResDataNodes struct {
+	DataNodes []DataNode     `json:"datanodes"` // datanodes of the page sorted by address
+	Next      sdk.AccAddress `json:"next"`      // address to continue from, empty if there are no more datanodes
+}
+
+// implement fmt.Stringer
+func (r QueryResDataNodes) String() string {
+	res, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(res)
+}
+
+// QueryResDeviceTypes - queries result payload for a page of device types
+type QueryResDeviceTypes struct {
+	DeviceTypes []DeviceType `json:"device_types"` // latest version of the device types of the page sorted by id
+	Next        string       `json:"next"`         // id to continue from, empty if there are no more device types
+}
+
+// implement fmt.Stringer
+func (r QueryResDeviceTypes) String() string {
+	res, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(res)
+}
+
+// QueryResFeeAllowance - queries result payload for the fee allowance of a datanode
+type QueryResFeeAllowance struct {
+	FeeAllowance    FeeAllowance `json:"fee_allowance"`    // limits and spending of the allowance
+	RemainingTotal  sdk.Coins    `json:"remaining_total"`  // fees that can still be charged overall, empty if not capped
+	RemainingPeriod sdk.Coins    `json:"remaining_period"` // fees that can still be charged on the current period, empty if not capped
+}
+
+// implement fmt.Stringer
+func (r QueryResFeeAllowance) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`%s
+		RemainingTotal: %s
+		RemainingPeriod: %s
+	`, r.FeeAllowance, r.RemainingTotal, r.RemainingPeriod))
+}
+
+// QueryResBandwidthQuota - queries result payload for the bandwidth quota of an owner on the current period
+type QueryResBandwidthQuota struct {
+	Owner     sdk.AccAddress `json:"owner"`      // owner of the datanodes using the quota
+	Bonded    sdk.Coins      `json:"bonded"`     // coins bonded, granting the quota
+	Quota     int64          `json:"quota"`      // records the datanodes can write without fees on a period
+	Used      int64          `json:"used"`       // records written without fees on the current period
+	Remaining int64          `json:"remaining"`  // records that can still be written without fees on the current period
+	PeriodEnd time.Time      `json:"period_end"` // the current period ends and the quota is renewed at this time
+}
+
+// implement fmt.Stringer
+func (r QueryResBandwidthQuota) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+		Owner: %s
+		Bonded: %s
+		Quota: %d
+		Used: %d
+		Remaining: %d
+		PeriodEnd: %s
+	`, r.Owner, r.Bonded, r.Quota, r.Used, r.Remaining, r.PeriodEnd))
+}
+
 // QueryResRecords - queries result payload for a single record
 type QueryResRecords struct {
-	TimeStamp uint32 `json:"ts"`    // timestamp in seconds since epoch
-	Value     uint32 `json:"value"` // numeric value of the record
-	Misc      string `json:"misc"`  // miscellaneous data for other non numeric records
+	TimeStamp int64  `json:"ts"`             // timestamp in milliseconds since epoch
+	Value     int64  `json:"value"`          // numeric value of the record
+	Misc      string `json:"misc"`           // miscellaneous data for other non numeric records
+	Decoded   string `json:"decoded"`        // value read with the channel encoding (ex. -12.50, true or a label)
+	Unit      string `json:"unit,omitempty"` // UCUM code of the unit of the channel
 }
 
 // QueryResRecordsList - queries result payload for datarecords within time frame
@@ -28,3 +151,18 @@ func (r QueryResRecordsList) String() string {
 	}
 	return string(res)
 }
+
+// QueryResRecordsRange - queries result payload for records within a time range
+type QueryResRecordsRange struct {
+	Records QueryResRecordsList `json:"records"` // records of the page sorted by timestamp
+	Next    int64               `json:"next"`    // timestamp in milliseconds to continue from, 0 if there are no more records
+}
+
+// implement fmt.Stringer
+func (r QueryResRecordsRange) String() string {
+	res, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(res)
+}
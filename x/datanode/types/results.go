@@ -0,0 +1,32 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RecordResult - outcome of a new record of a message adding records
+type RecordResult struct {
+	Index   int    `json:"index"`            // position of the record on the datanode records of the message
+	Channel string `json:"channel"`          // channel of the record
+	Time    int64  `json:"time"`             // timestamp in milliseconds of the record
+	Reason  string `json:"reason,omitempty"` // why the record was rejected
+}
+
+// RecordsResult - outcome of the records of a datanode on a message adding records, the results of a
+// message are returned JSON encoded as a list with an item by datanode on the data of the message result
+type RecordsResult struct {
+	DataNode   sdk.AccAddress `json:"datanode"`   // datanode of the records
+	Accepted   []RecordResult `json:"accepted"`   // records added
+	Duplicates []RecordResult `json:"duplicates"` // records not added, there's a record at the same time on the channel
+	Rejected   []RecordResult `json:"rejected"`   // records not added, with the reason
+}
+
+// NewRecordsResult creates an empty result for the records of the datanode
+func NewRecordsResult(dataNode sdk.AccAddress) RecordsResult {
+	return RecordsResult{
+		DataNode:   dataNode,
+		Accepted:   []RecordResult{},
+		Duplicates: []RecordResult{},
+		Rejected:   []RecordResult{},
+	}
+}
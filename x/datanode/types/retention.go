@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Rollup - summary of the records of a channel time frame deleted by the pruning, the values are
+// read with the channel encoding
+type Rollup struct {
+	DataNode  sdk.AccAddress `json:"datanode"`  // datanode which pushed the records
+	Channel   string         `json:"channel"`   // channel of the records
+	TimeFrame int64          `json:"timeframe"` // time frame of the records
+	Count     int64          `json:"count"`     // records pruned
+	Sum       sdk.Int        `json:"sum"`       // sum of the values pruned
+	Min       int64          `json:"min"`       // minimum value pruned
+	Max       int64          `json:"max"`       // maximum value pruned
+	First     int64          `json:"first"`     // timestamp in milliseconds of the first record pruned
+	Last      int64          `json:"last"`      // timestamp in milliseconds of the last record pruned
+}
+
+// NewRollup creates a rollup of the datanode channel time frame without records
+func NewRollup(dataNode sdk.AccAddress, channelID string, timeFrame int64) Rollup {
+	return Rollup{
+		DataNode:  dataNode,
+		Channel:   channelID,
+		TimeFrame: timeFrame,
+		Sum:       sdk.ZeroInt(),
+	}
+}
+
+// implement fmt.Stringer
+func (r Rollup) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+		DataNode: %s
+		Channel: %s
+		TimeFrame: %d
+		Count: %d
+		Sum: %s
+		Min: %d
+		Max: %d
+		First: %d
+		Last: %d
+	`, r.DataNode, r.Channel, r.TimeFrame, r.Count, r.Sum, r.Min, r.Max, r.First, r.Last))
+}
+
+// Add returns the rollup summarizing the record too
+func (r Rollup) Add(record Record) Rollup {
+	if r.Count == 0 || record.Value < r.Min {
+		r.Min = record.Value
+	}
+	if r.Count == 0 || record.Value > r.Max {
+		r.Max = record.Value
+	}
+	if r.Count == 0 || record.TimeStamp < r.First {
+		r.First = record.TimeStamp
+	}
+	if r.Count == 0 || record.TimeStamp > r.Last {
+		r.Last = record.TimeStamp
+	}
+	r.Count++
+	r.Sum = r.Sum.AddRaw(record.Value)
+	return r
+}
+
+// Merge returns the rollup summarizing the records of the other one too
+func (r Rollup) Merge(other Rollup) Rollup {
+	if other.Count == 0 {
+		return r
+	}
+	if r.Count == 0 || other.Min < r.Min {
+		r.Min = other.Min
+	}
+	if r.Count == 0 || other.Max > r.Max {
+		r.Max = other.Max
+	}
+	if r.Count == 0 || other.First < r.First {
+		r.First = other.First
+	}
+	if r.Count == 0 || other.Last > r.Last {
+		r.Last = other.Last
+	}
+	r.Count += other.Count
+	r.Sum = r.Sum.Add(other.Sum)
+	return r
+}
+
+// Validate returns an error if the rollup is malformed
+func (r Rollup) Validate() error {
+	if r.DataNode.Empty() {
+		return fmt.Errorf("rollup without datanode")
+	}
+	if len(r.Channel) == 0 || len(r.Channel) > MaxChannelIDLength {
+		return fmt.Errorf("rollup of %s with invalid channel %q", r.DataNode, r.Channel)
+	}
+	if r.TimeFrame < 0 || r.Count <= 0 || r.Min > r.Max || r.First > r.Last {
+		return fmt.Errorf("invalid rollup of %s channel %s time frame %d", r.DataNode, r.Channel, r.TimeFrame)
+	}
+	return nil
+}
+
+// ChannelRetention returns the seconds the records of the channel are kept after their time frame ends,
+// capped by the max retention, zero when they are kept forever. Records of deleted channels, nil, are
+// kept up to the max retention
+func ChannelRetention(channel *NodeChannel, maxRetention int64) int64 {
+	var retention int64
+	if channel != nil {
+		retention = channel.Retention
+	}
+	if maxRetention > 0 && (retention == 0 || retention > maxRetention) {
+		return maxRetention
+	}
+	return retention
+}
+
+// TimeFrameExpiry returns the time the records of the time frame expire at, after the retention seconds
+// from the end of the time frame
+func TimeFrameExpiry(timeFrame int64, frameSize int64, retention int64) time.Time {
+	return time.Unix((timeFrame+1)*frameSize+retention, 0).UTC()
+}
+
+// RecordsPruned - records of a channel time frame deleted by the pruning and the storage deposit refunded
+type RecordsPruned struct {
+	DataNode  sdk.AccAddress `json:"datanode"`  // datanode the records were deleted from
+	Channel   string         `json:"channel"`   // channel of the records
+	TimeFrame int64          `json:"timeframe"` // time frame of the records
+	Count     int            `json:"count"`     // records deleted
+	Refund    StorageRefund  `json:"refund"`    // storage deposit refunded
+}
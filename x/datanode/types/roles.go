@@ -0,0 +1,60 @@
+package types
+
+// Role of an account on a datanode, every role has the permissions of the roles below it
+type Role string
+
+// Datanode roles, sorted from the most to the least privileged
+const (
+	RoleAdmin    Role = "admin"    // transfer and delete the datanode, manage the non admin roles
+	RoleOperator Role = "operator" // edit the channels and the metadata
+	RoleWriter   Role = "writer"   // add records
+	RoleViewer   Role = "viewer"   // no permissions on chain, for off chain applications access control
+)
+
+// Permission to run an action on a datanode
+type Permission int
+
+// Datanode permissions
+const (
+	PermissionWrite Permission = iota
+	PermissionEditChannels
+	PermissionEditMetadata
+	PermissionManageRoles
+	PermissionTransfer
+	PermissionDelete
+	PermissionBackfill
+)
+
+// roleRanks - rank of the roles, a role has the permissions of the lower ranked ones
+var roleRanks = map[Role]int{
+	RoleViewer:   0,
+	RoleWriter:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// permissionRoles - least privileged role holding each permission
+var permissionRoles = map[Permission]Role{
+	PermissionWrite:        RoleWriter,
+	PermissionEditChannels: RoleOperator,
+	PermissionEditMetadata: RoleOperator,
+	PermissionManageRoles:  RoleAdmin,
+	PermissionTransfer:     RoleAdmin,
+	PermissionDelete:       RoleAdmin,
+	PermissionBackfill:     RoleAdmin,
+}
+
+// IsValid returns true if the role is one of the datanode roles
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows returns true if the role holds the permission
+func (r Role) Allows(p Permission) bool {
+	rank, ok := roleRanks[r]
+	if !ok {
+		return false
+	}
+	return rank >= roleRanks[permissionRoles[p]]
+}
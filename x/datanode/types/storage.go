@@ -0,0 +1,172 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// StorageDepositPoolName - module account holding the storage deposits of the datanodes
+const StorageDepositPoolName = "datanode_storage_deposits"
+
+// StorageDeposit - bytes taken by the records of a datanode on the store and the coins deposited for
+// them, refunded to their depositors as the records are pruned or the datanode is deleted
+type StorageDeposit struct {
+	DataNode   sdk.AccAddress     `json:"datanode"`             // datanode storing the records
+	Bytes      int64              `json:"bytes"`                // bytes of the records stored, including the ones stored before the deposits
+	Amount     sdk.Coins          `json:"amount"`               // coins deposited for the records stored
+	Depositors []StorageDepositor `json:"depositors,omitempty"` // accounts that deposited the coins and their share, the coins deposited before they were recorded are refunded to the fee payer
+}
+
+// StorageDepositor - coins of a storage deposit paid or refunded to an account
+type StorageDepositor struct {
+	Address sdk.AccAddress `json:"address"` // account paying or receiving the coins
+	Amount  sdk.Coins      `json:"amount"`  // coins of the account
+}
+
+// NewStorageDeposit creates a deposit of the datanode without bytes or coins
+func NewStorageDeposit(dataNode sdk.AccAddress) StorageDeposit {
+	return StorageDeposit{
+		DataNode: dataNode,
+		Amount:   sdk.Coins{},
+	}
+}
+
+// implement fmt.Stringer
+func (d StorageDeposit) String() string {
+	depositors := make([]string, len(d.Depositors))
+	for i, depositor := range d.Depositors {
+		depositors[i] = fmt.Sprintf("%s: %s", depositor.Address, depositor.Amount)
+	}
+	return strings.TrimSpace(fmt.Sprintf(`
+		DataNode: %s
+		Bytes: %d
+		Amount: %s
+		Depositors: %s
+	`, d.DataNode, d.Bytes, d.Amount, strings.Join(depositors, ", ")))
+}
+
+// IsEmpty returns true if the deposit has no bytes or coins
+func (d StorageDeposit) IsEmpty() bool {
+	return d.Bytes == 0 && d.Amount.Empty()
+}
+
+// Unrecorded returns the coins of the deposit without depositor, deposited before they were recorded
+func (d StorageDeposit) Unrecorded() sdk.Coins {
+	unrecorded := d.Amount
+	for _, depositor := range d.Depositors {
+		unrecorded = unrecorded.Sub(depositor.Amount)
+	}
+	return unrecorded
+}
+
+// Deposit returns the deposit with the coins paid by the depositor added to its share
+func (d StorageDeposit) Deposit(address sdk.AccAddress, amount sdk.Coins) StorageDeposit {
+	d.Amount = d.Amount.Add(amount...)
+	depositors := make([]StorageDepositor, 0, len(d.Depositors)+1)
+	found := false
+	for _, depositor := range d.Depositors {
+		if depositor.Address.Equals(address) {
+			depositor.Amount = depositor.Amount.Add(amount...)
+			found = true
+		}
+		depositors = append(depositors, depositor)
+	}
+	if !found {
+		depositors = append(depositors, StorageDepositor{Address: address, Amount: amount})
+	}
+	d.Depositors = depositors
+	return d
+}
+
+// Release returns the coins deposited for the bytes released, their shares by depositor and the deposit
+// without them. The coins are released in proportion to the bytes, all of them when no bytes are left,
+// and split among the depositors in proportion to their shares. The share of the coins without depositor
+// is returned with an empty address
+func (d StorageDeposit) Release(bytes int64) (sdk.Coins, []StorageDepositor, StorageDeposit) {
+	released := d.Amount
+	if bytes < d.Bytes {
+		released = sdk.Coins{}
+		for _, coin := range d.Amount {
+			amount := coin.Amount.MulRaw(bytes).QuoRaw(d.Bytes)
+			if amount.IsPositive() {
+				released = released.Add(sdk.NewCoin(coin.Denom, amount))
+			}
+		}
+	}
+
+	holders := append([]StorageDepositor{}, d.Depositors...)
+	if unrecorded := d.Unrecorded(); !unrecorded.Empty() {
+		holders = append(holders, StorageDepositor{Amount: unrecorded})
+	}
+	shares := make([]sdk.Coins, len(holders))
+	for i := range shares {
+		shares[i] = sdk.Coins{}
+	}
+	for _, coin := range released {
+		total := d.Amount.AmountOf(coin.Denom)
+		left := coin.Amount
+		amounts := make([]sdk.Int, len(holders))
+		for i, holder := range holders {
+			amounts[i] = coin.Amount.Mul(holder.Amount.AmountOf(coin.Denom)).Quo(total)
+			left = left.Sub(amounts[i])
+		}
+		// the rounding remainder goes a coin each to the first holders with coins left
+		for i, holder := range holders {
+			if !left.IsPositive() {
+				break
+			}
+			if amounts[i].LT(holder.Amount.AmountOf(coin.Denom)) {
+				amounts[i] = amounts[i].AddRaw(1)
+				left = left.SubRaw(1)
+			}
+		}
+		for i, amount := range amounts {
+			if amount.IsPositive() {
+				shares[i] = shares[i].Add(sdk.NewCoin(coin.Denom, amount))
+			}
+		}
+	}
+
+	var refunds []StorageDepositor
+	depositors := make([]StorageDepositor, 0, len(d.Depositors))
+	for i, holder := range holders {
+		if !shares[i].Empty() {
+			refunds = append(refunds, StorageDepositor{Address: holder.Address, Amount: shares[i]})
+		}
+		if i < len(d.Depositors) {
+			if amount := holder.Amount.Sub(shares[i]); !amount.Empty() {
+				depositors = append(depositors, StorageDepositor{Address: holder.Address, Amount: amount})
+			}
+		}
+	}
+	if len(depositors) == 0 {
+		depositors = nil
+	}
+
+	d.Bytes -= bytes
+	if d.Bytes < 0 {
+		d.Bytes = 0
+	}
+	d.Amount = d.Amount.Sub(released)
+	d.Depositors = depositors
+	return released, refunds, d
+}
+
+// StorageCost returns the coins deposited for the bytes at the deposit per byte, none when the deposit
+// per byte is zero
+func StorageCost(bytes int64, depositPerByte sdk.Coin) sdk.Coins {
+	if bytes <= 0 || !depositPerByte.IsPositive() {
+		return sdk.Coins{}
+	}
+	return sdk.NewCoins(sdk.NewCoin(depositPerByte.Denom, depositPerByte.Amount.MulRaw(bytes)))
+}
+
+// StorageRefund - coins of a storage deposit returned to their depositors for the bytes released
+type StorageRefund struct {
+	DataNode sdk.AccAddress     `json:"datanode"` // datanode the records were deleted from
+	Bytes    int64              `json:"bytes"`    // bytes of the records deleted
+	Amount   sdk.Coins          `json:"amount"`   // coins refunded
+	Refunds  []StorageDepositor `json:"refunds"`  // coins refunded to each depositor
+}
@@ -3,45 +3,284 @@ package types
 import (
 	"crypto/md5"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-const (
-	timeFrame = 24 * 3600
-)
-
 // DataRecordHash is the hash key of the records time frame
 type DataRecordHash [16]byte
 
 // NodeChannel holds information about the data channel of the DataNode
 type NodeChannel struct {
-	ID       string `json:"id,omitempty"` // id of the channel
-	Variable string `json:"variable"`     // variable of the channel (ex. temperature, humidity)
+	ID        string        `json:"id,omitempty"`         // id of the channel
+	Variable  string        `json:"variable"`             // variable of the channel (ex. temperature, humidity)
+	Unit      string        `json:"unit,omitempty"`       // UCUM code of the unit of the values (ex. Cel, %, kPa)
+	Encoding  ValueEncoding `json:"encoding,omitempty"`   // encoding of the record values, uint32 when empty
+	FrameSize int64         `json:"frame_size,omitempty"` // legacy, ignored: the records are grouped by the module frame size
+	Scale     uint32        `json:"scale,omitempty"`      // decimal digits of the decimal encoding values
+	Enum      []string      `json:"enum,omitempty"`       // labels of the enum encoding values, the value is the label index
+	Min       string        `json:"min,omitempty"`        // minimum decoded value accepted, no minimum when empty
+	Max       string        `json:"max,omitempty"`        // maximum decoded value accepted, no maximum when empty
+	Retention int64         `json:"retention,omitempty"`  // seconds the records are kept after their time frame ends, the module max retention when 0
+	Rollup    bool          `json:"rollup,omitempty"`     // keep a summary of each time frame pruned
+}
+
+// ValueEncoding tells how the numeric value of the records of a channel is read
+type ValueEncoding string
+
+// Record value encodings
+const (
+	EncodingUint32  ValueEncoding = "uint32"  // the value as is
+	EncodingInt32   ValueEncoding = "int32"   // two's complement signed value
+	EncodingInt64   ValueEncoding = "int64"   // signed 64-bit value
+	EncodingDecimal ValueEncoding = "decimal" // signed value with scale decimal digits
+	EncodingFloat32 ValueEncoding = "float32" // IEEE 754 bits of the value
+	EncodingBool    ValueEncoding = "bool"    // 0 is false, any other value is true
+	EncodingEnum    ValueEncoding = "enum"    // index of the label of the value
+	EncodingMisc    ValueEncoding = "misc"    // the value is not used, the record data is on misc
+)
+
+// IsValid returns true if the encoding is empty or one of the record value encodings
+func (e ValueEncoding) IsValid() bool {
+	switch e {
+	case "", EncodingUint32, EncodingInt32, EncodingInt64, EncodingDecimal, EncodingFloat32, EncodingBool, EncodingEnum, EncodingMisc:
+		return true
+	}
+	return false
+}
+
+// IsNumeric returns true if the values of the encoding can have bounds
+func (e ValueEncoding) IsNumeric() bool {
+	switch e {
+	case "", EncodingUint32, EncodingInt32, EncodingInt64, EncodingDecimal, EncodingFloat32:
+		return true
+	}
+	return false
 }
 
 // DataNode holds the configuration and the owner of the DataNode Device
 type DataNode struct {
-	ID       sdk.AccAddress   `json:"id,omitempty"` // id of the datanode
-	Owner    sdk.AccAddress   `json:"owner"`        // account address that owns the DataNode
-	Name     string           `json:"name"`         // name of the datanode
-	Channels []NodeChannel    `json:"channels"`     // channel definition
-	Records  []DataRecordHash `json:"records"`      // datarecords associated to this DataNode
+	ID              sdk.AccAddress `json:"id,omitempty"`     // id of the datanode
+	Owner           sdk.AccAddress `json:"owner"`            // account address that owns the DataNode
+	Name            string         `json:"name"`             // name of the datanode
+	Channels        []NodeChannel  `json:"channels"`         // channel definition
+	Description     string         `json:"description"`      // free text description of the datanode
+	Tags            []string       `json:"tags"`             // tags to classify the datanode
+	Location        string         `json:"location"`         // location of the datanode device
+	FirmwareVersion string         `json:"firmware_version"` // firmware version running on the device
+	Archived        bool           `json:"archived"`         // decommissioned datanode, records are kept but no new ones accepted
+	Fleet           string         `json:"fleet"`            // id of the fleet the datanode is member of, empty if none
+	DeviceType      string         `json:"device_type"`      // id of the device type the datanode is linked to, empty if none
+	TypeVersion     uint32         `json:"type_version"`     // version of the device type the channels come from
+	Backfill        bool           `json:"backfill"`         // accept records older than the max backfill age, to upload historical data
+	FeePayer        sdk.AccAddress `json:"fee_payer"`        // account paying the fees of the datanode, takes over the fleet fee payer and the owner
+}
+
+// DataNodeStats summarizes the records stored by a DataNode
+type DataNodeStats struct {
+	FirstTimeStamp int64  `json:"first_timestamp"` // timestamp in milliseconds of the oldest record, 0 if there are no records
+	LastTimeStamp  int64  `json:"last_timestamp"`  // timestamp in milliseconds of the newest record, 0 if there are no records
+	TimeFrames     uint64 `json:"timeframes"`      // number of channel time frames holding records
+}
+
+// OwnershipOffer is a pending transfer of the datanode ownership, waiting for the new owner to accept it
+type OwnershipOffer struct {
+	DataNode sdk.AccAddress `json:"datanode"` // datanode offered
+	Owner    sdk.AccAddress `json:"owner"`    // owner of the datanode making the offer
+	NewOwner sdk.AccAddress `json:"newowner"` // account that can accept the offer
+	Expiry   time.Time      `json:"expiry"`   // the offer can't be accepted from this time on
+}
+
+// implement fmt.Stringer
+func (o OwnershipOffer) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+		DataNode: %s
+		Owner: %s
+		NewOwner: %s
+		Expiry: %s
+	`, o.DataNode, o.Owner, o.NewOwner, o.Expiry))
+}
+
+// RoleGrant gives an account a role on the datanode, the owner holds every permission without grants
+type RoleGrant struct {
+	DataNode sdk.AccAddress `json:"datanode"` // datanode the role is granted on
+	Address  sdk.AccAddress `json:"address"`  // account the role is granted to
+	Role     Role           `json:"role"`     // role of the account
+	Channels []string       `json:"channels"` // channels a writer can write, all of them when empty
+	Expiry   time.Time      `json:"expiry"`   // the grant is not valid from this time on, zero if it doesn't expire
+}
+
+// implement fmt.Stringer
+func (g RoleGrant) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+		DataNode: %s
+		Address: %s
+		Role: %s
+		Channels: %s
+		Expiry: %s
+	`, g.DataNode, g.Address, g.Role, strings.Join(g.Channels, ","), g.Expiry))
+}
+
+// IsActive returns true if the grant has not expired at the given time
+func (g RoleGrant) IsActive(now time.Time) bool {
+	return g.Expiry.IsZero() || now.Before(g.Expiry)
+}
+
+// CanWriteChannel returns true if the grant allows writing the channel at the given time
+func (g RoleGrant) CanWriteChannel(channelID string, now time.Time) bool {
+	if !g.IsActive(now) || !g.Role.Allows(PermissionWrite) {
+		return false
+	}
+	if len(g.Channels) == 0 {
+		return true
+	}
+	for _, ch := range g.Channels {
+		if ch == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// Fleet groups datanodes of different owners managed together by the fleet admins
+type Fleet struct {
+	ID              string           `json:"id"`               // unique id of the fleet
+	Admins          []sdk.AccAddress `json:"admins"`           // accounts managing the fleet
+	ChannelTemplate []NodeChannel    `json:"channel_template"` // channels set on every member datanode
+	FeePayer        sdk.AccAddress   `json:"fee_payer"`        // account paying the fees of the members, the owners pay when empty
+}
+
+// NewFleet returns a new Fleet with the admin
+func NewFleet(id string, admin sdk.AccAddress) Fleet {
+	return Fleet{
+		ID:     id,
+		Admins: []sdk.AccAddress{admin},
+	}
+}
+
+// implement fmt.Stringer
+func (f Fleet) String() string {
+	admins := make([]string, len(f.Admins))
+	for i, admin := range f.Admins {
+		admins[i] = admin.String()
+	}
+	channels := make([]string, len(f.ChannelTemplate))
+	for i, ch := range f.ChannelTemplate {
+		channels[i] = ch.ID + ":" + ch.Variable
+	}
+	return strings.TrimSpace(fmt.Sprintf(`
+		ID: %s
+		Admins: %s
+		ChannelTemplate: %s
+		FeePayer: %s
+	`, f.ID, strings.Join(admins, ","), strings.Join(channels, ","), f.FeePayer))
+}
+
+// IsAdmin returns true if the account is admin of the fleet
+func (f Fleet) IsAdmin(address sdk.AccAddress) bool {
+	for _, admin := range f.Admins {
+		if admin.Equals(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fleet limits, enforced on messages and genesis
+const (
+	MaxFleetIDLength = 64
+	MaxFleetAdmins   = 16
+)
+
+// ValidateFleetID checks the fleet id is not empty, not longer than MaxFleetIDLength and only
+// has letters, digits, '-', '_' and '.'
+func ValidateFleetID(id string) error {
+	return validateIdentifier("fleet", id, MaxFleetIDLength)
+}
+
+// validateIdentifier checks the id is not empty, not longer than maxLength and only has letters,
+// digits, '-', '_' and '.'
+func validateIdentifier(kind string, id string, maxLength int) error {
+	if len(id) == 0 || len(id) > maxLength {
+		return fmt.Errorf("%s id must have between 1 and %d characters", kind, maxLength)
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return fmt.Errorf("invalid character %q on %s id", c, kind)
+		}
+	}
+	return nil
+}
+
+// ValidateFleetAdmins checks the fleet has between 1 and MaxFleetAdmins admins without duplicates
+func ValidateFleetAdmins(admins []sdk.AccAddress) error {
+	if len(admins) == 0 || len(admins) > MaxFleetAdmins {
+		return fmt.Errorf("fleet must have between 1 and %d admins", MaxFleetAdmins)
+	}
+	seen := make(map[string]bool)
+	for _, admin := range admins {
+		if admin.Empty() {
+			return fmt.Errorf("empty fleet admin")
+		}
+		if seen[admin.String()] {
+			return fmt.Errorf("duplicated fleet admin %s", admin)
+		}
+		seen[admin.String()] = true
+	}
+	return nil
+}
+
+// MaxUnitLength - maximum length of the unit of a channel
+const MaxUnitLength = 16
+
+// ValidateChannel checks the channel id, retention and value schema
+func ValidateChannel(channel NodeChannel) error {
+	if len(channel.ID) == 0 || len(channel.ID) > MaxChannelIDLength {
+		return fmt.Errorf("channel id must have between 1 and %d characters", MaxChannelIDLength)
+	}
+	if channel.Retention < 0 {
+		return fmt.Errorf("channel %s retention must not be negative", channel.ID)
+	}
+	if err := validateSchema(channel); err != nil {
+		return fmt.Errorf("channel %s %s", channel.ID, err)
+	}
+	return nil
+}
+
+// ValidateChannels checks every channel is valid and their ids are unique
+func ValidateChannels(channels []NodeChannel) error {
+	seen := make(map[string]bool)
+	for _, ch := range channels {
+		if err := ValidateChannel(ch); err != nil {
+			return err
+		}
+		if seen[ch.ID] {
+			return fmt.Errorf("duplicated channel %s", ch.ID)
+		}
+		seen[ch.ID] = true
+	}
+	return nil
 }
 
 // Record holds a single record from the DataNode device
 type Record struct {
-	TimeStamp uint32 `json:"t"` // timestamp in seconds since epoch
-	Value     uint32 `json:"v"` // numeric value of the record
+	TimeStamp int64  `json:"t"` // timestamp in milliseconds since epoch
+	Value     int64  `json:"v"` // numeric value of the record, read with the channel encoding
 	Misc      string `json:"m"` // miscellaneous data for other non numeric records
 }
 
+// Unix returns the timestamp of the record in seconds since epoch
+func (r Record) Unix() int64 {
+	return r.TimeStamp / MillisPerSecond
+}
+
 // implement fmt.Stringer
 func (r Record) String() string {
 	return strings.TrimSpace(fmt.Sprintf(`
-		TimeStamp: %d, Value: %f, Misc: %s
+		TimeStamp: %d, Value: %d, Misc: %s
 	`, r.TimeStamp, r.Value, r.Misc))
 }
 
@@ -68,34 +307,158 @@ func (d DataNode) String() string {
 		ID: %s
 		Owner: %s
 		Name: %s
-	`, d.ID, d.Owner, d.Name))
+		Description: %s
+		Tags: %s
+		Location: %s
+		FirmwareVersion: %s
+		Archived: %t
+		Fleet: %s
+		DeviceType: %s
+		TypeVersion: %d
+		FeePayer: %s
+	`, d.ID, d.Owner, d.Name, d.Description, strings.Join(d.Tags, ","), d.Location, d.FirmwareVersion, d.Archived, d.Fleet, d.DeviceType, d.TypeVersion, d.FeePayer))
+}
+
+// Metadata limits, enforced on messages and genesis
+const (
+	MaxNameLength            = 64
+	MaxDescriptionLength     = 256
+	MaxTags                  = 16
+	MaxTagLength             = 32
+	MaxLocationLength        = 128
+	MaxFirmwareVersionLength = 32
+)
+
+// ValidateMetadata checks the datanode descriptive fields against the metadata limits
+func ValidateMetadata(name string, description string, tags []string, location string, firmwareVersion string) error {
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("name can't have more than %d characters", MaxNameLength)
+	}
+	if len(description) > MaxDescriptionLength {
+		return fmt.Errorf("description can't have more than %d characters", MaxDescriptionLength)
+	}
+	if len(tags) > MaxTags {
+		return fmt.Errorf("can't have more than %d tags", MaxTags)
+	}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if len(tag) == 0 || len(tag) > MaxTagLength {
+			return fmt.Errorf("tags must have between 1 and %d characters", MaxTagLength)
+		}
+		if seen[tag] {
+			return fmt.Errorf("duplicated tag %s", tag)
+		}
+		seen[tag] = true
+	}
+	if len(location) > MaxLocationLength {
+		return fmt.Errorf("location can't have more than %d characters", MaxLocationLength)
+	}
+	if len(firmwareVersion) > MaxFirmwareVersionLength {
+		return fmt.Errorf("firmware version can't have more than %d characters", MaxFirmwareVersionLength)
+	}
+	return nil
+}
+
+// HasChannel returns true if the datanode has the channel defined
+func (d DataNode) HasChannel(channel NodeChannel) bool {
+	for _, c := range d.Channels {
+		if c.ID == channel.ID && c.Variable == channel.Variable {
+			return true
+		}
+	}
+	return false
+}
+
+// HasChannelID returns true if the datanode has a channel with the id
+func (d DataNode) HasChannelID(channelID string) bool {
+	for _, c := range d.Channels {
+		if c.ID == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChannel returns the channel with the id and true, or false if the datanode doesn't have it
+func (d DataNode) GetChannel(channelID string) (NodeChannel, bool) {
+	for _, c := range d.Channels {
+		if c.ID == channelID {
+			return c, true
+		}
+	}
+	return NodeChannel{}, false
 }
 
 // NewDataRecord returns a new DataRecord with the DataNode and the NodeChannel and empty records set
-func NewDataRecord(dataNode sdk.AccAddress, channel *NodeChannel, date int64) DataRecord {
+func NewDataRecord(dataNode sdk.AccAddress, channel *NodeChannel, timeFrame int64) DataRecord {
 	records := []Record{}
 	return DataRecord{
 		DataNode:    dataNode,
 		NodeChannel: *channel,
-		TimeFrame:   date / timeFrame,
+		TimeFrame:   timeFrame,
 		Records:     records,
 	}
 }
 
-// GetActualDataRecordHash returns the hash key to be used for KVStore at actual time
-func GetActualDataRecordHash(dataNode sdk.AccAddress, channel *NodeChannel) DataRecordHash {
-	now := time.Now()
-	return GetDataRecordHash(dataNode, channel, now.Unix())
+// GetTimeFrame returns the index of the time frame of frameSize seconds that contains the timestamp
+func GetTimeFrame(timestamp int64, frameSize int64) int64 {
+	return timestamp / frameSize
 }
 
-// GetDataRecordHash returns the hash key to be used for KVStore
-func GetDataRecordHash(dataNode sdk.AccAddress, channel *NodeChannel, date int64) DataRecordHash {
-	// use 1500000000 seconds as a safe time to detect if date is in seconds or days
-	if date > 1500000000 {
-		date = date / timeFrame
+// MillisPerSecond - milliseconds of a second, the records timestamps are in milliseconds
+const MillisPerSecond int64 = 1000
+
+// TimeUnit tells how a time of the records queries is expressed
+type TimeUnit string
+
+// Time units of the records queries
+const (
+	TimeUnitFrame  TimeUnit = "frame" // index of the time frame
+	TimeUnitSecond TimeUnit = "s"     // seconds since epoch
+	TimeUnitMilli  TimeUnit = "ms"    // milliseconds since epoch
+)
+
+// TimeFrame returns the index of the time frame of frameSize seconds that contains the time
+func (u TimeUnit) TimeFrame(t int64, frameSize int64) (int64, error) {
+	switch u {
+	case TimeUnitFrame:
+		return t, nil
+	case TimeUnitSecond:
+		return GetTimeFrame(t, frameSize), nil
+	case TimeUnitMilli:
+		return GetTimeFrame(t/MillisPerSecond, frameSize), nil
 	}
-	// Use days since epoch as daily time frame to group records
-	key := fmt.Sprintf("%s%s%s%d", dataNode.String(), channel.ID, channel.Variable, date)
+	return 0, fmt.Errorf("invalid time unit %s", u)
+}
+
+// Millis returns the first and the last millisecond of the time, time frames are not allowed. Seconds
+// out of the milliseconds range are capped to it
+func (u TimeUnit) Millis(t int64) (int64, int64, error) {
+	switch u {
+	case TimeUnitSecond:
+		if t > math.MaxInt64/MillisPerSecond-1 {
+			return math.MaxInt64, math.MaxInt64, nil
+		}
+		if t < math.MinInt64/MillisPerSecond {
+			return math.MinInt64, math.MinInt64, nil
+		}
+		return t * MillisPerSecond, t*MillisPerSecond + MillisPerSecond - 1, nil
+	case TimeUnitMilli:
+		return t, t, nil
+	}
+	return 0, 0, fmt.Errorf("invalid time unit %s", u)
+}
+
+// GetActualDataRecordHash returns the hash key to be used for KVStore at the given time,
+// callers on the state machine must use the block time to keep it deterministic
+func GetActualDataRecordHash(dataNode sdk.AccAddress, channel *NodeChannel, now time.Time, frameSize int64) DataRecordHash {
+	return GetDataRecordHash(dataNode, channel, GetTimeFrame(now.Unix(), frameSize))
+}
+
+// GetDataRecordHash returns the hash key to be used for KVStore
+func GetDataRecordHash(dataNode sdk.AccAddress, channel *NodeChannel, timeFrame int64) DataRecordHash {
+	// Use the time frame index since epoch to group records
+	key := fmt.Sprintf("%s%s%s%d", dataNode.String(), channel.ID, channel.Variable, timeFrame)
 
 	return md5.Sum([]byte(key))
 }
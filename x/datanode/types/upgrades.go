@@ -0,0 +1,32 @@
+package types
+
+// Names of the upgrade plans that migrate the datanode store, the upgrade handlers
+// are registered by the app and must run once at the planned height
+const (
+	// UpgradeKeyPrefixes moves datanodes and datarecords from raw keys to prefixed keys
+	UpgradeKeyPrefixes = "datanode-key-prefixes"
+	// UpgradeRecordsLayout splits the daily datarecords into single records keyed by timestamp
+	UpgradeRecordsLayout = "datanode-records-layout"
+	// UpgradeTimeFrameIndex moves the datarecord hashes of the datanodes to the time frame index
+	UpgradeTimeFrameIndex = "datanode-timeframe-index"
+	// UpgradeOwnerIndex indexes the existing datanodes by owner
+	UpgradeOwnerIndex = "datanode-owner-index"
+	// UpgradeOwnershipOffers sets the ownership offer duration parameter
+	UpgradeOwnershipOffers = "datanode-ownership-offers"
+	// UpgradeRecordsV2 converts the records to millisecond timestamps and 64-bit values
+	UpgradeRecordsV2 = "datanode-records-v2"
+	// UpgradeAcceptanceWindow sets the max backfill age parameter
+	UpgradeAcceptanceWindow = "datanode-acceptance-window"
+	// UpgradeBandwidthQuota sets the bandwidth bond, period and unbonding time parameters
+	UpgradeBandwidthQuota = "datanode-bandwidth-quota"
+	// UpgradeStorageDeposits sets the storage deposit parameter and counts the bytes stored by the datanodes
+	UpgradeStorageDeposits = "datanode-storage-deposits"
+	// UpgradeRetention sets the retention parameters and queues the time frames stored for pruning
+	UpgradeRetention = "datanode-retention"
+	// UpgradeFleetKeys moves the fleets to length prefixed keys
+	UpgradeFleetKeys = "datanode-fleet-keys"
+	// UpgradeFrameSize records the frame size the time frame index is built with
+	UpgradeFrameSize = "datanode-frame-size"
+	// UpgradeBandwidthGas sets the gas per record of the txs written on the bandwidth quota
+	UpgradeBandwidthGas = "datanode-bandwidth-gas"
+)
@@ -0,0 +1,200 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Channel value schema limits, enforced on messages and genesis
+const (
+	MaxDecimalScale    = sdk.Precision
+	MaxEnumValues      = 64
+	MaxEnumLabelLength = 32
+)
+
+// validateSchema checks the unit, encoding, scale, enum labels and bounds of the channel
+func validateSchema(channel NodeChannel) error {
+	if err := validateUnit(channel.Unit); err != nil {
+		return err
+	}
+	if !channel.Encoding.IsValid() {
+		return fmt.Errorf("has invalid encoding %s", channel.Encoding)
+	}
+
+	if channel.Scale != 0 && channel.Encoding != EncodingDecimal {
+		return fmt.Errorf("scale is only allowed on %s encoding", EncodingDecimal)
+	}
+	if channel.Scale > MaxDecimalScale {
+		return fmt.Errorf("scale can't be more than %d", MaxDecimalScale)
+	}
+
+	if channel.Encoding == EncodingEnum {
+		if len(channel.Enum) == 0 || len(channel.Enum) > MaxEnumValues {
+			return fmt.Errorf("enum must have between 1 and %d labels", MaxEnumValues)
+		}
+		seen := make(map[string]bool)
+		for _, label := range channel.Enum {
+			if len(label) == 0 || len(label) > MaxEnumLabelLength {
+				return fmt.Errorf("enum labels must have between 1 and %d characters", MaxEnumLabelLength)
+			}
+			if seen[label] {
+				return fmt.Errorf("duplicated enum label %s", label)
+			}
+			seen[label] = true
+		}
+	} else if len(channel.Enum) > 0 {
+		return fmt.Errorf("enum labels are only allowed on %s encoding", EncodingEnum)
+	}
+
+	min, max, err := channel.bounds()
+	if err != nil {
+		return err
+	}
+	if (min != nil || max != nil) && !channel.Encoding.IsNumeric() {
+		return fmt.Errorf("bounds are not allowed on %s encoding", channel.Encoding)
+	}
+	if min != nil && max != nil && min.GT(*max) {
+		return fmt.Errorf("min %s is greater than max %s", channel.Min, channel.Max)
+	}
+	return nil
+}
+
+// validateUnit checks the unit is a UCUM code, printable ascii without spaces, not longer than MaxUnitLength
+func validateUnit(unit string) error {
+	if len(unit) > MaxUnitLength {
+		return fmt.Errorf("unit can't have more than %d characters", MaxUnitLength)
+	}
+	for _, c := range unit {
+		if c <= ' ' || c > '~' {
+			return fmt.Errorf("invalid character %q on unit", c)
+		}
+	}
+	return nil
+}
+
+// bounds returns the parsed min and max of the channel, nil when not set
+func (c NodeChannel) bounds() (*sdk.Dec, *sdk.Dec, error) {
+	var min, max *sdk.Dec
+	if c.Min != "" {
+		d, err := sdk.NewDecFromStr(c.Min)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid min %s", c.Min)
+		}
+		min = &d
+	}
+	if c.Max != "" {
+		d, err := sdk.NewDecFromStr(c.Max)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid max %s", c.Max)
+		}
+		max = &d
+	}
+	return min, max, nil
+}
+
+// DecodeValue returns the record value read with the channel encoding (ex. -12.50, true or a label)
+func (c NodeChannel) DecodeValue(r Record) (string, error) {
+	value, _, err := c.decode(r)
+	return value, err
+}
+
+// ValidateValue checks the record value can be read with the channel encoding and is within the bounds
+func (c NodeChannel) ValidateValue(r Record) error {
+	value, number, err := c.decode(r)
+	if err != nil {
+		return err
+	}
+	if number == nil {
+		return nil
+	}
+
+	min, max, err := c.bounds()
+	if err != nil {
+		return err
+	}
+	if min != nil && number.LT(*min) {
+		return fmt.Errorf("value %s is below the minimum %s", value, c.Min)
+	}
+	if max != nil && number.GT(*max) {
+		return fmt.Errorf("value %s is above the maximum %s", value, c.Max)
+	}
+	return nil
+}
+
+// decode returns the text of the record value and its number for the numeric encodings
+func (c NodeChannel) decode(r Record) (string, *sdk.Dec, error) {
+	var number sdk.Dec
+	switch c.Encoding {
+	case "", EncodingUint32:
+		if r.Value < 0 || r.Value > math.MaxUint32 {
+			return "", nil, fmt.Errorf("value %d is out of the uint32 range", r.Value)
+		}
+		number = sdk.NewDec(r.Value)
+		return strconv.FormatInt(r.Value, 10), &number, nil
+	case EncodingInt32:
+		if r.Value < math.MinInt32 || r.Value > math.MaxInt32 {
+			return "", nil, fmt.Errorf("value %d is out of the int32 range", r.Value)
+		}
+		number = sdk.NewDec(r.Value)
+		return strconv.FormatInt(r.Value, 10), &number, nil
+	case EncodingInt64:
+		number = sdk.NewDec(r.Value)
+		return strconv.FormatInt(r.Value, 10), &number, nil
+	case EncodingDecimal:
+		number = sdk.NewDecWithPrec(r.Value, int64(c.Scale))
+		return formatDecimal(number, c.Scale), &number, nil
+	case EncodingFloat32:
+		if r.Value < 0 || r.Value > math.MaxUint32 {
+			return "", nil, fmt.Errorf("value %d is out of the float32 bits range", r.Value)
+		}
+		f := float64(math.Float32frombits(uint32(r.Value)))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", nil, fmt.Errorf("value is not a finite number")
+		}
+		number, err := sdk.NewDecFromStr(strconv.FormatFloat(f, 'f', sdk.Precision, 64))
+		if err != nil {
+			return "", nil, err
+		}
+		return strconv.FormatFloat(f, 'g', -1, 32), &number, nil
+	case EncodingBool:
+		return strconv.FormatBool(r.Value != 0), nil, nil
+	case EncodingEnum:
+		if r.Value < 0 || r.Value >= int64(len(c.Enum)) {
+			return "", nil, fmt.Errorf("value %d is not an enum label index", r.Value)
+		}
+		return c.Enum[r.Value], nil, nil
+	case EncodingMisc:
+		return r.Misc, nil, nil
+	}
+	return "", nil, fmt.Errorf("invalid encoding %s", c.Encoding)
+}
+
+// LegacyValue returns the value and the misc of a record v2 from the 32-bit value and the misc of a
+// legacy record. Signed encodings are sign extended and int64 values are moved from the misc text
+func (c NodeChannel) LegacyValue(value uint32, misc string) (int64, string, error) {
+	switch c.Encoding {
+	case EncodingInt32, EncodingDecimal:
+		return int64(int32(value)), misc, nil
+	case EncodingInt64:
+		v, err := strconv.ParseInt(misc, 10, 64)
+		if err != nil {
+			return 0, misc, fmt.Errorf("misc %q is not a 64-bit integer", misc)
+		}
+		return v, "", nil
+	}
+	return int64(value), misc, nil
+}
+
+// formatDecimal returns the decimal with scale digits after the point
+func formatDecimal(d sdk.Dec, scale uint32) string {
+	s := d.String()
+	point := strings.IndexByte(s, '.')
+	if scale == 0 {
+		return s[:point]
+	}
+	return s[:point+1+int(scale)]
+}